@@ -0,0 +1,199 @@
+// Auth is a package that stores API tokens as named profiles, either within a
+// credentials file only readable by the current user or via an external
+// helper command (e.g. a script that talks to the operating system keyring).
+
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultProfile is the profile used when none is specified
+const DefaultProfile = "default"
+
+var logger *logrus.Entry
+
+func init() {
+	logger = logrus.WithFields(logrus.Fields{
+		"package": "auth",
+	})
+}
+
+// ErrUnknownProfile is returned when a profile hasn't been stored
+var ErrUnknownProfile = errors.New("unknown profile (try: fastly auth login)")
+
+// Profile is a single set of stored credentials
+// when Helper is set the token is retrieved from the helper command instead
+type Profile struct {
+	Token  string `toml:"token,omitempty"`
+	Helper string `toml:"helper,omitempty"`
+}
+
+// Credentials is the content of the credentials file
+type Credentials struct {
+	// Default is the profile used when -profile isn't provided
+	Default  string             `toml:"default,omitempty"`
+	Profiles map[string]Profile `toml:"profiles"`
+
+	path string
+}
+
+// Path returns the location of the credentials file
+// (FASTLY_CLI_CREDENTIALS or ~/.fastly-cli/credentials)
+func Path() (string, error) {
+	if path := os.Getenv("FASTLY_CLI_CREDENTIALS"); path != "" {
+		return path, nil
+	}
+
+	home := os.Getenv("HOME")
+	if home == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", fmt.Errorf("Unable to locate your home directory:\n\t%s", err)
+		}
+		home = u.HomeDir
+	}
+
+	return filepath.Join(home, ".fastly-cli", "credentials"), nil
+}
+
+// Load reads the credentials file, an empty set of credentials is returned
+// when the file doesn't exist yet
+func Load() (*Credentials, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	creds := &Credentials{
+		Profiles: map[string]Profile{},
+		path:     path,
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return creds, nil
+	}
+
+	if _, err := toml.DecodeFile(path, creds); err != nil {
+		return nil, fmt.Errorf("Unable to parse credentials file '%s':\n\t%s", path, err)
+	}
+	if creds.Profiles == nil {
+		creds.Profiles = map[string]Profile{}
+	}
+
+	return creds, nil
+}
+
+// Save writes the credentials file so it's only readable by the current user
+func (c *Credentials) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(c.path, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	// WriteFile doesn't change the permissions of an existing file
+	return os.Chmod(c.path, 0600)
+}
+
+// File returns the location the credentials were loaded from
+func (c *Credentials) File() string {
+	return c.path
+}
+
+// Names returns the stored profile names in alphabetical order
+func (c *Credentials) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Selected returns the profile to use when name is empty
+func (c *Credentials) Selected(name string) string {
+	if name != "" {
+		return name
+	}
+	if c.Default != "" {
+		return c.Default
+	}
+	return DefaultProfile
+}
+
+// Token returns the token stored for the named profile
+func (c *Credentials) Token(name string) (string, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return "", ErrUnknownProfile
+	}
+
+	logger.WithFields(logrus.Fields{
+		"profile": name,
+		"helper":  profile.Helper != "",
+	}).Debug("retrieving token")
+
+	if profile.Helper != "" {
+		return helperGet(profile.Helper, name)
+	}
+	return profile.Token, nil
+}
+
+// Store saves the token for the named profile, when helper isn't empty the
+// token is handed to the helper command rather than written to the file
+func (c *Credentials) Store(name, token, helper string) error {
+	profile := Profile{Token: token}
+
+	if helper != "" {
+		if err := helperStore(helper, name, token); err != nil {
+			return err
+		}
+		profile = Profile{Helper: helper}
+	}
+
+	c.Profiles[name] = profile
+	if c.Default == "" {
+		c.Default = name
+	}
+
+	return c.Save()
+}
+
+// Remove deletes the named profile (and asks its helper to erase the token)
+func (c *Credentials) Remove(name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return ErrUnknownProfile
+	}
+
+	if profile.Helper != "" {
+		if err := helperErase(profile.Helper, name); err != nil {
+			return err
+		}
+	}
+
+	delete(c.Profiles, name)
+	if c.Default == name {
+		c.Default = ""
+	}
+
+	return c.Save()
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// credentials points FASTLY_CLI_CREDENTIALS at a file in a directory that
+// doesn't exist yet, the returned function removes it again
+func credentials(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "fastly-cli-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "config dir", "credentials")
+	os.Setenv("FASTLY_CLI_CREDENTIALS", path)
+
+	return path, func() {
+		os.Unsetenv("FASTLY_CLI_CREDENTIALS")
+		os.RemoveAll(dir)
+	}
+}
+
+// helperScript is a credential helper that keeps each profile's token in a
+// file of the directory it's given as its first argument, and records every
+// action it's asked to perform
+const helperScript = `#!/bin/sh
+dir="$1"
+echo "$2 $FASTLY_CLI_PROFILE" >> "$dir/actions"
+case "$2" in
+get) cat "$dir/$FASTLY_CLI_PROFILE" ;;
+store) cat > "$dir/$FASTLY_CLI_PROFILE" ;;
+erase) rm "$dir/$FASTLY_CLI_PROFILE" ;;
+*) echo "unknown action: $2" >&2; exit 1 ;;
+esac
+`
+
+// stubHelper writes the helper script into a directory (whose name contains
+// a space) and returns the helper command along with the directory
+func stubHelper(t *testing.T, path string) (string, string) {
+	dir := filepath.Join(filepath.Dir(filepath.Dir(path)), "token store")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	script := filepath.Join(dir, "helper.sh")
+	if err := ioutil.WriteFile(script, []byte(helperScript), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	return "'" + script + "' '" + dir + "'", dir
+}
+
+func TestSave(t *testing.T) {
+	path, cleanup := credentials(t)
+	defer cleanup()
+
+	creds, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(creds.Profiles) != 0 {
+		t.Fatalf("got profiles %v before anything was stored", creds.Names())
+	}
+
+	if err := creds.Store("work", "secret", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	dirInfo, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := dirInfo.Mode().Perm(); perm != 0700 {
+		t.Errorf("got directory permissions %o, want 700", perm)
+	}
+
+	// an existing file that's readable by others is restricted again
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := creds.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("got file permissions %o, want 600", perm)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Default != "work" {
+		t.Errorf("got default profile %q, want the first stored profile %q", loaded.Default, "work")
+	}
+	token, err := loaded.Token("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "secret" {
+		t.Errorf("got token %q, want %q", token, "secret")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	_, cleanup := credentials(t)
+	defer cleanup()
+
+	creds, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"work", "home"} {
+		if err := creds.Store(name, name+"-token", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := creds.Remove("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Default != "" {
+		t.Errorf("got default profile %q, want it cleared", loaded.Default)
+	}
+	if selected := loaded.Selected(""); selected != DefaultProfile {
+		t.Errorf("selected profile %q, want %q", selected, DefaultProfile)
+	}
+	if names := strings.Join(loaded.Names(), ","); names != "home" {
+		t.Errorf("got profiles %q, want %q", names, "home")
+	}
+
+	if err := loaded.Remove("work"); err != ErrUnknownProfile {
+		t.Errorf("got error %v removing an unknown profile, want %v", err, ErrUnknownProfile)
+	}
+}
+
+func TestHelper(t *testing.T) {
+	path, cleanup := credentials(t)
+	defer cleanup()
+
+	helper, dir := stubHelper(t, path)
+
+	creds, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := creds.Store("work", "secret", helper); err != nil {
+		t.Fatal(err)
+	}
+
+	// the token is only given to the helper, not written to the file
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "secret") {
+		t.Errorf("the token was written to the credentials file:\n%s", content)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := loaded.Token("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "secret" {
+		t.Errorf("got token %q, want %q", token, "secret")
+	}
+
+	if err := loaded.Remove("work"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "work")); !os.IsNotExist(err) {
+		t.Error("the helper didn't erase the token")
+	}
+
+	actions, err := ioutil.ReadFile(filepath.Join(dir, "actions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(actions), "store work\nget work\nerase work\n"; got != want {
+		t.Errorf("got helper actions %q, want %q", got, want)
+	}
+
+	// the token can't be retrieved once the helper has erased it
+	loaded.Profiles["work"] = Profile{Helper: helper}
+	if _, err := loaded.Token("work"); err == nil {
+		t.Error("expected an error retrieving an erased token")
+	}
+}
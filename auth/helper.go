@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// A helper is an external command that stores tokens somewhere other than the
+// credentials file (e.g. the macOS keychain or a password manager)
+//
+// The command is run by the shell (so it can include its own arguments) with
+// one of the following arguments appended, and the profile name in the
+// FASTLY_CLI_PROFILE variable...
+//
+//	get:   print the token to stdout
+//	store: read the token from stdin and store it
+//	erase: remove the stored token
+
+func helperGet(helper, profile string) (string, error) {
+	var stdout bytes.Buffer
+
+	if err := runHelper(helper, "get", profile, nil, &stdout); err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("The credential helper '%s' didn't return a token for profile '%s'", helper, profile)
+	}
+
+	return token, nil
+}
+
+func helperStore(helper, profile, token string) error {
+	return runHelper(helper, "store", profile, strings.NewReader(token+"\n"), nil)
+}
+
+func helperErase(helper, profile string) error {
+	return runHelper(helper, "erase", profile, nil, nil)
+}
+
+func runHelper(helper, action, profile string, stdin *strings.Reader, stdout *bytes.Buffer) error {
+	// the action is passed as a positional parameter rather than pasted into
+	// the command, "$@" then appends it as a single quoted argument
+	cmd := exec.Command("sh", "-c", helper+` "$@"`, "sh", action)
+	cmd.Env = append(os.Environ(), "FASTLY_CLI_PROFILE="+profile)
+	cmd.Stderr = os.Stderr
+
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	if stdout != nil {
+		cmd.Stdout = stdout
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("The credential helper '%s %s' failed:\n\t%s", helper, action, err)
+	}
+
+	return nil
+}
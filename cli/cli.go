@@ -0,0 +1,247 @@
+// Cli is a package that parses the command line into a tree of commands (e.g.
+// `fastly vcl upload`), where the flags of a command or any of its parents
+// can be provided either before or after the subcommand, and generates the
+// help and shell completion for each of the commands.
+
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logger *logrus.Entry
+
+func init() {
+	logger = logrus.WithFields(logrus.Fields{
+		"package": "cli",
+	})
+}
+
+// ErrUnknownCommand is returned when an argument doesn't match a subcommand
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Command is a single node within the command tree
+type Command struct {
+	Name string
+
+	// Args describes the positional arguments (e.g. "<version>")
+	Args string
+
+	Summary string
+	Example string
+
+	// Flags are the flags specific to this command, the flags of each parent
+	// command are also accepted (unless a flag of the same name shadows them)
+	Flags *flag.FlagSet
+
+	// Run is called with the positional arguments, a command without Run
+	// displays its help
+	Run func(args []string)
+
+	// Replacement is set for a deprecated command, which still runs (after a
+	// warning) but is no longer displayed in the help or completion
+	Replacement *Command
+
+	// Hidden commands aren't displayed in the help or completion
+	Hidden bool
+
+	commands []*Command
+	parent   *Command
+}
+
+// Invocation is the command selected by the command line
+type Invocation struct {
+	Command *Command
+	Args    []string
+}
+
+// Run runs the selected command (warning first when it's deprecated)
+func (i *Invocation) Run() {
+	if i.Command.Replacement != nil {
+		Deprecated(i.Command.Path(), i.Command.Replacement.Path())
+	}
+
+	if i.Command.Run == nil {
+		i.Command.Help()
+		return
+	}
+
+	i.Command.Run(i.Args)
+}
+
+// Deprecated warns (on stderr, so structured output isn't affected) that
+// the old form of a command should be replaced
+func Deprecated(old, replacement string) {
+	fmt.Fprintf(os.Stderr, "Warning: '%s' is deprecated, use '%s' instead\n\n", old, replacement)
+}
+
+// Add appends the subcommands and returns the command so calls can be nested
+func (c *Command) Add(commands ...*Command) *Command {
+	for _, cmd := range commands {
+		cmd.parent = c
+		c.commands = append(c.commands, cmd)
+	}
+	return c
+}
+
+// Alias returns a deprecated copy of the command under a different name
+// (e.g. the old `fastly upload` form of `fastly vcl upload`)
+func (c *Command) Alias(name string) *Command {
+	alias := *c
+	alias.Name = name
+	alias.Replacement = c
+	alias.commands = nil
+	alias.parent = nil
+	return &alias
+}
+
+// Path returns the full name of the command (e.g. "fastly vcl upload")
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.Path() + " " + c.Name
+}
+
+// Root returns the top of the command tree
+func (c *Command) Root() *Command {
+	if c.parent == nil {
+		return c
+	}
+	return c.parent.Root()
+}
+
+// Parse selects the command from the arguments (which shouldn't include the
+// program name) and sets the value of every flag provided along the way
+func (c *Command) Parse(args []string) (*Invocation, error) {
+	if len(args) > 0 && args[0] == completeCommand {
+		return &Invocation{Command: c.completer(), Args: args[1:]}, nil
+	}
+
+	cmd := c
+	var positional []string
+
+	for {
+		fs := cmd.flagSet()
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%s (see: %s -help)", err, cmd.Path())
+		}
+		cmd.record(fs)
+
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+
+		// everything after a -- terminator is a positional argument
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+
+		if sub := cmd.find(rest[0]); sub != nil && len(positional) == 0 {
+			cmd = sub
+		} else {
+			positional = append(positional, rest[0])
+		}
+		args = rest[1:]
+	}
+
+	if len(positional) > 0 && len(cmd.commands) > 0 {
+		return nil, fmt.Errorf("%s '%s' for '%s' (see: %s -help)", ErrUnknownCommand, positional[0], cmd.Path(), cmd.Path())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"command": cmd.Path(),
+		"args":    positional,
+	}).Debug("command selected")
+
+	return &Invocation{Command: cmd, Args: positional}, nil
+}
+
+// Lookup finds the command named by the path of subcommand names
+func (c *Command) Lookup(names []string) (*Command, error) {
+	cmd := c
+	for _, name := range names {
+		sub := cmd.find(name)
+		if sub == nil {
+			return nil, fmt.Errorf("%s '%s' for '%s'", ErrUnknownCommand, name, cmd.Path())
+		}
+		cmd = sub
+	}
+	return cmd, nil
+}
+
+func (c *Command) find(name string) *Command {
+	for _, cmd := range c.commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// visible returns the subcommands that are displayed in help and completion
+func (c *Command) visible() []*Command {
+	var commands []*Command
+	for _, cmd := range c.commands {
+		if !cmd.Hidden && cmd.Replacement == nil {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+// flagSet combines the flags of the command with those of its parents, the
+// values are shared so setting a flag in the combined set sets the original
+func (c *Command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.Path(), flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd.Flags == nil {
+			continue
+		}
+
+		cmd.Flags.VisitAll(func(fl *flag.Flag) {
+			if fs.Lookup(fl.Name) == nil {
+				fs.Var(fl.Value, fl.Name, fl.Usage)
+			}
+		})
+	}
+
+	return fs
+}
+
+// record marks the flags provided in the combined set as set within the flag
+// set that defines them, so flag.Visit reports them wherever they were given
+func (c *Command) record(fs *flag.FlagSet) {
+	fs.Visit(func(fl *flag.Flag) {
+		for cmd := c; cmd != nil; cmd = cmd.parent {
+			if cmd.Flags != nil && cmd.Flags.Lookup(fl.Name) != nil {
+				cmd.Flags.Set(fl.Name, fl.Value.String())
+				return
+			}
+		}
+	})
+}
+
+// isBool reports whether the flag doesn't take a value
+func isBool(fl *flag.Flag) bool {
+	b, ok := fl.Value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && b.IsBoolFlag()
+}
+
+// trimDashes returns the flag name from an argument such as -dir or --dir
+func trimDashes(arg string) string {
+	return strings.TrimLeft(arg, "-")
+}
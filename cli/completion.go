@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/integralist/go-fastly-cli/common"
+)
+
+// completeCommand is the hidden command the completion scripts call with the
+// words typed so far, it prints one candidate per line
+const completeCommand = "__complete"
+
+// ErrUnknownShell is returned when completion isn't available for a shell
+var ErrUnknownShell = errors.New("unknown shell (try: bash, zsh or fish)")
+
+// Complete returns the candidates for the last of the words typed so far
+// (excluding the program name), which are subcommands or flags, nothing is
+// returned when a flag value is expected so the shell falls back to files
+func (c *Command) Complete(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+
+	cmd := c
+	fs := cmd.flagSet()
+
+	for i := 0; i < len(words)-1; i++ {
+		word := words[i]
+
+		if strings.HasPrefix(word, "-") {
+			name := trimDashes(word)
+			if strings.Contains(name, "=") {
+				continue
+			}
+
+			if fl := fs.Lookup(name); fl != nil && !isBool(fl) {
+				// the flag's value is the word being completed
+				if i++; i == len(words)-1 {
+					return nil
+				}
+			}
+			continue
+		}
+
+		if sub := cmd.find(word); sub != nil {
+			cmd = sub
+			fs = cmd.flagSet()
+		}
+	}
+
+	var candidates []string
+
+	if strings.HasPrefix(current, "-") {
+		fs.VisitAll(func(fl *flag.Flag) {
+			if name := "-" + fl.Name; strings.HasPrefix(name, current) {
+				candidates = append(candidates, name)
+			}
+		})
+	} else {
+		for _, sub := range cmd.visible() {
+			if strings.HasPrefix(sub.Name, current) {
+				candidates = append(candidates, sub.Name)
+			}
+		}
+	}
+
+	sort.Strings(candidates)
+
+	return candidates
+}
+
+// completer is the command run for completeCommand
+func (c *Command) completer() *Command {
+	return &Command{
+		Name:   completeCommand,
+		Hidden: true,
+		Run: func(words []string) {
+			for _, candidate := range c.Complete(words) {
+				fmt.Println(candidate)
+			}
+		},
+	}
+}
+
+// Completion returns the completion script for the shell
+func (c *Command) Completion(shell string) (string, error) {
+	name := c.Root().Name
+
+	switch shell {
+	case "bash":
+		return fmt.Sprintf(bashCompletion, name, completeCommand), nil
+	case "zsh":
+		return fmt.Sprintf(zshCompletion, name, completeCommand), nil
+	case "fish":
+		return fmt.Sprintf(fishCompletion, name, completeCommand), nil
+	}
+
+	return "", ErrUnknownShell
+}
+
+// CompletionCommand returns a `completion <shell>` command for the tree
+func (c *Command) CompletionCommand() *Command {
+	return &Command{
+		Name:    "completion",
+		Args:    "bash|zsh|fish",
+		Summary: "print the shell completion script",
+		Example: "source <(fastly completion bash)\nfastly completion zsh > \"${fpath[1]}/_fastly\"\nfastly completion fish > ~/.config/fish/completions/fastly.fish",
+		Run: func(args []string) {
+			shell := ""
+			if len(args) > 0 {
+				shell = args[0]
+			}
+
+			script, err := c.Completion(shell)
+			if err != nil {
+				fmt.Println(err)
+				common.Failure()
+			}
+
+			fmt.Print(script)
+		},
+	}
+}
+
+// each script is formatted with the program name and the completeCommand,
+// the words typed so far (including the current one) are passed to it
+
+const bashCompletion = `# bash completion for %[1]s
+_%[1]s_complete() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	local IFS=$'\n'
+	COMPREPLY=( $(compgen -W "$(%[1]s %[2]s "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)" -- "$cur") )
+}
+complete -o default -F _%[1]s_complete %[1]s
+`
+
+const zshCompletion = `#compdef %[1]s
+_%[1]s_complete() {
+	local -a candidates
+	candidates=(${(f)"$(%[1]s %[2]s "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+	if (( ${#candidates} )); then
+		compadd -a candidates
+	else
+		_files
+	fi
+}
+compdef _%[1]s_complete %[1]s
+`
+
+const fishCompletion = `# fish completion for %[1]s
+function __%[1]s_complete
+	set -l words (commandline -opc)
+	set -e words[1]
+	%[1]s %[2]s $words (commandline -ct) 2>/dev/null
+end
+complete -c %[1]s -a '(__%[1]s_complete)'
+`
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/integralist/go-fastly-cli/common"
+)
+
+// Help displays the usage, examples, subcommands and flags of the command
+func (c *Command) Help() {
+	if c.Summary != "" {
+		fmt.Printf("\n%s\n", c.Summary)
+	}
+
+	fmt.Printf("\nUsage:\n\n  %s\n", c.usage())
+
+	if c.Replacement != nil {
+		fmt.Printf("\nDeprecated, use: %s\n", c.Replacement.Path())
+	}
+
+	if c.Example != "" {
+		fmt.Printf("\nExamples:\n\n  %s\n", strings.Replace(c.Example, "\n", "\n  ", -1))
+	}
+
+	if commands := c.visible(); len(commands) > 0 {
+		fmt.Printf("\nCommands:\n\n")
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, cmd := range commands {
+			fmt.Fprintf(w, "  %s\t%s\n", cmd.Name, cmd.Summary)
+		}
+		w.Flush()
+	}
+
+	if c.Flags != nil && hasFlags(c.Flags) {
+		fmt.Printf("\nFlags:\n\n")
+		printDefaults(c.Flags)
+	}
+
+	if inherited := c.inherited(); hasFlags(inherited) {
+		fmt.Printf("\nGlobal Flags:\n\n")
+		printDefaults(inherited)
+	}
+
+	if len(c.visible()) > 0 {
+		fmt.Printf("\nUse \"%s <command> -help\" for more information about a command\n", c.Path())
+	}
+
+	fmt.Println()
+}
+
+// HelpCommand returns a `help [command]...` command for the tree
+func (c *Command) HelpCommand() *Command {
+	return &Command{
+		Name:    "help",
+		Args:    "[command]...",
+		Summary: "show the help for a command",
+		Example: "fastly help vcl upload",
+		Run: func(args []string) {
+			cmd, err := c.Root().Lookup(args)
+			if err != nil {
+				fmt.Println(err)
+				common.Failure()
+			}
+			cmd.Help()
+		},
+	}
+}
+
+func (c *Command) usage() string {
+	usage := c.Path()
+	if len(c.visible()) > 0 {
+		usage += " <command>"
+	}
+	usage += " [flags]"
+	if c.Args != "" {
+		usage += " " + c.Args
+	}
+	return usage
+}
+
+// inherited returns the flags accepted from the parent commands, excluding
+// any shadowed by a flag of the same name
+func (c *Command) inherited() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.Path(), flag.ContinueOnError)
+
+	for cmd := c.parent; cmd != nil; cmd = cmd.parent {
+		if cmd.Flags == nil {
+			continue
+		}
+
+		cmd.Flags.VisitAll(func(fl *flag.Flag) {
+			if c.Flags != nil && c.Flags.Lookup(fl.Name) != nil {
+				return
+			}
+			if fs.Lookup(fl.Name) == nil {
+				fs.Var(fl.Value, fl.Name, fl.Usage)
+				fs.Lookup(fl.Name).DefValue = fl.DefValue
+			}
+		})
+	}
+
+	return fs
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) {
+		found = true
+	})
+	return found
+}
+
+func printDefaults(fs *flag.FlagSet) {
+	fs.SetOutput(os.Stdout)
+	fs.PrintDefaults()
+	fs.SetOutput(nil)
+}
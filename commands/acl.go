@@ -0,0 +1,411 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// errACLConfirmation is reported when an ACL sync can't be confirmed
+var errACLConfirmation = errors.New("synced entries are live as soon as they're applied, please provide -yes to sync them with -output")
+
+// ACLList displays the ACLs found in the remote service version
+// each of the services provided to -service is listed at the same time
+func ACLList(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.ACLListVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.ListACLs(ctx, client, vcl.ListOptions{
+			Service: service,
+			Version: selectedVersion,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printACLs(result) },
+			Doc:     output.ACLs(result),
+		}
+	})
+}
+
+// ACLCreate creates an empty ACL in the selected version
+func ACLCreate(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.ACLOptions{
+		Target: target(f, f.Sub.ACLCreate),
+		Name:   name,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanCreateACL(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.ACL(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe ACL '%s' would be created in %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		return
+	}
+
+	result, err := vcl.CreateACL(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.ACL(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The ACL '%s' in version '%s' was created successfully\n\n", common.Green(name), common.Yellow(result.Version))
+}
+
+// ACLDelete deletes the ACL (along with its entries) from the selected version
+func ACLDelete(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.ACLOptions{
+		Target: target(f, f.Sub.ACLDelete),
+		Name:   name,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanDeleteACL(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.ACL(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe ACL '%s' would be deleted from %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		return
+	}
+
+	result, err := vcl.DeleteACL(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.ACL(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The ACL '%s' in version '%s' was deleted successfully\n\n", common.Red(name), common.Yellow(result.Version))
+}
+
+// EntryList displays the entries of the ACL, which is found in the same
+// version as List (each of the services provided to -service is listed at
+// the same time)
+func EntryList(ctx context.Context, f flags.Flags, client api.Client, acl string) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.EntryListVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.ACLEntries(ctx, client, vcl.EntryOptions{
+			Service: service,
+			ACL:     acl,
+			Version: selectedVersion,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printEntries(result, selectedVersion) },
+			Doc:     output.ACLEntries(result),
+		}
+	})
+}
+
+// EntryAdd adds an ip address or cidr block to the ACL (the change is live
+// immediately, as the entries aren't versioned)
+func EntryAdd(ctx context.Context, f flags.Flags, client api.Client, acl, entry string) {
+	opts := entryOptions(f, *f.Sub.EntryAddVersion, acl, entry)
+	opts.Comment = *f.Sub.EntryAddComment
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanAddACLEntry(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.ACLEntry(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\n%s would be added to the ACL '%s' (found in version '%s')\n\n", common.Yellow(result.Entry), common.Yellow(result.ACL.Name), common.Yellow(result.Version))
+		return
+	}
+
+	result, err := vcl.AddACLEntry(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.ACLEntry(result))
+		return
+	}
+
+	fmt.Printf("\n%s was added to the ACL '%s' successfully (it's now live)\n\n", common.Green(result.Entry), common.Yellow(result.ACL.Name))
+}
+
+// EntryRemove removes an ip address or cidr block from the ACL (the change
+// is live immediately, as the entries aren't versioned)
+func EntryRemove(ctx context.Context, f flags.Flags, client api.Client, acl, entry string) {
+	opts := entryOptions(f, *f.Sub.EntryRemoveVersion, acl, entry)
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanRemoveACLEntry(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.ACLEntry(result)
+			doc.Removed = true
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\n%s would be removed from the ACL '%s' (found in version '%s')\n\n", common.Yellow(result.Entry), common.Yellow(result.ACL.Name), common.Yellow(result.Version))
+		return
+	}
+
+	result, err := vcl.RemoveACLEntry(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		doc := output.ACLEntry(result)
+		doc.Removed = true
+		output.Write(doc)
+		return
+	}
+
+	fmt.Printf("\n%s was removed from the ACL '%s' successfully (it's now live)\n\n", common.Red(result.Entry), common.Yellow(result.ACL.Name))
+}
+
+// EntrySync makes the ACL contain exactly the entries of a local file, after
+// confirming the changes
+//
+// the ACL of each of the services provided to -service is planned first, so
+// every change is confirmed at once, and then synced at the same time
+func EntrySync(ctx context.Context, f flags.Flags, client api.Client, acl, file string) {
+	if output.Structured() && !*f.Top.DryRun && !*f.Sub.EntrySyncConfirm {
+		output.Fail(errACLConfirmation)
+	}
+
+	selectedVersion, err := common.ParseVersion(*f.Sub.EntrySyncVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	plans := map[string]*vcl.ACLSyncPlan{}
+	empty := true
+
+	for _, service := range services(f) {
+		plan, err := vcl.PlanACLSync(ctx, client, vcl.ACLSyncOptions{
+			Service: service,
+			ACL:     acl,
+			Version: selectedVersion,
+			File:    file,
+		})
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		plans[service] = plan
+		empty = empty && plan.Empty()
+	}
+
+	if *f.Top.DryRun || empty {
+		output.Services(services(f), func(service string) output.Report {
+			plan := plans[service]
+			doc := output.ACLSync(plan, nil, nil)
+			doc.DryRun = *f.Top.DryRun
+
+			return output.Report{
+				Service: service,
+				Text:    func() { printACLSyncPlan(plan, *f.Top.DryRun) },
+				Doc:     doc,
+			}
+		})
+	}
+
+	if !output.Structured() {
+		for _, service := range services(f) {
+			printACLSyncPlan(plans[service], false)
+		}
+	}
+
+	if !*f.Sub.EntrySyncConfirm && !common.Confirm("\nSync these entries (they will be live immediately)?") {
+		fmt.Println("\nNo changes were applied")
+		common.Success()
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		plan := plans[service]
+
+		result, err := vcl.ApplyACLSync(ctx, client, plan)
+		if err != nil {
+			code := common.ExitFailure
+			if result.Batches > 0 {
+				code = common.ExitPartialFailure
+			}
+
+			return output.Report{
+				Service: service,
+				Text: func() {
+					fmt.Printf("\nThe ACL '%s' didn't sync because of the following error:\n\t%s\n\n", common.Yellow(plan.ACL.Name), common.Red(err))
+				},
+				Doc:  output.ACLSync(plan, result, err),
+				Code: code,
+			}
+		}
+
+		return output.Report{
+			Service: service,
+			Text: func() {
+				fmt.Printf("\nThe entries of the ACL '%s' were synced successfully in %d batch(es): %s added, %s updated, %s removed\n\n",
+					common.Yellow(plan.ACL.Name), result.Batches, common.Green(result.Added), common.Yellow(result.Updated), common.Red(result.Removed))
+			},
+			Doc: output.ACLSync(plan, result, nil),
+		}
+	})
+}
+
+// entryOptions returns the options identifying the entry of an ACL entries
+// command
+func entryOptions(f flags.Flags, version, acl, entry string) vcl.EntryOptions {
+	selectedVersion, err := common.ParseVersion(version)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	return vcl.EntryOptions{
+		Service: *f.Top.Service,
+		ACL:     acl,
+		Version: selectedVersion,
+		Entry:   entry,
+	}
+}
+
+func printACLs(result *vcl.ACLsResult) {
+	fmt.Printf("ACLs found for service version: %s\n\n", common.Yellow(result.Version))
+
+	if len(result.ACLs) == 0 {
+		fmt.Println("There are no ACLs")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID")
+	for _, acl := range result.ACLs {
+		fmt.Fprintf(w, "%s\t%s\n", acl.Name, acl.ID)
+	}
+	w.Flush()
+}
+
+func printEntries(result *vcl.EntriesResult, selectedVersion int) {
+	// If the user didn't provide a version, then we used the latest one
+	if selectedVersion == 0 {
+		fmt.Println("You didn't provide a specific service version, so we'll use the latest one")
+	}
+
+	fmt.Printf("Entries of the ACL '%s' found in service version: %s\n\n", common.Yellow(result.ACL.Name), common.Yellow(result.Version))
+
+	if len(result.Entries) == 0 {
+		fmt.Println("There are no entries")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENTRY\tCOMMENT")
+	for _, entry := range result.Entries {
+		fmt.Fprintf(w, "%s\t%s\n", entry, entry.Comment)
+	}
+	w.Flush()
+}
+
+// printACLSyncPlan displays the entries a sync will add, update and remove
+func printACLSyncPlan(plan *vcl.ACLSyncPlan, dryRun bool) {
+	if len(plan.Warnings) > 0 {
+		fmt.Println()
+		printWarnings(plan.Warnings)
+	}
+
+	if plan.Empty() {
+		fmt.Printf("\nThe ACL '%s' of service '%s' already contains the entries (%d unchanged)\n", common.Yellow(plan.ACL.Name), common.Yellow(plan.Service), plan.Unchanged)
+		return
+	}
+
+	will := "will"
+	if dryRun {
+		will = "would"
+	}
+
+	fmt.Printf("\nThe following changes %s be made to the entries of the ACL '%s' of service '%s' (found in version '%s'):\n\n", will, common.Yellow(plan.ACL.Name), common.Yellow(plan.Service), common.Yellow(plan.Version))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	for _, entry := range plan.Add {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", common.Green("+ add"), entry, entry.Comment)
+	}
+	for _, update := range plan.Update {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", common.Yellow("~ update"), update.New, entryChanges(update))
+	}
+	for _, entry := range plan.Remove {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", common.Red("- remove"), entry, entry.Comment)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d to add, %d to update, %d to remove, %d unchanged\n", len(plan.Add), len(plan.Update), len(plan.Remove), plan.Unchanged)
+}
+
+// entryChanges describes how an entry is updated (e.g. negated: no -> yes)
+func entryChanges(update vcl.ACLEntryUpdate) string {
+	var changes []string
+
+	if update.Old.Negated != update.New.Negated {
+		changes = append(changes, fmt.Sprintf("negated: %s -> %s", yesNo(update.Old.Negated), yesNo(update.New.Negated)))
+	}
+	if update.Old.Comment != update.New.Comment {
+		changes = append(changes, fmt.Sprintf("comment: %q -> %q", update.Old.Comment, update.New.Comment))
+	}
+
+	return strings.Join(changes, ", ")
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/integralist/go-fastly-cli/auth"
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+// errMissingToken is reported when no token was entered during login
+var errMissingToken = errors.New("no token was provided")
+
+// AuthLogin reads a token from stdin, verifies it with the Fastly API and then
+// stores it as the named profile (fallback: -profile or the default)
+func AuthLogin(f flags.Flags, name string) {
+	creds := loadCredentials()
+	login(creds, creds.Selected(profileName(f, name)), *f.Sub.AuthHelper)
+}
+
+// AuthLogout removes the named profile (fallback: -profile or the default)
+func AuthLogout(f flags.Flags, name string) {
+	creds := loadCredentials()
+	logout(creds, creds.Selected(profileName(f, name)))
+}
+
+// AuthList displays the stored profiles
+func AuthList() {
+	listProfiles(loadCredentials())
+}
+
+func loadCredentials() *auth.Credentials {
+	creds, err := auth.Load()
+	if err != nil {
+		output.Fail(err)
+	}
+	return creds
+}
+
+// profileName returns the profile named on the command line, or -profile
+func profileName(f flags.Flags, name string) string {
+	if name != "" {
+		return name
+	}
+	return *f.Top.Profile
+}
+
+// login reads a token from stdin, verifies it with the Fastly API and then
+// stores it as the named profile
+func login(creds *auth.Credentials, name, helper string) {
+	if !output.Structured() {
+		fmt.Printf("Fastly API token for profile '%s': ", common.Yellow(name))
+	}
+
+	// the error is ignored, as a token without a trailing newline is fine
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	token := strings.TrimSpace(line)
+	if token == "" {
+		output.Failf(errMissingToken, "\n%s\n", common.Red(errMissingToken))
+	}
+
+	client, err := fastly.NewClient(token)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	verified, err := api.VerifyToken(client)
+	if err != nil {
+		output.Failf(err, "\nUnable to verify the API token:\n\n%s\n\n", common.Red(err))
+	}
+
+	if err := creds.Store(name, token, helper); err != nil {
+		output.Failf(err, "\nUnable to store the API token:\n\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Token(name, verified))
+		common.Success()
+	}
+
+	fmt.Printf("\nStored API token '%s' as profile '%s' (scope: %s, expires: %s)\n", common.Yellow(verified.Name), common.Green(name), common.Yellow(verified.Scope), common.Yellow(verified.Expiry()))
+}
+
+func logout(creds *auth.Credentials, name string) {
+	if err := creds.Remove(name); err != nil {
+		output.Failf(err, "Unable to remove profile '%s':\n\t%s\n", common.Yellow(name), common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Profiles(creds))
+		common.Success()
+	}
+
+	fmt.Printf("Removed profile '%s'\n", common.Yellow(name))
+}
+
+func listProfiles(creds *auth.Credentials) {
+	if output.Structured() {
+		output.Write(output.Profiles(creds))
+		common.Success()
+	}
+
+	names := creds.Names()
+	if len(names) == 0 {
+		fmt.Println("No profiles are stored (try: fastly auth login)")
+		return
+	}
+
+	fmt.Printf("Profiles stored in: %s\n\n", common.Yellow(creds.File()))
+	for _, name := range names {
+		storage := "credentials file"
+		if helper := creds.Profiles[name].Helper; helper != "" {
+			storage = "helper: " + helper
+		}
+
+		if name == creds.Selected("") {
+			fmt.Printf("  * %s %s (%s)\n", name, common.Green("(default)"), storage)
+			continue
+		}
+		fmt.Printf("  * %s (%s)\n", name, storage)
+	}
+}
@@ -0,0 +1,352 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// BackendList displays the backends found in the remote service version
+// each of the services provided to -service is listed at the same time
+func BackendList(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.BackendListVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.ListBackends(ctx, client, vcl.ListOptions{
+			Service: service,
+			Version: selectedVersion,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printBackends(result) },
+			Doc:     output.Backends(result),
+		}
+	})
+}
+
+// BackendShow displays every setting of the backend
+func BackendShow(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.BackendShowVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	result, err := vcl.GetBackend(ctx, client, vcl.BackendShowOptions{
+		Service: *f.Top.Service,
+		Version: selectedVersion,
+		Name:    name,
+	})
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.BackendShow(result))
+		return
+	}
+
+	fmt.Printf("\nThe backend '%s' in version '%s'\n\n", common.Yellow(result.Backend.Name), common.Yellow(result.Version))
+	printBackendFields(result.Backend)
+	fmt.Println()
+}
+
+// BackendCreate creates the backend in the selected version
+func BackendCreate(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.BackendOptions{
+		Target:   target(f, f.Sub.BackendCreate),
+		Name:     name,
+		Settings: backendSettings(f.Top.BackendCreate, f.Sub.BackendCreateSettings),
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanCreateBackend(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Backend(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe backend '%s' would be created in %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printFieldChanges(result.Changes)
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.CreateBackend(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Backend(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The backend '%s' in version '%s' was created successfully\n\n", common.Green(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// BackendUpdate changes the settings of the backend provided as flags in the
+// selected version, no version is cloned when none of the settings differ
+func BackendUpdate(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.BackendOptions{
+		Target:   target(f, f.Sub.BackendUpdate),
+		Name:     name,
+		Settings: backendSettings(f.Top.BackendUpdate, f.Sub.BackendUpdateSettings),
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanUpdateBackend(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Backend(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		if len(result.Changes) == 0 {
+			fmt.Printf("\nThe backend '%s' in version '%s' already has these settings, nothing would change\n\n", common.Yellow(name), common.Yellow(result.Version))
+			return
+		}
+
+		fmt.Printf("\nThe backend '%s' would be updated in %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printFieldChanges(result.Changes)
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.UpdateBackend(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Backend(result))
+		return
+	}
+
+	if len(result.Changes) == 0 {
+		fmt.Printf("\nThe backend '%s' in version '%s' already has these settings, nothing was changed\n\n", common.Yellow(name), common.Yellow(result.Version))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The backend '%s' in version '%s' was updated successfully\n\n", common.Green(name), common.Yellow(result.Version))
+	printFieldChanges(result.Changes)
+	printWarnings(result.Warnings)
+}
+
+// BackendDelete deletes the backend from the selected version
+func BackendDelete(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.BackendOptions{
+		Target: target(f, f.Sub.BackendDelete),
+		Name:   name,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanDeleteBackend(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Backend(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe backend '%s' would be deleted from %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.DeleteBackend(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Backend(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The backend '%s' in version '%s' was deleted successfully\n\n", common.Red(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// BackendDiff compares the backends of two remote service versions field by
+// field, `to` defaults to the latest version when it isn't provided
+// (each of the services provided to -service is compared at the same time)
+func BackendDiff(ctx context.Context, f flags.Flags, client api.Client, from, to string) {
+	fromVersion, err := common.ParseVersion(from)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	toVersion, err := common.ParseVersion(to)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.DiffBackends(ctx, client, vcl.BackendDiffOptions{
+			Service: service,
+			From:    fromVersion,
+			To:      toVersion,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		code := common.ExitSuccess
+		if result.Differences() > 0 {
+			code = common.ExitDifferences
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printBackendDiff(result) },
+			Doc:     output.BackendDiff(result),
+			Code:    code,
+		}
+	})
+}
+
+// backendSettings returns the settings of the backend flags the user provided
+// so an update only changes those settings
+func backendSettings(fs *flag.FlagSet, bf flags.BackendFlags) vcl.BackendSettings {
+	var s vcl.BackendSettings
+
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "address":
+			s.Address = bf.Address
+		case "auto-loadbalance":
+			s.AutoLoadbalance = bf.AutoLoadbalance
+		case "between-bytes-timeout":
+			s.BetweenBytesTimeout = bf.BetweenBytesTimeout
+		case "connect-timeout":
+			s.ConnectTimeout = bf.ConnectTimeout
+		case "first-byte-timeout":
+			s.FirstByteTimeout = bf.FirstByteTimeout
+		case "healthcheck":
+			s.HealthCheck = bf.HealthCheck
+		case "max-conn":
+			s.MaxConn = bf.MaxConn
+		case "max-tls-version":
+			s.MaxTLSVersion = bf.MaxTLSVersion
+		case "min-tls-version":
+			s.MinTLSVersion = bf.MinTLSVersion
+		case "override-host":
+			s.OverrideHost = bf.OverrideHost
+		case "port":
+			s.Port = bf.Port
+		case "request-condition":
+			s.RequestCondition = bf.RequestCondition
+		case "shield":
+			s.Shield = bf.Shield
+		case "ssl-cert-hostname":
+			s.SSLCertHostname = bf.SSLCertHostname
+		case "ssl-check-cert":
+			s.SSLCheckCert = bf.SSLCheckCert
+		case "ssl-sni-hostname":
+			s.SSLSNIHostname = bf.SSLSNIHostname
+		case "use-ssl":
+			s.UseSSL = bf.UseSSL
+		case "weight":
+			s.Weight = bf.Weight
+		}
+	})
+
+	return s
+}
+
+func printBackends(result *vcl.BackendsResult) {
+	fmt.Printf("Backends found for service version: %s\n\n", common.Yellow(result.Version))
+
+	if len(result.Backends) == 0 {
+		fmt.Println("There are no backends")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tADDRESS\tPORT\tTLS\tHEALTHCHECK")
+	for _, b := range result.Backends {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", b.Name, b.Address, b.Port, yesNo(b.UseSSL), b.HealthCheck)
+	}
+	w.Flush()
+}
+
+func printBackendFields(b vcl.Backend) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, field := range b.Fields() {
+		fmt.Fprintf(w, "  %s\t%s\n", field.Name, field.Value)
+	}
+	w.Flush()
+}
+
+func printFieldChanges(changes []vcl.FieldChange) {
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+	}
+	if len(changes) > 0 {
+		fmt.Println()
+	}
+}
+
+// printWarnings reports the references a change leaves unused (e.g. a
+// healthcheck no backend uses any more), they don't prevent the change
+func printWarnings(problems []vcl.Problem) {
+	for _, p := range problems {
+		fmt.Printf("%s %s\n", common.Yellow("warning:"), p)
+	}
+	if len(problems) > 0 {
+		fmt.Println()
+	}
+}
+
+func printBackendDiff(result *vcl.BackendDiffResult) {
+	if result.Differences() == 0 {
+		fmt.Printf("No difference between the backends of version %d and version %d\n", result.From, result.To)
+		return
+	}
+
+	for _, bd := range result.Backends {
+		switch {
+		case bd.From == nil:
+			fmt.Printf("\nThe backend '%s' was added in version %d\n\n", common.Green(bd.Name), result.To)
+		case bd.To == nil:
+			fmt.Printf("\nThe backend '%s' was removed in version %d\n\n", common.Red(bd.Name), result.To)
+		default:
+			fmt.Printf("\nThe backend '%s' differs between version %d and version %d\n\n", common.Yellow(bd.Name), result.From, result.To)
+		}
+		printFieldChanges(bd.Changes)
+	}
+}
@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// ConditionList displays the conditions found in the remote service version
+// each of the services provided to -service is listed at the same time
+func ConditionList(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.ConditionListVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.ListConditions(ctx, client, vcl.ListOptions{
+			Service: service,
+			Version: selectedVersion,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printConditions(result) },
+			Doc:     output.Conditions(result),
+		}
+	})
+}
+
+// ConditionCreate creates the condition in the selected version
+func ConditionCreate(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.ConditionOptions{
+		Target:   target(f, f.Sub.ConditionCreate),
+		Name:     name,
+		Settings: conditionSettings(f.Top.ConditionCreate, f.Sub.ConditionCreateSettings),
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanCreateCondition(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Condition(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe condition '%s' would be created in %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.CreateCondition(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Condition(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The condition '%s' in version '%s' was created successfully\n\n", common.Green(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// ConditionUpdate changes the settings of the condition provided as flags in
+// the selected version, no version is cloned when none of the settings differ
+func ConditionUpdate(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.ConditionOptions{
+		Target:   target(f, f.Sub.ConditionUpdate),
+		Name:     name,
+		Settings: conditionSettings(f.Top.ConditionUpdate, f.Sub.ConditionUpdateSettings),
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanUpdateCondition(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Condition(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		if result.Unchanged {
+			fmt.Printf("\nThe condition '%s' in version '%s' already has these settings, nothing would change\n\n", common.Yellow(name), common.Yellow(result.Version))
+			return
+		}
+
+		fmt.Printf("\nThe condition '%s' would be updated in %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.UpdateCondition(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Condition(result))
+		return
+	}
+
+	if result.Unchanged {
+		fmt.Printf("\nThe condition '%s' in version '%s' already has these settings, nothing was changed\n\n", common.Yellow(name), common.Yellow(result.Version))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The condition '%s' in version '%s' was updated successfully\n\n", common.Green(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// ConditionDelete deletes the condition from the selected version, unless a
+// backend still references it
+func ConditionDelete(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.ConditionOptions{
+		Target: target(f, f.Sub.ConditionDelete),
+		Name:   name,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanDeleteCondition(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Condition(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe condition '%s' would be deleted from %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.DeleteCondition(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Condition(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The condition '%s' in version '%s' was deleted successfully\n\n", common.Red(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// conditionSettings returns the settings of the condition flags the user
+// provided so an update only changes those settings
+func conditionSettings(fs *flag.FlagSet, cf flags.ConditionFlags) vcl.ConditionSettings {
+	var s vcl.ConditionSettings
+
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "priority":
+			s.Priority = cf.Priority
+		case "statement":
+			s.Statement = cf.Statement
+		case "type":
+			s.Type = cf.Type
+		}
+	})
+
+	return s
+}
+
+func printConditions(result *vcl.ConditionsResult) {
+	fmt.Printf("Conditions found for service version: %s\n\n", common.Yellow(result.Version))
+
+	if len(result.Conditions) == 0 {
+		fmt.Println("There are no conditions")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tPRIORITY\tSTATEMENT")
+	for _, c := range result.Conditions {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", c.Name, c.Type, c.Priority, c.Statement)
+	}
+	w.Flush()
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/config"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// ConfigShow prints the resolved value of each setting and where it came from
+func ConfigShow(resolved *config.Resolved) {
+	settings := make([]config.Setting, len(resolved.Settings))
+	copy(settings, resolved.Settings)
+
+	for i, setting := range settings {
+		if setting.Name == "token" {
+			settings[i].Value = maskToken(setting.Value)
+		}
+		if setting.Name == "main" && setting.Source == config.SourceDefault {
+			settings[i].Detail = vcl.MainMarker + " file in -dir (if present)"
+		}
+	}
+
+	if output.Structured() {
+		output.Write(output.Config(resolved, settings))
+		common.Success()
+	}
+
+	file := resolved.File
+	if file == "" {
+		file = "none found"
+	}
+	env := resolved.Env
+	if env == "" {
+		env = "none selected"
+	}
+
+	fmt.Printf("\nConfig file: %s\nEnvironment: %s\n\n", common.Yellow(file), common.Yellow(env))
+
+	for _, setting := range settings {
+		source := string(setting.Source)
+		if setting.Detail != "" {
+			source = fmt.Sprintf("%s: %s", source, setting.Detail)
+		}
+
+		fmt.Printf("  %-12s %s (%s)\n", setting.Name, common.Green(fmt.Sprintf("%-40q", setting.Value)), source)
+	}
+
+	fmt.Println()
+}
+
+// maskToken hides all but the last four characters of the api token
+func maskToken(token string) string {
+	if len(token) <= 4 {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", len(token)-4) + token[len(token)-4:]
+}
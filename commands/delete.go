@@ -1,63 +1,58 @@
 package commands
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 
 	"github.com/integralist/go-fastly-cli/common"
 	"github.com/integralist/go-fastly-cli/flags"
-	"github.com/sethvargo/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
 )
 
 // Delete specified VCL file in the remote service version
-func Delete(f flags.Flags, client *fastly.Client) {
-	var serviceVersion string
-	var selectedVersion int
-
+func Delete(ctx context.Context, f flags.Flags, client api.Client) {
 	deleteVCL := *f.Sub.VclName
 
 	if deleteVCL == "" {
-		fmt.Println("You must provide a VCL name\n  e.g. -name test_file")
-		common.Failure()
+		output.Failf(vcl.ErrMissingName, "You must provide a VCL name\n  e.g. -name test_file\n")
 	}
 
-	deleteVersion := *f.Sub.VclDeleteVersion
-	fastlyServiceID = *f.Top.Service
+	selectedVersion, err := common.ParseVersion(*f.Sub.VclDeleteVersion)
+	if err != nil {
+		output.Fail(err)
+	}
 
-	if deleteVersion != "" {
-		serviceVersion = deleteVersion
-	} else {
-		var err error
-		selectedVersion, err = common.GetLatestVCLVersion(fastlyServiceID, client)
-		if err != nil {
-			fmt.Println("Sorry, we were unable to acquire the latest service version")
-			fmt.Println("Please try again, or provide a specific version by using the -version flag")
-			common.Failure()
-		}
+	opts := vcl.DeleteOptions{
+		Service: *f.Top.Service,
+		Version: selectedVersion,
+		Name:    deleteVCL,
 	}
 
-	// If we're not the type default, then we have the latest service version
-	if selectedVersion != 0 {
-		fmt.Printf("You didn't provide a specific service version, so we'll use the latest one: %s\n", common.Yellow(selectedVersion))
-	} else {
-		// Otherwise the user provided a service version, which we need to convert
-		var err error
-		selectedVersion, err = strconv.Atoi(serviceVersion)
+	if *f.Top.DryRun {
+		plan, err := vcl.PlanDelete(ctx, client, opts)
 		if err != nil {
-			fmt.Printf("Unable to convert provided version:\n\t%+v\n", err)
-			common.Failure()
+			output.Fail(err)
 		}
-	}
 
-	err := client.DeleteVCL(&fastly.DeleteVCLInput{
-		Service: fastlyServiceID,
-		Version: selectedVersion,
-		Name:    deleteVCL,
-	})
+		printPlan(plan, true)
+		common.Success()
+	}
 
+	result, err := vcl.Delete(ctx, client, opts)
 	if err != nil {
-		fmt.Printf("\nUnable to delete the specified VCL file from version: %s\n\n", common.Yellow(selectedVersion))
-		fmt.Printf("Error:\n%s", common.Red(err))
+		output.Failf(err, "\nUnable to delete the specified VCL file\n\nError:\n%s\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Delete(result))
+		common.Success()
+	}
+
+	// If the user didn't provide a version, then we used the latest one
+	if selectedVersion == 0 {
+		fmt.Printf("You didn't provide a specific service version, so we used the latest one: %s\n", common.Yellow(result.Version))
 	}
 
 	common.Success()
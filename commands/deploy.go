@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+	"github.com/integralist/go-fastly-cli/standalone"
+)
+
+// Deploy uploads local files to a remote service version, validates the
+// version and then activates it (aborting if any of those steps fail), the
+// activation is confirmed in the same way as `version activate`
+//
+// The final line of output is `FASTLY_VERSION=<number>` so scripts can
+// determine which version was deployed to
+func Deploy(ctx context.Context, f flags.Flags, client api.Client, guard standalone.ActivateOptions) {
+	if *f.Sub.DeployCloneVersion != "" && *f.Sub.DeployVersion != "" {
+		output.Failf(vcl.ErrConflictingVersions, "Please do not provide both -clone and -version flags\n")
+	}
+
+	cloneVersion, err := common.ParseVersion(*f.Sub.DeployCloneVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	deployVersion, err := common.ParseVersion(*f.Sub.DeployVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	match, skip := skipMatch(f)
+	comment := versionComment(f, *f.Sub.DeployComment)
+
+	opts := vcl.DeployOptions{
+		Service:   *f.Top.Service,
+		Directory: *f.Top.Directory,
+		Match:     match,
+		Skip:      skip,
+		Clone:     cloneVersion,
+		Version:   deployVersion,
+		Latest:    *f.Sub.DeployLatest,
+		Main:      *f.Sub.DeployMainVCL,
+		Comment:   comment,
+
+		Concurrency: *f.Top.Concurrency,
+	}
+
+	if *f.Top.DryRun {
+		plan, err := vcl.PlanUpload(ctx, client, vcl.UploadOptions(opts))
+		if err != nil {
+			output.Fail(err)
+		}
+
+		printPlan(plan, true)
+		if !output.Structured() {
+			fmt.Printf("\nThe resulting version would then be validated and activated\n")
+		}
+		common.Success()
+	}
+
+	if err := guard.Check(); err != nil {
+		output.Fail(err)
+	}
+
+	result, err := vcl.Deploy(ctx, client, opts, guard.Confirm)
+
+	if output.Structured() {
+		output.Write(output.Deploy(result, err))
+		exitOnDeployFailure(err)
+		return
+	}
+
+	if result.Upload != nil {
+		if result.Upload.ClonedFrom != 0 {
+			fmt.Printf("Successfully created new version %d from existing version %d\n\n", result.Upload.Version, result.Upload.ClonedFrom)
+			if result.Upload.Comment != "" {
+				fmt.Printf("With the comment: %q\n\n", result.Upload.Comment)
+			}
+		}
+
+		for _, fr := range result.Upload.Files {
+			handleResponse(fr, result.Upload.Version)
+		}
+
+		handleMainResponse(result.Upload.Main, result.Upload.MainErr, result.Upload.Version)
+	}
+
+	if result.Validate != nil && !result.Validate.Valid {
+		fmt.Printf("\nVersion '%s' is not valid:\n\n%s\n", common.Yellow(result.Validate.Version), common.Red(result.Validate.Message))
+	}
+
+	if err == standalone.ErrNotConfirmed {
+		fmt.Printf("\nVersion '%s' was not activated\n\n", common.Yellow(result.Version()))
+		printDeployedVersion(result)
+		common.Success()
+	}
+
+	if err != nil {
+		fmt.Printf("\nDeploy aborted: %s\n\n", common.Red(err))
+		printDeployedVersion(result)
+		exitOnDeployFailure(err)
+	}
+
+	fmt.Printf("\nService '%s' now has version '%s' activated\n\n", common.Yellow(result.Activate.Service), common.Green(result.Activate.Version))
+	printDeployedVersion(result)
+}
+
+func printDeployedVersion(result *vcl.DeployResult) {
+	if version := result.Version(); version != 0 {
+		fmt.Printf("FASTLY_VERSION=%d\n", version)
+	}
+}
+
+// exitOnDeployFailure exits with the partial failure exit code when some of
+// the files failed to upload, otherwise with the general error exit code
+func exitOnDeployFailure(err error) {
+	if err == nil {
+		return
+	}
+
+	if err == vcl.ErrUploadFailed {
+		common.PartialFailure()
+	}
+	common.Failure()
+}
@@ -0,0 +1,373 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// errImportConfirmation is reported when an import can't be confirmed
+var errImportConfirmation = errors.New("imported items are live as soon as they're applied, please provide -yes to import them with -output")
+
+// DictionaryList displays the dictionaries found in the remote service version
+// each of the services provided to -service is listed at the same time
+func DictionaryList(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.DictionaryListVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.ListDictionaries(ctx, client, vcl.ListOptions{
+			Service: service,
+			Version: selectedVersion,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printDictionaries(result) },
+			Doc:     output.Dictionaries(result),
+		}
+	})
+}
+
+// DictionaryCreate creates an empty dictionary in the selected version
+func DictionaryCreate(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.DictionaryOptions{
+		Target:    target(f, f.Sub.DictionaryCreate),
+		Name:      name,
+		WriteOnly: *f.Sub.DictionaryWriteOnly,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanCreateDictionary(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Dictionary(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe dictionary '%s' would be created in %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		return
+	}
+
+	result, err := vcl.CreateDictionary(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Dictionary(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The dictionary '%s' in version '%s' was created successfully\n\n", common.Green(name), common.Yellow(result.Version))
+}
+
+// DictionaryDelete deletes the dictionary (along with its items) from the
+// selected version
+func DictionaryDelete(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.DictionaryOptions{
+		Target: target(f, f.Sub.DictionaryDelete),
+		Name:   name,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanDeleteDictionary(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Dictionary(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe dictionary '%s' would be deleted from %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		return
+	}
+
+	result, err := vcl.DeleteDictionary(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Dictionary(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The dictionary '%s' in version '%s' was deleted successfully\n\n", common.Red(name), common.Yellow(result.Version))
+}
+
+// ItemList displays the items of the dictionary
+func ItemList(ctx context.Context, f flags.Flags, client api.Client, dictionary string) {
+	opts := itemOptions(f, *f.Sub.ItemListVersion, dictionary)
+
+	result, err := vcl.DictionaryItems(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.DictionaryItems(result))
+		return
+	}
+
+	fmt.Printf("Items found in the dictionary: %s\n\n", common.Yellow(result.Dictionary.Name))
+
+	if len(result.Items) == 0 {
+		fmt.Println("There are no items")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE")
+	for _, item := range result.Items {
+		fmt.Fprintf(w, "%s\t%s\n", item.Key, item.Value)
+	}
+	w.Flush()
+}
+
+// ItemGet displays the value of a single dictionary item
+func ItemGet(ctx context.Context, f flags.Flags, client api.Client, dictionary, key string) {
+	opts := itemOptions(f, *f.Sub.ItemGetVersion, dictionary)
+	opts.Key = key
+
+	result, err := vcl.GetDictionaryItem(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.DictionaryKey(result))
+		return
+	}
+
+	// only the value is printed so it can be used by scripts
+	fmt.Println(result.Value)
+}
+
+// ItemSet creates or updates a dictionary item (the change is live
+// immediately, as the items aren't versioned)
+func ItemSet(ctx context.Context, f flags.Flags, client api.Client, dictionary, key, value string) {
+	opts := itemOptions(f, *f.Sub.ItemSetVersion, dictionary)
+	opts.Key = key
+	opts.Value = value
+
+	if *f.Top.DryRun {
+		dict, err := vcl.LookupDictionary(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.DictionaryKey(&vcl.ItemResult{
+				Service:        opts.Service,
+				Dictionary:     *dict,
+				DictionaryItem: vcl.DictionaryItem{Key: key, Value: value},
+			})
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe item '%s' would be set to %q in the dictionary '%s'\n\n", common.Yellow(key), value, common.Yellow(dict.Name))
+		return
+	}
+
+	result, err := vcl.SetDictionaryItem(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.DictionaryKey(result))
+		return
+	}
+
+	fmt.Printf("\nThe item '%s' in the dictionary '%s' was set successfully (it's now live)\n\n", common.Green(key), common.Yellow(result.Dictionary.Name))
+}
+
+// ItemDelete deletes a dictionary item (the change is live immediately, as
+// the items aren't versioned)
+func ItemDelete(ctx context.Context, f flags.Flags, client api.Client, dictionary, key string) {
+	opts := itemOptions(f, *f.Sub.ItemDeleteVersion, dictionary)
+	opts.Key = key
+
+	if *f.Top.DryRun {
+		result, err := vcl.GetDictionaryItem(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.DictionaryKey(result)
+			doc.Deleted = true
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe item '%s' would be deleted from the dictionary '%s'\n\n", common.Yellow(key), common.Yellow(result.Dictionary.Name))
+		return
+	}
+
+	result, err := vcl.DeleteDictionaryItem(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		doc := output.DictionaryKey(result)
+		doc.Deleted = true
+		output.Write(doc)
+		return
+	}
+
+	fmt.Printf("\nThe item '%s' in the dictionary '%s' was deleted successfully (it's now live)\n\n", common.Red(key), common.Yellow(result.Dictionary.Name))
+}
+
+// ItemImport makes the dictionary contain exactly the items of a local JSON
+// or CSV file, after confirming the added, changed and removed items
+func ItemImport(ctx context.Context, f flags.Flags, client api.Client, dictionary, file string) {
+	if output.Structured() && !*f.Top.DryRun && !*f.Sub.ItemImportConfirm {
+		output.Fail(errImportConfirmation)
+	}
+
+	plan, err := vcl.PlanImport(ctx, client, vcl.ImportOptions{
+		Service:    *f.Top.Service,
+		Dictionary: dictionary,
+		Version:    itemVersion(*f.Sub.ItemImportVersion),
+		File:       file,
+		Format:     *f.Sub.ItemImportFormat,
+	})
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if *f.Top.DryRun {
+		if output.Structured() {
+			doc := output.Import(plan, nil)
+			doc.DryRun = true
+			output.Write(doc)
+			common.Success()
+		}
+		printImportPlan(plan, true)
+		common.Success()
+	}
+
+	if plan.Empty() {
+		if output.Structured() {
+			output.Write(output.Import(plan, nil))
+		} else {
+			printImportPlan(plan, false)
+		}
+		common.Success()
+	}
+
+	if !output.Structured() {
+		printImportPlan(plan, false)
+	}
+
+	if !*f.Sub.ItemImportConfirm && !common.Confirm("\nImport these items (they will be live immediately)?") {
+		fmt.Println("\nNo changes were applied")
+		common.Success()
+	}
+
+	result, err := vcl.ApplyImport(ctx, client, plan)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Import(plan, result))
+		return
+	}
+
+	fmt.Printf("\nThe items of the dictionary '%s' were imported successfully in %d batch(es): %s added, %s changed, %s removed\n\n",
+		common.Yellow(plan.Dictionary.Name), result.Batches, common.Green(result.Added), common.Yellow(result.Changed), common.Red(result.Removed))
+}
+
+// itemOptions returns the options identifying the dictionary of an items
+// command
+func itemOptions(f flags.Flags, version, dictionary string) vcl.ItemOptions {
+	return vcl.ItemOptions{
+		Service:    *f.Top.Service,
+		Dictionary: dictionary,
+		Version:    itemVersion(version),
+	}
+}
+
+func itemVersion(version string) int {
+	selectedVersion, err := common.ParseVersion(version)
+	if err != nil {
+		output.Fail(err)
+	}
+	return selectedVersion
+}
+
+func printDictionaries(result *vcl.DictionariesResult) {
+	fmt.Printf("Dictionaries found for service version: %s\n\n", common.Yellow(result.Version))
+
+	if len(result.Dictionaries) == 0 {
+		fmt.Println("There are no dictionaries")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID\tWRITE ONLY")
+	for _, d := range result.Dictionaries {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", d.Name, d.ID, yesNo(d.WriteOnly))
+	}
+	w.Flush()
+}
+
+// printImportPlan displays the items an import will add, change and remove
+func printImportPlan(plan *vcl.ImportPlan, dryRun bool) {
+	if plan.Empty() {
+		fmt.Printf("\nThe dictionary '%s' of service '%s' already contains the items (%d unchanged)\n\n", common.Yellow(plan.Dictionary.Name), common.Yellow(plan.Service), plan.Unchanged)
+		return
+	}
+
+	will := "will"
+	if dryRun {
+		will = "would"
+	}
+
+	fmt.Printf("\nThe following changes %s be made to the items of the dictionary '%s' of service '%s':\n\n", will, common.Yellow(plan.Dictionary.Name), common.Yellow(plan.Service))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	for _, change := range plan.Add {
+		fmt.Fprintf(w, "  %s\t%s\t%q\n", common.Green("+ add"), change.Key, change.New)
+	}
+	for _, change := range plan.Change {
+		fmt.Fprintf(w, "  %s\t%s\t%q -> %q\n", common.Yellow("~ change"), change.Key, change.Old, change.New)
+	}
+	for _, change := range plan.Remove {
+		fmt.Fprintf(w, "  %s\t%s\t%q\n", common.Red("- remove"), change.Key, change.Old)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d to add, %d to change, %d to remove, %d unchanged\n", len(plan.Add), len(plan.Change), len(plan.Remove), plan.Unchanged)
+}
@@ -1,133 +1,90 @@
 package commands
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
-	"strconv"
-	"strings"
 
 	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/diff"
 	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
 
 	"github.com/fatih/color"
-	"github.com/sethvargo/go-fastly/fastly"
-	"github.com/sirupsen/logrus"
 )
 
 // Diff compares local VCL to the specificed remote service vcl version
-func Diff(f flags.Flags, client *fastly.Client) {
-	configureSkipMatch(f)
+// each of the services provided to -service is compared at the same time
+func Diff(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.VclVersion)
+	if err != nil {
+		output.Fail(err)
+	}
 
-	// store value rather than dereference pointer multiple times later
-	fastlyServiceID = *f.Top.Service
+	match, skip := skipMatch(f)
 
-	var (
-		selectedVersion int
-		err             error
-	)
+	if *f.Sub.DiffContext < 0 {
+		output.Fail(diff.ErrNegativeContext)
+	}
 
-	if *f.Sub.VclVersion != "" {
-		selectedVersion, err = strconv.Atoi(*f.Sub.VclVersion)
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.Diff(ctx, client, vcl.DiffOptions{
+			Service:   service,
+			Directory: *f.Top.Directory,
+			Match:     match,
+			Skip:      skip,
+			Version:   selectedVersion,
+			Context:   *f.Sub.DiffContext,
+
+			Concurrency: *f.Top.Concurrency,
+		})
 		if err != nil {
-			fmt.Println(err)
-			common.Failure()
+			return output.ErrorReport(service, err, "%s\n", err)
 		}
-	} else {
-		latestVersion, err := common.GetLatestVCLVersion(*f.Top.Service, client)
-		if err != nil {
-			fmt.Println(err)
-			common.Failure()
-		}
-		selectedVersion = latestVersion
-	}
-
-	processFiles(selectedVersion, getVCL, processDiff, f, client)
-}
-
-func getVCL(selectedVersion int, path string, client *fastly.Client, ch chan vclResponse) {
-	defer wg.Done()
-
-	logger.WithFields(logrus.Fields{
-		"channel":  fmt.Sprintf("%p", ch),
-		"length":   len(ch),
-		"capacity": cap(ch),
-	}).Debug("channel view - start of getVCL")
-
-	name := extractName(path)
-
-	logger.WithFields(logrus.Fields{
-		"path": path,
-		"name": name,
-	}).Debug("file processor")
-
-	vclFile, err := client.GetVCL(&fastly.GetVCLInput{
-		Service: fastlyServiceID,
-		Version: selectedVersion,
-		Name:    name,
-	})
-
-	if err != nil {
-		errMsg := fmt.Sprintf("error: %s", err)
-
-		logger.WithFields(logrus.Fields{
-			"name":  name,
-			"error": errMsg,
-		}).Debug("error retrieving vcl file from fastly")
 
-		ch <- vclResponse{
-			Path:    path,
-			Name:    name,
-			Content: errMsg,
-			Error:   true,
+		return output.Report{
+			Service: service,
+			Text: func() {
+				for _, fd := range result.Files {
+					printDiff(fd, result.Version)
+				}
+				if len(result.Failed) > 0 {
+					fmt.Printf("\n%s\n", common.Red(result.Failed))
+				}
+			},
+			Doc:  output.Diff(result),
+			Code: diffExitCode(result),
 		}
+	})
+}
 
-		logger.WithFields(logrus.Fields{
-			"channel":  fmt.Sprintf("%p", ch),
-			"length":   len(ch),
-			"capacity": cap(ch),
-		}).Debug("channel view - failed retrieval")
-	} else {
-		logger.WithField("name", name).Debug("successfully retrieved vcl file from fastly")
-
-		ch <- vclResponse{
-			Path:    path,
-			Name:    name,
-			Content: vclFile.Content,
-			Error:   false,
-		}
+// diffExitCode is the general error exit code when any file couldn't be
+// compared, or the differences exit code when any file differs
+func diffExitCode(result *vcl.DiffResult) int {
+	if len(result.Failed) > 0 {
+		return common.ExitFailure
+	}
 
-		logger.WithFields(logrus.Fields{
-			"channel":  fmt.Sprintf("%p", ch),
-			"length":   len(ch),
-			"capacity": cap(ch),
-		}).Debug("channel view - successful retrieval")
+	if result.Differences() > 0 {
+		return common.ExitDifferences
 	}
+
+	return common.ExitSuccess
 }
 
-func processDiff(vr vclResponse, debug bool, selectedVersion int) {
-	var (
-		err    error
-		cmdOut []byte
-	)
-	cmdName := "diff"
-	cmdArgs := []string{
-		"--ignore-all-space",
-		"--ignore-blank-lines",
-		"--ignore-matching-lines",
-		"^[[:space:]]\\+#",
-		"-", // the dash (-) indicates that the first file comes from stdin
-		vr.Path,
+func printDiff(fd vcl.FileDiff, selectedVersion int) {
+	if fd.Err != nil {
+		fmt.Printf("\nUnable to compare '%s' against version (%d):\n\t%s\n", common.Yellow(fd.Name), selectedVersion, common.Red(fd.Err))
+		return
 	}
-	cmd := exec.Command(cmdName, cmdArgs...)
-	cmd.Stdin = strings.NewReader(vr.Content)
 
-	if cmdOut, err = cmd.Output(); err != nil {
-		color.Red("\nThere was a difference between the version (%d) of '%s' and the version found locally\n\t%s\n", selectedVersion, vr.Name, vr.Path)
-
-		if debug == true {
-			fmt.Printf("\n%s\n", string(cmdOut))
-		}
-	} else {
-		color.Green("\nNo difference between the version (%d) of '%s' and the version found locally\n\t%s\n", selectedVersion, vr.Name, vr.Path)
+	if fd.Result.Equal() {
+		color.Green("\nNo difference between the version (%d) of '%s' and the version found locally\n\t%s\n", selectedVersion, fd.Name, fd.Path)
+		return
 	}
+
+	color.Red("\nThere was a difference between the version (%d) of '%s' and the version found locally\n\t%s\n", selectedVersion, fd.Name, fd.Path)
+	fmt.Printf("\n%s lines added, %s lines removed\n\n", common.Green(fd.Result.Added), common.Red(fd.Result.Removed))
+	fmt.Print(fd.Unified(selectedVersion))
 }
@@ -0,0 +1,220 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// DirectorList displays the directors found in the remote service version
+// each of the services provided to -service is listed at the same time
+func DirectorList(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.DirectorListVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.ListDirectors(ctx, client, vcl.ListOptions{
+			Service: service,
+			Version: selectedVersion,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printDirectors(result) },
+			Doc:     output.Directors(result),
+		}
+	})
+}
+
+// DirectorCreate creates the director with the backends provided to
+// -backends in the selected version
+func DirectorCreate(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.DirectorOptions{
+		Target:   target(f, f.Sub.DirectorCreate),
+		Name:     name,
+		Settings: directorSettings(f.Top.DirectorCreate, f.Sub.DirectorCreateSettings),
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanCreateDirector(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Director(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe director '%s' would be created in %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.CreateDirector(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Director(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The director '%s' in version '%s' was created successfully\n\n", common.Green(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// DirectorUpdate changes the settings of the director provided as flags in
+// the selected version (-backends replaces its backends), no version is cloned
+// when none of the settings differ
+func DirectorUpdate(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.DirectorOptions{
+		Target:   target(f, f.Sub.DirectorUpdate),
+		Name:     name,
+		Settings: directorSettings(f.Top.DirectorUpdate, f.Sub.DirectorUpdateSettings),
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanUpdateDirector(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Director(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		if result.Unchanged {
+			fmt.Printf("\nThe director '%s' in version '%s' already has these settings, nothing would change\n\n", common.Yellow(name), common.Yellow(result.Version))
+			return
+		}
+
+		fmt.Printf("\nThe director '%s' would be updated in %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.UpdateDirector(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Director(result))
+		return
+	}
+
+	if result.Unchanged {
+		fmt.Printf("\nThe director '%s' in version '%s' already has these settings, nothing was changed\n\n", common.Yellow(name), common.Yellow(result.Version))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The director '%s' in version '%s' was updated successfully\n\n", common.Green(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// DirectorDelete deletes the director from the selected version
+func DirectorDelete(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.DirectorOptions{
+		Target: target(f, f.Sub.DirectorDelete),
+		Name:   name,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanDeleteDirector(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Director(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe director '%s' would be deleted from %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.DeleteDirector(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Director(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The director '%s' in version '%s' was deleted successfully\n\n", common.Red(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// directorSettings returns the settings of the director flags the user
+// provided so an update only changes those settings, -backends is a comma
+// separated list (an empty list removes every backend)
+func directorSettings(fs *flag.FlagSet, df flags.DirectorFlags) vcl.DirectorSettings {
+	var s vcl.DirectorSettings
+
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "backends":
+			backends := []string{}
+			for _, name := range strings.Split(*df.Backends, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					backends = append(backends, name)
+				}
+			}
+			s.Backends = &backends
+		case "quorum":
+			s.Quorum = df.Quorum
+		case "retries":
+			s.Retries = df.Retries
+		case "type":
+			s.Type = df.Type
+		}
+	})
+
+	return s
+}
+
+func printDirectors(result *vcl.DirectorsResult) {
+	fmt.Printf("Directors found for service version: %s\n\n", common.Yellow(result.Version))
+
+	if len(result.Directors) == 0 {
+		fmt.Println("There are no directors")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tQUORUM\tRETRIES\tBACKENDS")
+	for _, d := range result.Directors {
+		fmt.Fprintf(w, "%s\t%s\t%d%%\t%d\t%s\n", d.Name, d.Type, d.Quorum, d.Retries, strings.Join(d.Backends, ", "))
+	}
+	w.Flush()
+}
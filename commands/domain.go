@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/dns"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// DomainList displays the domains found in the remote service version
+// each of the services provided to -service is listed at the same time
+func DomainList(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.DomainListVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.ListDomains(ctx, client, vcl.ListOptions{
+			Service: service,
+			Version: selectedVersion,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printDomains(result) },
+			Doc:     output.Domains(result),
+		}
+	})
+}
+
+// DomainAdd adds the domain to the selected version
+func DomainAdd(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.DomainOptions{
+		Target: target(f, f.Sub.DomainAdd),
+		Name:   name,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanAddDomain(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Domain(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe domain '%s' would be added to %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		return
+	}
+
+	result, err := vcl.AddDomain(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Domain(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The domain '%s' was added to version '%s' successfully\n\n", common.Green(name), common.Yellow(result.Version))
+}
+
+// DomainRemove removes the domain from the selected version
+func DomainRemove(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.DomainOptions{
+		Target: target(f, f.Sub.DomainRemove),
+		Name:   name,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanRemoveDomain(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Domain(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe domain '%s' would be removed from %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		return
+	}
+
+	result, err := vcl.RemoveDomain(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Domain(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The domain '%s' was removed from version '%s' successfully\n\n", common.Red(name), common.Yellow(result.Version))
+}
+
+// DomainCheck reports whether each domain of the remote service version
+// CNAMEs to Fastly, according to both Fastly's check and the local resolver
+// (each of the services provided to -service is checked at the same time)
+func DomainCheck(ctx context.Context, f flags.Flags, client api.Client, requester api.Requester) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.DomainCheckVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	resolver := dns.System
+	if *f.Sub.DomainCheckResolver != "" {
+		resolver = dns.Server{Address: *f.Sub.DomainCheckResolver}
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.CheckDomains(ctx, client, requester, vcl.DomainCheckOptions{
+			Service:  service,
+			Version:  selectedVersion,
+			Resolver: resolver,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		code := common.ExitSuccess
+		if result.Failures() > 0 {
+			code = common.ExitFailure
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printDomainCheck(result) },
+			Doc:     output.DomainCheck(result),
+			Code:    code,
+		}
+	})
+}
+
+func printDomains(result *vcl.DomainsResult) {
+	fmt.Printf("Domains found for service version: %s\n\n", common.Yellow(result.Version))
+
+	if len(result.Domains) == 0 {
+		fmt.Println("There are no domains")
+		return
+	}
+
+	for _, d := range result.Domains {
+		if d.Comment == "" {
+			fmt.Println(d.Name)
+			continue
+		}
+		fmt.Printf("%s (%s)\n", d.Name, d.Comment)
+	}
+}
+
+func printDomainCheck(result *vcl.DomainCheckResult) {
+	fmt.Printf("Domains checked for service version: %s\n\n", common.Yellow(result.Version))
+
+	if len(result.Domains) == 0 {
+		fmt.Println("There are no domains")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tDNS\tCNAME\tFASTLY CHECK")
+	for _, d := range result.Domains {
+		cname := d.LocalCNAME
+		if d.Status == vcl.DomainUnresolved {
+			cname = d.Error
+		}
+
+		fastly := "invalid"
+		if d.FastlyValid {
+			fastly = "valid"
+		}
+		if d.FastlyCNAME != "" {
+			fastly += " (" + d.FastlyCNAME + ")"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Name, d.Status, cname, fastly)
+	}
+	w.Flush()
+
+	if failures := result.Failures(); failures > 0 {
+		fmt.Printf("\n%s of %d domain(s) don't CNAME correctly to Fastly\n", common.Red(failures), len(result.Domains))
+		return
+	}
+	fmt.Println("\nEvery domain CNAMEs correctly to Fastly")
+}
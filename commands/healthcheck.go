@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// HealthCheckList displays the healthchecks found in the remote service
+// version, each of the services provided to -service is listed at the same
+// time
+func HealthCheckList(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.HealthCheckListVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.ListHealthChecks(ctx, client, vcl.ListOptions{
+			Service: service,
+			Version: selectedVersion,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printHealthChecks(result) },
+			Doc:     output.HealthChecks(result),
+		}
+	})
+}
+
+// HealthCheckCreate creates the healthcheck in the selected version
+func HealthCheckCreate(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.HealthCheckOptions{
+		Target:   target(f, f.Sub.HealthCheckCreate),
+		Name:     name,
+		Settings: healthCheckSettings(f.Top.HealthCheckCreate, f.Sub.HealthCheckCreateSettings),
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanCreateHealthCheck(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.HealthCheck(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe healthcheck '%s' would be created in %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.CreateHealthCheck(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.HealthCheck(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The healthcheck '%s' in version '%s' was created successfully\n\n", common.Green(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// HealthCheckUpdate changes the settings of the healthcheck provided as flags
+// in the selected version, no version is cloned when none of the settings
+// differ
+func HealthCheckUpdate(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.HealthCheckOptions{
+		Target:   target(f, f.Sub.HealthCheckUpdate),
+		Name:     name,
+		Settings: healthCheckSettings(f.Top.HealthCheckUpdate, f.Sub.HealthCheckUpdateSettings),
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanUpdateHealthCheck(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.HealthCheck(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		if result.Unchanged {
+			fmt.Printf("\nThe healthcheck '%s' in version '%s' already has these settings, nothing would change\n\n", common.Yellow(name), common.Yellow(result.Version))
+			return
+		}
+
+		fmt.Printf("\nThe healthcheck '%s' would be updated in %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.UpdateHealthCheck(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.HealthCheck(result))
+		return
+	}
+
+	if result.Unchanged {
+		fmt.Printf("\nThe healthcheck '%s' in version '%s' already has these settings, nothing was changed\n\n", common.Yellow(name), common.Yellow(result.Version))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The healthcheck '%s' in version '%s' was updated successfully\n\n", common.Green(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// HealthCheckDelete deletes the healthcheck from the selected version, unless
+// a backend still references it
+func HealthCheckDelete(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	opts := vcl.HealthCheckOptions{
+		Target: target(f, f.Sub.HealthCheckDelete),
+		Name:   name,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.PlanDeleteHealthCheck(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.HealthCheck(result)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe healthcheck '%s' would be deleted from %s\n\n", common.Yellow(name), plannedTarget(result.TargetVersion))
+		printWarnings(result.Warnings)
+		return
+	}
+
+	result, err := vcl.DeleteHealthCheck(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.HealthCheck(result))
+		return
+	}
+
+	printCloned(result.TargetVersion)
+	fmt.Printf("The healthcheck '%s' in version '%s' was deleted successfully\n\n", common.Red(name), common.Yellow(result.Version))
+	printWarnings(result.Warnings)
+}
+
+// healthCheckSettings returns the settings of the healthcheck flags the user
+// provided so an update only changes those settings
+func healthCheckSettings(fs *flag.FlagSet, hf flags.HealthCheckFlags) vcl.HealthCheckSettings {
+	var s vcl.HealthCheckSettings
+
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "check-interval":
+			s.CheckInterval = hf.CheckInterval
+		case "expected-response":
+			s.ExpectedResponse = hf.ExpectedResponse
+		case "host":
+			s.Host = hf.Host
+		case "http-version":
+			s.HTTPVersion = hf.HTTPVersion
+		case "initial":
+			s.Initial = hf.Initial
+		case "method":
+			s.Method = hf.Method
+		case "path":
+			s.Path = hf.Path
+		case "threshold":
+			s.Threshold = hf.Threshold
+		case "timeout":
+			s.Timeout = hf.Timeout
+		case "window":
+			s.Window = hf.Window
+		}
+	})
+
+	return s
+}
+
+func printHealthChecks(result *vcl.HealthChecksResult) {
+	fmt.Printf("Healthchecks found for service version: %s\n\n", common.Yellow(result.Version))
+
+	if len(result.HealthChecks) == 0 {
+		fmt.Println("There are no healthchecks")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tREQUEST\tEXPECTED\tINTERVAL\tTHRESHOLD")
+	for _, hc := range result.HealthChecks {
+		fmt.Fprintf(w, "%s\t%s %s%s\t%d\t%dms\t%d/%d\n", hc.Name, hc.Method, hc.Host, hc.Path, hc.ExpectedResponse, hc.CheckInterval, hc.Threshold, hc.Window)
+	}
+	w.Flush()
+}
@@ -1,60 +1,53 @@
 package commands
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 
 	"github.com/integralist/go-fastly-cli/common"
 	"github.com/integralist/go-fastly-cli/flags"
-	"github.com/sethvargo/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
 )
 
 // List all VCL files found in the remote service version
-func List(f flags.Flags, client *fastly.Client) {
-	var serviceVersion string
-	var selectedVersion int
-
-	listVersion := *f.Sub.VclListVersion
-	fastlyServiceID = *f.Top.Service
-
-	if listVersion != "" {
-		serviceVersion = listVersion
-	} else {
-		var err error
-		selectedVersion, err = common.GetLatestVCLVersion(fastlyServiceID, client)
-		if err != nil {
-			fmt.Println("Sorry, we were unable to acquire the latest service version")
-			fmt.Println("Please try again, or provide a specific version by using the -version flag")
-			common.Failure()
-		}
+// each of the services provided to -service is listed at the same time
+func List(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.VclListVersion)
+	if err != nil {
+		output.Fail(err)
 	}
 
-	// If we're not the type default, then we have the latest service version
-	if selectedVersion != 0 {
-		fmt.Println("You didn't provide a specific service version, so we'll use the latest one")
-	} else {
-		// Otherwise the user provided a service version, which we need to convert
-		var err error
-		selectedVersion, err = strconv.Atoi(serviceVersion)
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.List(ctx, client, vcl.ListOptions{
+			Service: service,
+			Version: selectedVersion,
+		})
 		if err != nil {
-			fmt.Printf("Unable to convert provided version:\n\t%+v\n", err)
-			common.Failure()
+			return output.ErrorReport(service, err, "%s\n", err)
 		}
-	}
 
-	vclFiles, err := client.ListVCLs(&fastly.ListVCLsInput{
-		Service: fastlyServiceID,
-		Version: selectedVersion,
+		return output.Report{
+			Service: service,
+			Text:    func() { printList(result, selectedVersion) },
+			Doc:     output.List(result),
+		}
 	})
-	if err != nil {
-		fmt.Printf("Unable to retrieve list of VCL files for version: %s", common.Yellow(selectedVersion))
-		common.Failure()
-	}
+}
 
-	fmt.Printf("VCL files found for service version: %s\n\n", common.Yellow(selectedVersion))
-	for _, f := range vclFiles {
-		fmt.Printf("  * %v\n", f.Name)
+func printList(result *vcl.ListResult, selectedVersion int) {
+	// If the user didn't provide a version, then we used the latest one
+	if selectedVersion == 0 {
+		fmt.Println("You didn't provide a specific service version, so we'll use the latest one")
 	}
 
-	common.Success()
+	fmt.Printf("VCL files found for service version: %s\n\n", common.Yellow(result.Version))
+	for _, file := range result.Files {
+		if file.Main {
+			fmt.Printf("  * %v %s\n", file.Name, common.Green("(main)"))
+			continue
+		}
+		fmt.Printf("  * %v\n", file.Name)
+	}
 }
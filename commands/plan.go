@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// printPlan displays the changes a command will make to the remote version
+// for a dry run each file's diff is also included
+func printPlan(plan *vcl.Plan, dryRun bool) {
+	if output.Structured() {
+		output.Write(output.Plan(plan))
+		return
+	}
+
+	will := "will"
+	if dryRun {
+		will = "would"
+	}
+
+	target := fmt.Sprintf("version '%s'", common.Yellow(plan.Version))
+	if plan.Clone {
+		target = fmt.Sprintf("a new clone of version '%s'", common.Yellow(plan.Version))
+	}
+
+	if plan.Empty() {
+		fmt.Printf("\nNo changes need to be made to %s of service '%s'\n", target, common.Yellow(plan.Service))
+		return
+	}
+
+	fmt.Printf("\nThe following changes %s be made to %s of service '%s':\n\n", will, target, common.Yellow(plan.Service))
+
+	if plan.Comment != "" {
+		fmt.Printf("  %s %q\n\n", common.Yellow("comment"), plan.Comment)
+	}
+
+	for _, file := range plan.Create {
+		fmt.Printf("  %s %s (%s)\n", common.Green("+ create"), file.Name, file.Path)
+	}
+	for _, file := range plan.Update {
+		fmt.Printf("  %s %s (%s)\n", common.Yellow("~ update"), file.Name, file.Path)
+	}
+	for _, file := range plan.Delete {
+		fmt.Printf("  %s %s\n", common.Red("- delete"), file.Name)
+	}
+	if plan.Main != "" {
+		fmt.Printf("  %s %s\n", common.Yellow("* main  "), plan.Main)
+	}
+
+	fmt.Printf("\n%d to create, %d to update, %d to delete, %d unchanged\n", len(plan.Create), len(plan.Update), len(plan.Delete), len(plan.Unchanged))
+
+	if !dryRun {
+		return
+	}
+
+	for _, files := range [][]vcl.PlannedFile{plan.Create, plan.Update, plan.Delete} {
+		for _, file := range files {
+			local := file.Path
+			if local == "" {
+				local = "/dev/null"
+			}
+			fmt.Printf("\n%s", file.Diff.Unified(fmt.Sprintf("%s (version %d)", file.Name, plan.Version), local))
+		}
+	}
+}
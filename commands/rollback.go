@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+	"github.com/integralist/go-fastly-cli/standalone"
+)
+
+// Rollback re-activates the previously active service version (or the version
+// specified with -to) after displaying the VCL differences between them, the
+// activation is confirmed in the same way as `version activate`
+func Rollback(ctx context.Context, f flags.Flags, client api.Client, guard standalone.ActivateOptions) {
+	to, err := common.ParseVersion(*f.Sub.RollbackTo)
+	if err != nil {
+		fmt.Println(err)
+		common.Failure()
+	}
+
+	plan, err := vcl.PlanRollback(ctx, client, vcl.RollbackOptions{
+		Service: *f.Top.Service,
+		To:      to,
+	})
+	if err == vcl.ErrNoPreviousVersion {
+		fmt.Printf("\nUnable to roll back service '%s':\n\n%s\n\nProvide the version to roll back to with -to\n\n", common.Yellow(*f.Top.Service), common.Red(err))
+		common.Failure()
+	}
+	if err != nil {
+		fmt.Printf("\nUnable to roll back service '%s':\n\n%s\n\n", common.Yellow(*f.Top.Service), common.Red(err))
+		common.Failure()
+	}
+
+	printVersionDiffs(plan.Files, plan.Active, plan.Target)
+
+	fmt.Printf("\nService '%s' will roll back from version '%s' to version '%s'\n", common.Yellow(plan.Service), common.Red(plan.Active), common.Green(plan.Target))
+
+	if *f.Top.DryRun {
+		common.Success()
+	}
+
+	result, err := vcl.Rollback(ctx, client, plan, guard.Confirm)
+	if err == standalone.ErrNotConfirmed {
+		fmt.Println("\nThe version was not activated")
+		common.Success()
+	}
+	if err != nil {
+		fmt.Printf("\nThere was a problem activating version %s\n\n%s\n\n", common.Yellow(plan.Target), common.Red(err))
+		common.Failure()
+	}
+
+	fmt.Printf("\nService '%s' now has version '%s' activated\n\n", common.Yellow(result.Service), common.Green(result.Version))
+}
+
+// printVersionDiffs displays the VCL differences between two remote versions
+func printVersionDiffs(diffs []vcl.VersionDiff, from, to int) {
+	if len(diffs) == 0 {
+		fmt.Printf("\nThere are no VCL differences between version '%s' and version '%s'\n", common.Yellow(from), common.Yellow(to))
+		return
+	}
+
+	for _, vd := range diffs {
+		fmt.Printf("\n%s lines added, %s lines removed\n\n", common.Green(vd.Result.Added), common.Red(vd.Result.Removed))
+		fmt.Print(vd.Result.Unified(fmt.Sprintf("%s (version %d)", vd.Name, from), fmt.Sprintf("%s (version %d)", vd.Name, to)))
+	}
+}
@@ -0,0 +1,373 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/diff"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+
+	"github.com/fatih/color"
+)
+
+// errDynamicConfirmation is reported when a dynamic upload can't be confirmed
+var errDynamicConfirmation = errors.New("dynamic snippets are live as soon as they're uploaded, please provide -yes to upload them with -output")
+
+// SnippetList displays the snippets found in the remote service version
+// each of the services provided to -service is listed at the same time
+func SnippetList(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.SnippetListVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.ListSnippets(ctx, client, vcl.ListOptions{
+			Service: service,
+			Version: selectedVersion,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printSnippets(result) },
+			Doc:     output.Snippets(result),
+		}
+	})
+}
+
+// SnippetGet displays the content of the snippet, the content of a dynamic
+// snippet is its current (live) content
+func SnippetGet(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.SnippetGetVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	result, err := vcl.GetSnippet(ctx, client, vcl.SnippetOptions{
+		Service: *f.Top.Service,
+		Version: selectedVersion,
+		Name:    name,
+	})
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Snippet(result))
+		return
+	}
+
+	// only the content is printed so it can be redirected to a file
+	fmt.Print(result.Content)
+}
+
+// SnippetDelete removes the snippet from the remote service version
+func SnippetDelete(ctx context.Context, f flags.Flags, client api.Client, name string) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.SnippetDelVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	opts := vcl.SnippetOptions{
+		Service: *f.Top.Service,
+		Version: selectedVersion,
+		Name:    name,
+	}
+
+	if *f.Top.DryRun {
+		result, err := vcl.GetSnippet(ctx, client, opts)
+		if err != nil {
+			output.Failf(err, "\n%s\n\n", common.Red(err))
+		}
+
+		if output.Structured() {
+			doc := output.Snippet(result)
+			doc.Content = ""
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nThe snippet '%s' would be deleted from version '%s'\n\n", common.Yellow(result.Name), common.Yellow(result.Version))
+		return
+	}
+
+	result, err := vcl.DeleteSnippet(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		doc := output.Snippet(result)
+		doc.Content = ""
+		output.Write(doc)
+		return
+	}
+
+	fmt.Printf("\nThe snippet '%s' in version '%s' was deleted successfully\n\n", common.Red(result.Name), common.Yellow(result.Version))
+}
+
+// SnippetDiff compares the local snippets against the remote service version
+// each of the services provided to -service is compared at the same time
+func SnippetDiff(ctx context.Context, f flags.Flags, client api.Client) {
+	selectedVersion, err := common.ParseVersion(*f.Sub.SnippetDiffVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	if *f.Sub.SnippetContext < 0 {
+		output.Fail(diff.ErrNegativeContext)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.DiffSnippets(ctx, client, vcl.SnippetDiffOptions{
+			Service:   service,
+			Directory: *f.Top.Directory,
+			Version:   selectedVersion,
+			Context:   *f.Sub.SnippetContext,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		code := common.ExitSuccess
+		if result.Differences() > 0 {
+			code = common.ExitDifferences
+		}
+
+		return output.Report{
+			Service: service,
+			Text: func() {
+				if len(result.Snippets) == 0 {
+					fmt.Printf("No snippets were found locally or in version %d\n", result.Version)
+				}
+				for _, sd := range result.Snippets {
+					printSnippetDiff(sd, result.Version)
+				}
+			},
+			Doc:  output.SnippetDiff(result),
+			Code: code,
+		}
+	})
+}
+
+// SnippetUpload creates or updates the remote snippets to match the local
+// snippets, with -dynamic the content of the dynamic snippets is updated in
+// place (after confirming the changes, as they're live immediately)
+func SnippetUpload(ctx context.Context, f flags.Flags, client api.Client) {
+	if *f.Sub.SnippetClone != "" && *f.Sub.SnippetVersion != "" {
+		output.Fail(vcl.ErrConflictingVersions)
+	}
+
+	cloneVersion, err := common.ParseVersion(*f.Sub.SnippetClone)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	uploadVersion, err := common.ParseVersion(*f.Sub.SnippetVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	dynamic := *f.Sub.SnippetDynamic
+	if dynamic && output.Structured() && !*f.Top.DryRun && !*f.Sub.SnippetConfirm {
+		output.Fail(errDynamicConfirmation)
+	}
+
+	plan, err := vcl.PlanSnippets(ctx, client, vcl.SnippetUploadOptions{
+		Service:   *f.Top.Service,
+		Directory: *f.Top.Directory,
+		Clone:     cloneVersion,
+		Version:   uploadVersion,
+		Latest:    *f.Sub.SnippetLatest,
+		Comment:   versionComment(f, *f.Sub.SnippetComment),
+		Dynamic:   dynamic,
+	})
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if *f.Top.DryRun || !output.Structured() {
+		printSnippetPlan(plan, *f.Top.DryRun)
+	}
+
+	if *f.Top.DryRun {
+		common.Success()
+	}
+
+	if plan.Empty() {
+		if output.Structured() {
+			output.Write(output.SnippetUpload(&vcl.SnippetUploadResult{Service: plan.Service, Version: plan.Version, Dynamic: dynamic}))
+		}
+		common.Success()
+	}
+
+	if dynamic && !*f.Sub.SnippetConfirm && !common.Confirm("\nUpload these dynamic snippets (they will be live immediately)?") {
+		fmt.Println("\nNo changes were applied")
+		common.Success()
+	}
+
+	result, err := vcl.ApplySnippets(ctx, client, plan)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.SnippetUpload(result))
+		if result.Err() != nil {
+			common.PartialFailure()
+		}
+		common.Success()
+	}
+
+	fmt.Println()
+
+	if result.ClonedFrom != 0 {
+		fmt.Printf("Successfully created new version %d from existing version %d\n\n", result.Version, result.ClonedFrom)
+		if result.Comment != "" {
+			fmt.Printf("With the comment: %q\n\n", result.Comment)
+		}
+	}
+
+	for _, fr := range result.Snippets {
+		handleSnippetResponse(fr, result)
+	}
+
+	exitOnPartialFailure(result.Err())
+}
+
+func printSnippets(result *vcl.SnippetsResult) {
+	fmt.Printf("Snippets found for service version: %s\n\n", common.Yellow(result.Version))
+
+	if len(result.Snippets) == 0 {
+		fmt.Println("There are no snippets")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tPRIORITY\tDYNAMIC")
+	for _, s := range result.Snippets {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", s.Name, s.Type, s.Priority, yesNo(s.Dynamic))
+	}
+	w.Flush()
+}
+
+func printSnippetDiff(sd vcl.SnippetDiff, selectedVersion int) {
+	switch {
+	case sd.Equal():
+		color.Green("\nNo difference between the version (%d) of the snippet '%s' and the version found locally\n\t%s\n", selectedVersion, sd.Name, sd.Local.Path)
+		return
+	case sd.Remote == nil:
+		color.Red("\nThe snippet '%s' doesn't exist in version (%d)\n\t%s\n", sd.Name, selectedVersion, sd.Local.Path)
+	case sd.Local == nil:
+		color.Red("\nThe snippet '%s' in version (%d) doesn't exist locally\n", sd.Name, selectedVersion)
+	default:
+		color.Red("\nThere was a difference between the version (%d) of the snippet '%s' and the version found locally\n\t%s\n", selectedVersion, sd.Name, sd.Local.Path)
+	}
+
+	if len(sd.Changes) > 0 {
+		fmt.Println()
+		for _, change := range sd.Changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+
+	if sd.Result.Equal() {
+		return
+	}
+
+	fmt.Printf("\n%s lines added, %s lines removed\n\n", common.Green(sd.Result.Added), common.Red(sd.Result.Removed))
+	fmt.Print(sd.Unified(selectedVersion))
+}
+
+// printSnippetPlan displays the changes a snippet upload will make
+// for a dry run each snippet's diff is also included
+func printSnippetPlan(plan *vcl.SnippetPlan, dryRun bool) {
+	if output.Structured() {
+		output.Write(output.SnippetPlan(plan))
+		return
+	}
+
+	will := "will"
+	if dryRun {
+		will = "would"
+	}
+
+	target := fmt.Sprintf("version '%s'", common.Yellow(plan.Version))
+	switch {
+	case plan.Dynamic:
+		target = fmt.Sprintf("the dynamic snippets (found in version '%s')", common.Yellow(plan.Version))
+	case plan.Clone:
+		target = fmt.Sprintf("a new clone of version '%s'", common.Yellow(plan.Version))
+	}
+
+	for _, planned := range plan.Skipped {
+		fmt.Printf("\nThe snippet '%s' was skipped: %s\n", common.Yellow(planned.Name), planned.Reason)
+	}
+
+	if plan.Empty() {
+		fmt.Printf("\nNo changes need to be made to %s of service '%s'\n", target, common.Yellow(plan.Service))
+		return
+	}
+
+	fmt.Printf("\nThe following changes %s be made to %s of service '%s':\n\n", will, target, common.Yellow(plan.Service))
+
+	if plan.Comment != "" {
+		fmt.Printf("  %s %q\n\n", common.Yellow("comment"), plan.Comment)
+	}
+
+	for _, planned := range plan.Create {
+		fmt.Printf("  %s %s (%s, priority %d, dynamic: %s)\n", common.Green("+ create"), planned.Name, planned.Type, planned.Priority, yesNo(planned.Dynamic))
+	}
+	for _, planned := range plan.Update {
+		fmt.Printf("  %s %s (%s)\n", common.Yellow("~ update"), planned.Name, planned.Path)
+		for _, change := range planned.Changes {
+			fmt.Printf("             %s\n", change)
+		}
+	}
+
+	fmt.Printf("\n%d to create, %d to update, %d unchanged, %d skipped\n", len(plan.Create), len(plan.Update), len(plan.Unchanged), len(plan.Skipped))
+
+	if !dryRun {
+		return
+	}
+
+	for _, snippets := range [][]vcl.PlannedSnippet{plan.Create, plan.Update} {
+		for _, planned := range snippets {
+			if planned.Diff.Equal() {
+				continue
+			}
+			fmt.Printf("\n%s", planned.Diff.Unified(fmt.Sprintf("%s (version %d)", planned.Name, plan.Version), planned.Path))
+		}
+	}
+}
+
+func handleSnippetResponse(fr vcl.FileResult, result *vcl.SnippetUploadResult) {
+	kind := "snippet"
+	if result.Dynamic {
+		kind = "dynamic snippet"
+	}
+
+	switch {
+	case fr.Err != nil && result.Dynamic:
+		fmt.Printf("The %s '%s' didn't upload because of the following error:\n\t%s\n\n", kind, common.Yellow(fr.Name), common.Red(fr.Err))
+	case fr.Err != nil:
+		fmt.Printf("The %s '%s' didn't upload to version '%d' because of the following error:\n\t%s\n\n", kind, common.Yellow(fr.Name), result.Version, common.Red(fr.Err))
+	case result.Dynamic:
+		fmt.Printf("The %s '%s' was updated successfully (it's now live)\n", kind, common.Green(fr.Name))
+	case fr.Created:
+		fmt.Printf("The %s '%s' in version '%s' was created successfully\n", kind, common.Green(fr.Name), common.Yellow(result.Version))
+	default:
+		fmt.Printf("The %s '%s' in version '%s' was updated successfully\n", kind, common.Green(fr.Name), common.Yellow(result.Version))
+	}
+}
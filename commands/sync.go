@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// errSyncConfirmation is reported when the sync can't be confirmed
+var errSyncConfirmation = errors.New("synced files are changed as soon as they're applied, please provide -yes to sync them with -output")
+
+// Sync makes the remote service version exactly mirror the local VCL files
+// creating, updating and deleting remote files as necessary
+func Sync(ctx context.Context, f flags.Flags, client api.Client) {
+	if *f.Sub.SyncCloneVersion != "" && *f.Sub.SyncVersion != "" {
+		output.Failf(vcl.ErrConflictingVersions, "Please do not provide both -clone and -version flags\n")
+	}
+
+	if output.Structured() && !*f.Top.DryRun && !*f.Sub.SyncConfirm {
+		output.Fail(errSyncConfirmation)
+	}
+
+	cloneVersion, err := common.ParseVersion(*f.Sub.SyncCloneVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	syncVersion, err := common.ParseVersion(*f.Sub.SyncVersion)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	match, skip := skipMatch(f)
+	comment := versionComment(f, *f.Sub.SyncComment)
+
+	plan, err := vcl.PlanSync(ctx, client, vcl.SyncOptions{
+		UploadOptions: vcl.UploadOptions{
+			Service:   *f.Top.Service,
+			Directory: *f.Top.Directory,
+			Match:     match,
+			Skip:      skip,
+			Clone:     cloneVersion,
+			Version:   syncVersion,
+			Latest:    *f.Sub.SyncLatest,
+			Main:      *f.Sub.SyncMainVCL,
+			Comment:   comment,
+
+			Concurrency: *f.Top.Concurrency,
+		},
+		DeleteMatch: *f.Sub.SyncDeleteMatch,
+	})
+	if err != nil {
+		output.Fail(err)
+	}
+
+	// the plan is the only document of a dry run (or a sync with nothing to
+	// change), otherwise the document is the outcome of applying it
+	if plan.Empty() || *f.Top.DryRun {
+		printPlan(plan, *f.Top.DryRun)
+		common.Success()
+	}
+
+	if !output.Structured() {
+		printPlan(plan, false)
+
+		if !*f.Sub.SyncConfirm && !common.Confirm("\nApply these changes?") {
+			fmt.Println("\nNo changes were applied")
+			common.Success()
+		}
+	}
+
+	result, err := vcl.ApplySync(ctx, client, plan)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Write(output.Sync(result))
+		exitOnPartialFailure(result.Err())
+		return
+	}
+
+	fmt.Println()
+
+	if result.ClonedFrom != 0 {
+		fmt.Printf("Successfully created new version %d from existing version %d\n\n", result.Version, result.ClonedFrom)
+		if result.Comment != "" {
+			fmt.Printf("With the comment: %q\n\n", result.Comment)
+		}
+	}
+
+	for _, fr := range result.Files {
+		if fr.Deleted {
+			handleDeleteResponse(fr, result.Version)
+			continue
+		}
+		handleResponse(fr, result.Version)
+	}
+
+	handleMainResponse(result.Main, result.MainErr, result.Version)
+	exitOnPartialFailure(result.Err())
+}
+
+func handleDeleteResponse(fr vcl.FileResult, selectedVersion int) {
+	if fr.Err != nil {
+		fmt.Printf("The file '%s' wasn't deleted from version '%d' because of the following error:\n\t%s\n\n", common.Yellow(fr.Name), selectedVersion, common.Red(fr.Err))
+		return
+	}
+	fmt.Printf("The file '%s' in version '%s' was deleted successfully\n", common.Red(fr.Name), common.Yellow(selectedVersion))
+}
+
+// exitOnPartialFailure summarises the files that failed and then exits with
+// the partial failure exit code (it does nothing when err is nil)
+func exitOnPartialFailure(err error) {
+	if err == nil {
+		return
+	}
+
+	if !output.Structured() {
+		fmt.Printf("\n%s\n", common.Red(err))
+	}
+	common.PartialFailure()
+}
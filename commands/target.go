@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// target returns the version selected by the -clone, -version and -latest
+// flags of a command that changes the service's configuration
+func target(f flags.Flags, tf flags.TargetFlags) vcl.Target {
+	if *tf.Clone != "" && *tf.Version != "" {
+		output.Fail(vcl.ErrConflictingVersions)
+	}
+
+	cloneVersion, err := common.ParseVersion(*tf.Clone)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	selectedVersion, err := common.ParseVersion(*tf.Version)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	return vcl.Target{
+		Service: *f.Top.Service,
+		Clone:   cloneVersion,
+		Version: selectedVersion,
+		Latest:  *tf.Latest,
+		Comment: versionComment(f, *tf.Comment),
+	}
+}
+
+// plannedTarget describes the version a change would be made to
+func plannedTarget(tv vcl.TargetVersion) string {
+	if tv.Clone {
+		return fmt.Sprintf("a new clone of version '%s'", common.Yellow(tv.Version))
+	}
+	return fmt.Sprintf("version '%s'", common.Yellow(tv.Version))
+}
+
+// printCloned displays the version that was cloned to make a change (if any)
+func printCloned(tv vcl.TargetVersion) {
+	fmt.Println()
+
+	if tv.ClonedFrom == 0 {
+		return
+	}
+
+	fmt.Printf("Successfully created new version %d from existing version %d\n\n", tv.Version, tv.ClonedFrom)
+	if tv.Comment != "" {
+		fmt.Printf("With the comment: %q\n\n", tv.Comment)
+	}
+}
@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// VersionList displays every version of the service sorted by number
+// each of the services provided to -service is listed at the same time
+func VersionList(ctx context.Context, f flags.Flags, client api.Client) {
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.Versions(ctx, client, service)
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printVersions(result) },
+			Doc:     output.Versions(result),
+		}
+	})
+}
+
+// VersionShow displays the details and VCL files of the service version
+func VersionShow(ctx context.Context, f flags.Flags, client api.Client, version string) {
+	v, err := common.ParseVersion(version)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	output.Services(services(f), func(service string) output.Report {
+		result, err := vcl.Describe(ctx, client, vcl.VersionOptions{
+			Service: service,
+			Version: v,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "%s\n", err)
+		}
+
+		return output.Report{
+			Service: service,
+			Text:    func() { printDescribe(result) },
+			Doc:     output.Describe(result),
+		}
+	})
+}
+
+// VersionClone creates a new version from the service version
+func VersionClone(ctx context.Context, f flags.Flags, client api.Client, version string) {
+	v, err := common.ParseVersion(version)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	opts := vcl.VersionOptions{
+		Service: *f.Top.Service,
+		Version: v,
+	}
+
+	if *f.Top.DryRun {
+		source := describeVersion(ctx, client, opts)
+
+		if output.Structured() {
+			output.Write(output.CloneDocument{Service: source.Service, From: source.Number, DryRun: true})
+			return
+		}
+
+		fmt.Printf("\nVersion '%s' would be cloned\n\n", common.Yellow(source.Number))
+		return
+	}
+
+	result, err := vcl.Clone(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Clone(result))
+		return
+	}
+
+	fmt.Printf("\nVersion '%s' was cloned to version '%s'\n\n", common.Yellow(result.From), common.Green(result.Version))
+}
+
+// VersionLock locks the service version so it can no longer be modified
+func VersionLock(ctx context.Context, f flags.Flags, client api.Client, version string) {
+	v, err := common.ParseVersion(version)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	opts := vcl.VersionOptions{
+		Service: *f.Top.Service,
+		Version: v,
+	}
+
+	if *f.Top.DryRun {
+		target := describeVersion(ctx, client, opts)
+
+		if output.Structured() {
+			doc := output.Version(&target.VersionResult)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nVersion '%s' would be locked\n\n", common.Yellow(target.Number))
+		return
+	}
+
+	result, err := vcl.Lock(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Version(result))
+		return
+	}
+
+	fmt.Printf("\nVersion '%s' is now locked\n\n", common.Green(result.Number))
+}
+
+// VersionComment replaces the comment of the service version
+func VersionComment(ctx context.Context, f flags.Flags, client api.Client, version, comment string) {
+	v, err := common.ParseVersion(version)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	if comment == "" {
+		output.Failf(vcl.ErrMissingComment, "Please provide a comment\n  e.g. fastly version comment 123 \"Add the new backend\"\n")
+	}
+
+	if *f.Top.DryRun {
+		target := describeVersion(ctx, client, vcl.VersionOptions{
+			Service: *f.Top.Service,
+			Version: v,
+		})
+		target.Comment = comment
+
+		if output.Structured() {
+			doc := output.Version(&target.VersionResult)
+			doc.DryRun = true
+			output.Write(doc)
+			return
+		}
+
+		fmt.Printf("\nVersion '%s' would have its comment set to %q\n\n", common.Yellow(target.Number), comment)
+		return
+	}
+
+	result, err := vcl.Comment(ctx, client, vcl.CommentOptions{
+		Service: *f.Top.Service,
+		Version: v,
+		Comment: comment,
+	})
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Version(result))
+		return
+	}
+
+	fmt.Printf("\nVersion '%s' now has the comment %q\n\n", common.Green(result.Number), result.Comment)
+}
+
+// describeVersion is used by a dry run to report the version that would be
+// changed (resolving "latest" to a number)
+func describeVersion(ctx context.Context, client api.Client, opts vcl.VersionOptions) *vcl.DescribeResult {
+	result, err := vcl.Describe(ctx, client, opts)
+	if err != nil {
+		output.Failf(err, "\n%s\n\n", common.Red(err))
+	}
+	return result
+}
+
+func printVersions(result *vcl.VersionsResult) {
+	if len(result.Versions) == 0 {
+		fmt.Println("No versions were found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tACTIVE\tLOCKED\tDEPLOYED\tUPDATED\tCOMMENT")
+	for _, v := range result.Versions {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", v.Number, yesNo(v.Active), yesNo(v.Locked), yesNo(v.Deployed), v.UpdatedAt, v.Comment)
+	}
+	w.Flush()
+}
+
+func printDescribe(result *vcl.DescribeResult) {
+	comment := "none"
+	if result.Comment != "" {
+		comment = fmt.Sprintf("%q", result.Comment)
+	}
+
+	fmt.Printf("Version: %s\n\n", common.Yellow(result.Number))
+	fmt.Printf("  active:    %s\n", yesNo(result.Active))
+	fmt.Printf("  locked:    %s\n", yesNo(result.Locked))
+	fmt.Printf("  deployed:  %s\n", yesNo(result.Deployed))
+	fmt.Printf("  staging:   %s\n", yesNo(result.Staging))
+	fmt.Printf("  testing:   %s\n", yesNo(result.Testing))
+	fmt.Printf("  comment:   %s\n", comment)
+	fmt.Printf("  created:   %s\n", result.CreatedAt)
+	fmt.Printf("  updated:   %s\n", result.UpdatedAt)
+
+	if len(result.Files) == 0 {
+		fmt.Println("\nThere are no VCL files")
+		return
+	}
+
+	fmt.Printf("\nVCL files:\n\n")
+	for _, file := range result.Files {
+		if file.Main {
+			fmt.Printf("  * %v %s\n", file.Name, common.Green("(main)"))
+			continue
+		}
+		fmt.Printf("  * %v\n", file.Name)
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
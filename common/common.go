@@ -3,12 +3,13 @@
 package common
 
 import (
+	"bufio"
 	"fmt"
 	"os"
-	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/fatih/color"
-	"github.com/sethvargo/go-fastly/fastly"
 )
 
 // useful colour settings for printing messages...
@@ -22,47 +23,77 @@ var Red = color.New(color.FgRed).SprintFunc()
 // Green colours stdout to be green
 var Green = color.New(color.FgGreen).SprintFunc()
 
-// fastly API doesn't return sorted data
-// so we have to manually sort the data ourselves
-type version struct {
-	Number  int
-	Version *fastly.Version
-}
-type wrappedVersions []version
-
-// satisfy the Sort interface
-func (v wrappedVersions) Len() int      { return len(v) }
-func (v wrappedVersions) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
-func (v wrappedVersions) Less(i, j int) bool {
-	return v[i].Number < v[j].Number
-}
+// exit codes returned by the commands (so CI can gate on them)
+const (
+	ExitSuccess        = 0
+	ExitFailure        = 1
+	ExitDifferences    = 2
+	ExitPartialFailure = 3
+)
 
 // Success stops processing and returns a zero exit code
 func Success() {
-	os.Exit(0)
+	os.Exit(ExitSuccess)
 }
 
 // Failure stops processing and returns an error exit code
 func Failure() {
-	os.Exit(1)
+	os.Exit(ExitFailure)
 }
 
-// GetLatestVCLVersion returns latest fastly service version
-// This service version isn't necessarily the currently active version
-func GetLatestVCLVersion(serviceID string, client *fastly.Client) (int, error) {
-	// we have to get all the versions and then sort them to find the actual latest
-	listVersions, err := client.ListVersions(&fastly.ListVersionsInput{
-		Service: serviceID,
-	})
-	if err != nil {
-		return 0, fmt.Errorf("There was a problem getting the version list:\n\n%s", Red(err))
+// Differences stops processing and returns the exit code indicating that
+// local files differ from the remote service version
+func Differences() {
+	os.Exit(ExitDifferences)
+}
+
+// PartialFailure stops processing and returns the exit code indicating that
+// some (but not necessarily all) files failed to be processed
+func PartialFailure() {
+	os.Exit(ExitPartialFailure)
+}
+
+// Confirm asks the user the given question and reports whether they answered
+// yes, any other answer (or no answer at all) is treated as a no
+func Confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
 	}
 
-	wv := wrappedVersions{}
-	for _, v := range listVersions {
-		wv = append(wv, version{v.Number, v})
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// ConfirmTyped asks the user to type the expected value, which is safer than
+// a y/N question for changes that are difficult to undo
+func ConfirmTyped(question, expected string) bool {
+	fmt.Printf("%s: ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	return strings.TrimSpace(answer) == expected
+}
+
+// ParseVersion converts a user provided service version into a number
+// an empty value or "latest" are returned as zero (i.e. use the latest version)
+func ParseVersion(version string) (int, error) {
+	if version == "" || version == "latest" {
+		return 0, nil
+	}
+
+	v, err := strconv.Atoi(version)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to convert provided version:\n\t%+v", err)
 	}
-	sort.Sort(wv)
 
-	return wv[len(wv)-1].Number, nil
+	return v, nil
 }
@@ -0,0 +1,128 @@
+// Config is a package that discovers and loads the optional project
+// configuration file (.fastly-cli.toml) from the current directory or any of
+// its parent directories.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sirupsen/logrus"
+)
+
+// FileName is the name of the project configuration file
+const FileName = ".fastly-cli.toml"
+
+var logger *logrus.Entry
+
+func init() {
+	logger = logrus.WithFields(logrus.Fields{
+		"package": "config",
+	})
+}
+
+// File is the content of the project configuration file
+type File struct {
+	// Path is where the file was loaded from (empty when no file was found)
+	Path string `toml:"-"`
+
+	// Groups maps a name to a list of service ids, so a group can be given
+	// to -service in place of the ids themselves
+	Groups map[string][]string `toml:"groups"`
+
+	// Environments are named sets of settings selected with -env
+	Environments map[string]Environment `toml:"env"`
+
+	// Protected lists the service ids (or group names) that can only be
+	// activated when -force is provided
+	Protected []string `toml:"protected"`
+}
+
+// Load discovers the configuration file by walking up from the current
+// directory, an empty File is returned when there isn't one
+func Load() (*File, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	path := find(dir)
+	if path == "" {
+		logger.Debug("no configuration file found")
+		return &File{}, nil
+	}
+
+	return LoadFile(path)
+}
+
+// LoadFile loads the configuration file at the given path
+func LoadFile(path string) (*File, error) {
+	cfg := &File{Path: path}
+
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		return nil, fmt.Errorf("Unable to parse configuration file '%s':\n\t%s", path, err)
+	}
+
+	logger.WithField("path", path).Debug("loaded configuration file")
+
+	return cfg, nil
+}
+
+// find returns the path of the nearest configuration file
+func find(dir string) string {
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Services expands the value given to -service into a list of service ids
+// the value is either the name of a group or a comma separated list of ids
+func (c *File) Services(value string) []string {
+	if group, ok := c.Groups[value]; ok {
+		return group
+	}
+	return SplitServices(value)
+}
+
+// IsProtected reports whether the service was listed as protected, either
+// directly or as a member of a protected group
+func (c *File) IsProtected(service string) bool {
+	for _, value := range c.Protected {
+		for _, id := range c.Services(value) {
+			if id == service {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SplitServices splits a comma separated list of service ids
+// an empty value is returned as a single (empty) id so the missing service
+// is reported by the Fastly API as before
+func SplitServices(value string) []string {
+	var services []string
+	for _, service := range strings.Split(value, ",") {
+		if service = strings.TrimSpace(service); service != "" {
+			services = append(services, service)
+		}
+	}
+
+	if len(services) == 0 {
+		return []string{""}
+	}
+	return services
+}
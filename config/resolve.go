@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Source describes where a resolved setting came from
+type Source string
+
+// the possible sources of a setting (in order of precedence)
+const (
+	SourceFlag    Source = "flag"
+	SourceEnvVar  Source = "environment variable"
+	SourceConfig  Source = "config file"
+	SourceDefault Source = "default"
+
+	// SourceCredentials is used for a token retrieved from a stored profile
+	SourceCredentials Source = "credentials profile"
+)
+
+// Environment is a named set of settings within the configuration file
+// e.g. [env.stage]
+type Environment struct {
+	Service     string `toml:"service"`
+	Directory   string `toml:"dir"`
+	Match       string `toml:"match"`
+	Skip        string `toml:"skip"`
+	Main        string `toml:"main"`
+	Concurrency int    `toml:"concurrency"`
+	Profile     string `toml:"profile"`
+}
+
+// Setting is the resolved value of a single setting
+// Detail names the flag, environment variable or config file section used
+type Setting struct {
+	Name   string
+	Value  string
+	Source Source
+	Detail string
+}
+
+// Resolved contains every setting that can be configured
+type Resolved struct {
+	// Env is the selected environment (empty when none was selected)
+	Env  string
+	File string
+
+	Settings []Setting
+}
+
+// Get returns the named setting
+func (r *Resolved) Get(name string) Setting {
+	for _, setting := range r.Settings {
+		if setting.Name == name {
+			return setting
+		}
+	}
+	return Setting{Name: name}
+}
+
+// Set replaces the named setting (e.g. once a token has been retrieved)
+func (r *Resolved) Set(setting Setting) {
+	for i := range r.Settings {
+		if r.Settings[i].Name == setting.Name {
+			r.Settings[i] = setting
+			return
+		}
+	}
+	r.Settings = append(r.Settings, setting)
+}
+
+// setting describes how a single setting is resolved, name is the flag name
+// relative paths in the config file are relative to the file when path is true
+type setting struct {
+	name   string
+	envVar string
+	config func(Environment) string
+	path   bool
+}
+
+// settings that can be configured (in the order they're displayed)
+var settings = []setting{
+	{"service", "FASTLY_SERVICE_ID", func(e Environment) string { return e.Service }, false},
+	{"dir", "VCL_DIRECTORY", func(e Environment) string { return e.Directory }, true},
+	{"match", "VCL_MATCH_PATH", func(e Environment) string { return e.Match }, false},
+	{"skip", "VCL_SKIP_PATH", func(e Environment) string { return e.Skip }, false},
+	{"main", "", func(e Environment) string { return e.Main }, false},
+	{"concurrency", "", func(e Environment) string {
+		if e.Concurrency == 0 {
+			return ""
+		}
+		return strconv.Itoa(e.Concurrency)
+	}, false},
+	{"profile", "FASTLY_PROFILE", func(e Environment) string { return e.Profile }, false},
+	{"token", "FASTLY_API_TOKEN", func(e Environment) string { return "" }, false},
+}
+
+// Resolve works out the value of every setting, where the precedence is...
+//
+//	A. a flag provided by the user: `provided`
+//	B. an environment variable
+//	C. the selected environment within the configuration file: `env`
+//	D. the flag default: `defaults`
+func (c *File) Resolve(env string, provided, defaults map[string]string) (*Resolved, error) {
+	environment, ok := c.Environments[env]
+	if env != "" && !ok {
+		return nil, fmt.Errorf("Unknown environment '%s' (available: %s)", env, c.environmentNames())
+	}
+
+	resolved := &Resolved{
+		Env:  env,
+		File: c.Path,
+	}
+
+	for _, s := range settings {
+		r := Setting{Name: s.name}
+
+		if value, ok := provided[s.name]; ok {
+			r.Value, r.Source, r.Detail = value, SourceFlag, "-"+s.name
+		} else if value := os.Getenv(s.envVar); s.envVar != "" && value != "" {
+			r.Value, r.Source, r.Detail = value, SourceEnvVar, s.envVar
+		} else if value := s.config(environment); value != "" {
+			if s.path && !filepath.IsAbs(value) {
+				value = filepath.Join(filepath.Dir(c.Path), value)
+			}
+			r.Value, r.Source, r.Detail = value, SourceConfig, fmt.Sprintf("%s [env.%s]", c.Path, env)
+		} else {
+			r.Value, r.Source = defaults[s.name], SourceDefault
+		}
+
+		resolved.Settings = append(resolved.Settings, r)
+	}
+
+	return resolved, nil
+}
+
+func (c *File) environmentNames() string {
+	if len(c.Environments) == 0 {
+		return "none"
+	}
+
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return fmt.Sprintf("%v", names)
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	// none of the environment variables the settings are read from are set
+	// unless a test sets them
+	for _, s := range settings {
+		if s.envVar == "" {
+			continue
+		}
+		if value, ok := os.LookupEnv(s.envVar); ok {
+			os.Unsetenv(s.envVar)
+			defer os.Setenv(s.envVar, value)
+		}
+	}
+
+	root := string(filepath.Separator) + "project"
+	cfg := &File{
+		Path: filepath.Join(root, "fastly.toml"),
+		Environments: map[string]Environment{
+			"stage": {Service: "config-service", Directory: "vcl", Match: "config-match", Concurrency: 4},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		env      string
+		provided map[string]string
+		envVars  map[string]string
+		setting  string
+		want     Setting
+	}{
+		{
+			name:     "flag over environment variable and config file",
+			env:      "stage",
+			provided: map[string]string{"service": "flag-service"},
+			envVars:  map[string]string{"FASTLY_SERVICE_ID": "env-service"},
+			setting:  "service",
+			want:     Setting{Name: "service", Value: "flag-service", Source: SourceFlag, Detail: "-service"},
+		},
+		{
+			name:    "environment variable over config file",
+			env:     "stage",
+			envVars: map[string]string{"FASTLY_SERVICE_ID": "env-service"},
+			setting: "service",
+			want:    Setting{Name: "service", Value: "env-service", Source: SourceEnvVar, Detail: "FASTLY_SERVICE_ID"},
+		},
+		{
+			name:    "config file over default",
+			env:     "stage",
+			setting: "match",
+			want:    Setting{Name: "match", Value: "config-match", Source: SourceConfig, Detail: cfg.Path + " [env.stage]"},
+		},
+		{
+			name:    "default without an environment",
+			setting: "match",
+			want:    Setting{Name: "match", Value: "default-match", Source: SourceDefault},
+		},
+		{
+			name:    "empty environment variable is ignored",
+			env:     "stage",
+			envVars: map[string]string{"VCL_MATCH_PATH": ""},
+			setting: "match",
+			want:    Setting{Name: "match", Value: "config-match", Source: SourceConfig, Detail: cfg.Path + " [env.stage]"},
+		},
+		{
+			name:     "provided flag with an empty value",
+			env:      "stage",
+			provided: map[string]string{"match": ""},
+			setting:  "match",
+			want:     Setting{Name: "match", Value: "", Source: SourceFlag, Detail: "-match"},
+		},
+		{
+			name:    "relative directory is relative to the config file",
+			env:     "stage",
+			setting: "dir",
+			want:    Setting{Name: "dir", Value: filepath.Join(root, "vcl"), Source: SourceConfig, Detail: cfg.Path + " [env.stage]"},
+		},
+		{
+			name:    "concurrency from the config file",
+			env:     "stage",
+			setting: "concurrency",
+			want:    Setting{Name: "concurrency", Value: "4", Source: SourceConfig, Detail: cfg.Path + " [env.stage]"},
+		},
+	}
+
+	defaults := map[string]string{"match": "default-match", "concurrency": "1"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.envVars {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+
+			resolved, err := cfg.Resolve(tt.env, tt.provided, defaults)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := resolved.Get(tt.setting); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveUnknownEnvironment(t *testing.T) {
+	cfg := &File{Environments: map[string]Environment{"stage": {}, "prod": {}}}
+
+	_, err := cfg.Resolve("dev", nil, nil)
+	if err == nil || err.Error() != "Unknown environment 'dev' (available: [prod stage])" {
+		t.Errorf("got error %v, want an unknown environment error", err)
+	}
+}
@@ -0,0 +1,471 @@
+// Diff is a package that implements a line based diff (using the Myers
+// algorithm) for comparing local VCL files against their remote versions.
+
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change
+const DefaultContext = 3
+
+// ErrNegativeContext is returned when the number of context lines is negative
+var ErrNegativeContext = errors.New("the number of context lines can't be negative")
+
+// commentLine matches lines that are indented comments (mirrors the previous
+// `diff --ignore-matching-lines '^[[:space:]]\+#'` behaviour)
+var commentLine = regexp.MustCompile(`^\s+#`)
+
+// Options controls how lines are compared and how much context is rendered
+type Options struct {
+	IgnoreAllSpace   bool
+	IgnoreBlankLines bool
+	IgnoreMatching   *regexp.Regexp
+	Context          int
+}
+
+// VCLOptions returns the options used when comparing VCL files
+func VCLOptions(context int) Options {
+	return Options{
+		IgnoreAllSpace:   true,
+		IgnoreBlankLines: true,
+		IgnoreMatching:   commentLine,
+		Context:          context,
+	}
+}
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is a single step of the edit script
+// a and b are the positions within each sequence at the time of the step
+type op struct {
+	kind opKind
+	a, b int
+}
+
+// Result describes the differences between two sets of lines
+type Result struct {
+	Added   int
+	Removed int
+
+	from, to []string
+	ops      []op
+	relevant []bool
+	context  int
+}
+
+// Equal reports whether no (non-ignored) differences were found
+func (r Result) Equal() bool {
+	return r.Added == 0 && r.Removed == 0
+}
+
+// Strings compares the content of two strings line by line
+func Strings(from, to string, opts Options) Result {
+	return Lines(splitLines(from), splitLines(to), opts)
+}
+
+// Lines compares two slices of lines
+func Lines(from, to []string, opts Options) Result {
+	keyFrom := keys(from, opts)
+	keyTo := keys(to, opts)
+
+	ops := myers(keyFrom, keyTo)
+
+	r := Result{
+		from:     from,
+		to:       to,
+		ops:      ops,
+		relevant: make([]bool, len(ops)),
+		context:  opts.Context,
+	}
+
+	// a run of consecutive changes is only relevant if at least one of its
+	// lines isn't ignorable (e.g. a blank line or a comment)
+	for start := 0; start < len(ops); {
+		if ops[start].kind == opEqual {
+			start++
+			continue
+		}
+
+		end := start
+		ignorable := true
+		for end < len(ops) && ops[end].kind != opEqual {
+			if !r.ignorable(ops[end], opts) {
+				ignorable = false
+			}
+			end++
+		}
+
+		if !ignorable {
+			for i := start; i < end; i++ {
+				r.relevant[i] = true
+
+				if r.ignorable(ops[i], opts) {
+					continue
+				}
+				if ops[i].kind == opDelete {
+					r.Removed++
+				} else {
+					r.Added++
+				}
+			}
+		}
+
+		start = end
+	}
+
+	return r
+}
+
+func (r Result) ignorable(o op, opts Options) bool {
+	line := r.lineFor(o)
+
+	if opts.IgnoreBlankLines && strings.TrimSpace(line) == "" {
+		return true
+	}
+	if opts.IgnoreMatching != nil && opts.IgnoreMatching.MatchString(line) {
+		return true
+	}
+	return false
+}
+
+func (r Result) lineFor(o op) string {
+	if o.kind == opInsert {
+		return r.to[o.b]
+	}
+	return r.from[o.a]
+}
+
+// hunk is a range of ops (end exclusive) to be rendered together
+type hunk struct {
+	start, end int
+}
+
+func (r Result) hunks() []hunk {
+	var hunks []hunk
+
+	for i := 0; i < len(r.ops); i++ {
+		if !r.relevant[i] {
+			continue
+		}
+
+		end := i
+		for end < len(r.ops) && r.relevant[end] {
+			end++
+		}
+
+		h := hunk{start: r.extend(i, -1), end: r.extend(end-1, 1) + 1}
+
+		// merge hunks whose context overlaps
+		if len(hunks) > 0 && h.start <= hunks[len(hunks)-1].end {
+			hunks[len(hunks)-1].end = h.end
+		} else {
+			hunks = append(hunks, h)
+		}
+
+		i = end - 1
+	}
+
+	return hunks
+}
+
+// extend walks from the op at index i in the given direction and returns the
+// furthest index that still falls within the configured number of context
+// lines (ignored changes are included but don't count towards the context)
+func (r Result) extend(i, direction int) int {
+	seen := 0
+	for {
+		next := i + direction
+		if next < 0 || next >= len(r.ops) {
+			return i
+		}
+		if r.ops[next].kind == opEqual {
+			if seen == r.context {
+				return i
+			}
+			seen++
+		}
+		i = next
+	}
+}
+
+// Unified renders the result in the unified diff format
+// an empty string is returned when there are no differences
+func (r Result) Unified(fromName, toName string) string {
+	if r.Equal() {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromName, toName)
+
+	for _, h := range r.hunks() {
+		ops := r.ops[h.start:h.end]
+
+		var fromCount, toCount int
+		for _, o := range ops {
+			switch o.kind {
+			case opEqual:
+				fromCount++
+				toCount++
+			case opDelete:
+				fromCount++
+			case opInsert:
+				toCount++
+			}
+		}
+
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(ops[0].a, fromCount), hunkRange(ops[0].b, toCount))
+
+		for _, o := range ops {
+			switch o.kind {
+			case opEqual:
+				fmt.Fprintf(&buf, " %s\n", r.from[o.a])
+			case opDelete:
+				fmt.Fprintf(&buf, "-%s\n", r.from[o.a])
+			case opInsert:
+				fmt.Fprintf(&buf, "+%s\n", r.to[o.b])
+			}
+		}
+	}
+
+	return buf.String()
+}
+
+// hunkRange formats a hunk header range the same way GNU diff does
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// keys returns the values that lines are compared by
+func keys(lines []string, opts Options) []string {
+	k := make([]string, len(lines))
+	for i, line := range lines {
+		if opts.IgnoreAllSpace {
+			line = strings.Map(func(r rune) rune {
+				if unicode.IsSpace(r) {
+					return -1
+				}
+				return r
+			}, line)
+		}
+		k[i] = line
+	}
+	return k
+}
+
+// myers returns the shortest edit script that transforms a into b, using the
+// linear space refinement of "An O(ND) Difference Algorithm and Its
+// Variations" (Myers, 1986): the point where an optimal path crosses the
+// middle is found by searching from both ends at the same time, then each
+// side of it is compared in the same way
+func myers(a, b []string) []op {
+	s := &solver{a: a, b: b}
+	s.compare(0, len(a), 0, len(b))
+	return order(s.ops)
+}
+
+// solver accumulates the edit script of a and b
+type solver struct {
+	a, b []string
+	ops  []op
+}
+
+// compare appends the ops that transform a[aLo:aHi] into b[bLo:bHi]
+func (s *solver) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && s.a[aLo] == s.b[bLo] {
+		s.ops = append(s.ops, op{opEqual, aLo, bLo})
+		aLo++
+		bLo++
+	}
+
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && s.a[aHi-suffix-1] == s.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	x, y, ok := s.split(aLo, aHi, bLo, bHi)
+	if ok {
+		s.compare(aLo, x, bLo, y)
+		s.compare(x, aHi, y, bHi)
+	} else {
+		for x := aLo; x < aHi; x++ {
+			s.ops = append(s.ops, op{opDelete, x, bLo})
+		}
+		for y := bLo; y < bHi; y++ {
+			s.ops = append(s.ops, op{opInsert, aHi, y})
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		s.ops = append(s.ops, op{opEqual, aHi + i, bHi + i})
+	}
+}
+
+// split returns a point on an optimal path through a[aLo:aHi] and
+// b[bLo:bHi], which (differing at both ends) are compared forwards and
+// backwards until the two searches overlap
+//
+// No point is returned when either side is empty, as every line of the other
+// side is then deleted or inserted
+func (s *solver) split(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n, m := aHi-aLo, bHi-bLo
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	// forward[k] and backward[k] are the furthest x reached on each diagonal
+	// (k = x - y), the backward search measures x and y from the end
+	max := (n + m + 1) / 2
+	offset := max + 1
+	forward := make([]int, 2*max+3)
+	backward := make([]int, 2*max+3)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	// the diagonals are only compared by one of the searches, depending on
+	// which of them reaches the middle first
+	delta := n - m
+	odd := delta%2 != 0
+
+	// the diagonals that have run off the edit graph are skipped
+	var forwardStart, forwardEnd, backwardStart, backwardEnd int
+
+	for d := 0; d < max; d++ {
+		for k := -d + forwardStart; k <= d-forwardEnd; k += 2 {
+			i := offset + k
+
+			var x int
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && s.a[aLo+x] == s.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[i] = x
+
+			switch {
+			case x > n:
+				forwardEnd += 2
+			case y > m:
+				forwardStart += 2
+			case odd:
+				j := offset + delta - k
+				if j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+
+		for k := -d + backwardStart; k <= d-backwardEnd; k += 2 {
+			i := offset + k
+
+			var x int
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && s.a[aHi-1-x] == s.b[bHi-1-y] {
+				x++
+				y++
+			}
+			backward[i] = x
+
+			switch {
+			case x > n:
+				backwardEnd += 2
+			case y > m:
+				backwardStart += 2
+			case !odd:
+				j := offset + delta - k
+				if j >= 0 && j < len(forward) && forward[j] != -1 && forward[j] >= n-x {
+					fx := forward[j]
+					return aLo + fx, bLo + fx - (delta - k), true
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// order moves the deletions of each run of changes before its insertions
+// (as with GNU diff) and updates the positions of the ops to match
+func order(ops []op) []op {
+	ordered := make([]op, 0, len(ops))
+	x, y := 0, 0
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			ordered = append(ordered, op{opEqual, x, y})
+			x++
+			y++
+			i++
+			continue
+		}
+
+		end := i
+		deletes, inserts := 0, 0
+		for end < len(ops) && ops[end].kind != opEqual {
+			if ops[end].kind == opDelete {
+				deletes++
+			} else {
+				inserts++
+			}
+			end++
+		}
+
+		for j := 0; j < deletes; j++ {
+			ordered = append(ordered, op{opDelete, x, y})
+			x++
+		}
+		for j := 0; j < inserts; j++ {
+			ordered = append(ordered, op{opInsert, x, y})
+			y++
+		}
+
+		i = end
+	}
+
+	return ordered
+}
@@ -0,0 +1,180 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		opts     Options
+		want     string
+	}{
+		{
+			name: "equal",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+			opts: Options{Context: DefaultContext},
+			want: "",
+		},
+		{
+			name: "changed line",
+			from: "a\nb\nc\n",
+			to:   "a\nx\nc\n",
+			opts: Options{Context: DefaultContext},
+			want: "--- remote\n+++ local\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "deletions before insertions",
+			from: "a\nb\nc\nd\n",
+			to:   "a\nx\ny\nd\n",
+			opts: Options{Context: DefaultContext},
+			want: "--- remote\n+++ local\n@@ -1,4 +1,4 @@\n a\n-b\n-c\n+x\n+y\n d\n",
+		},
+		{
+			name: "added to empty",
+			from: "",
+			to:   "a\nb\n",
+			opts: Options{Context: DefaultContext},
+			want: "--- remote\n+++ local\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "no context",
+			from: "a\nb\nc\nd\ne\n",
+			to:   "a\nb\nx\nd\ne\n",
+			opts: Options{},
+			want: "--- remote\n+++ local\n@@ -3 +3 @@\n-c\n+x\n",
+		},
+		{
+			name: "separate hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			to:   "x\n2\n3\n4\n5\n6\n7\ny\n",
+			opts: Options{Context: 1},
+			want: "--- remote\n+++ local\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+y\n",
+		},
+		{
+			name: "ignored whitespace, blank lines and comments",
+			from: "sub vcl_recv {\n  set req.http.X = 1;\n}\n",
+			to:   "sub vcl_recv {\n\n    # a comment\n  set  req.http.X  =  1;\n}\n",
+			opts: VCLOptions(DefaultContext),
+			want: "",
+		},
+		{
+			name: "ignored lines shown around a relevant change",
+			from: "a\nb\n",
+			to:   "a\n  # why\nc\n",
+			opts: VCLOptions(DefaultContext),
+			want: "--- remote\n+++ local\n@@ -1,2 +1,3 @@\n a\n-b\n+  # why\n+c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Strings(tt.from, tt.to, tt.opts).Unified("remote", "local")
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCounts(t *testing.T) {
+	r := Strings("a\nb\nc\n", "a\nc\nd\ne\n", Options{Context: DefaultContext})
+	if r.Added != 2 || r.Removed != 1 {
+		t.Errorf("got +%d -%d, want +2 -1", r.Added, r.Removed)
+	}
+	if r.Equal() {
+		t.Error("expected a difference")
+	}
+}
+
+// TestShortestEditScript compares random sequences (from a small alphabet, so
+// they share lines) and checks the edit script transforms one into the other
+// with the fewest possible changes
+func TestShortestEditScript(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string('a' + rune(rng.Intn(4)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 2000; i++ {
+		a, b := random(), random()
+		ops := myers(a, b)
+
+		var rebuilt []string
+		changes := 0
+		x, y := 0, 0
+		for _, o := range ops {
+			if o.a != x || o.b != y {
+				t.Fatalf("%v -> %v: op %+v at position %d,%d", a, b, o, x, y)
+			}
+			switch o.kind {
+			case opEqual:
+				if a[o.a] != b[o.b] {
+					t.Fatalf("%v -> %v: unequal lines kept", a, b)
+				}
+				rebuilt = append(rebuilt, a[o.a])
+				x++
+				y++
+			case opDelete:
+				changes++
+				x++
+			case opInsert:
+				rebuilt = append(rebuilt, b[o.b])
+				changes++
+				y++
+			}
+		}
+
+		if x != len(a) || strings.Join(rebuilt, "") != strings.Join(b, "") {
+			t.Fatalf("%v -> %v: rebuilt %v", a, b, rebuilt)
+		}
+		if want := len(a) + len(b) - 2*lcs(a, b); changes != want {
+			t.Fatalf("%v -> %v: %d changes, want %d", a, b, changes, want)
+		}
+	}
+}
+
+// TestRewrite checks a file that's rewritten entirely is compared without
+// running out of memory (the space used is linear in the number of lines)
+func TestRewrite(t *testing.T) {
+	from := make([]string, 5000)
+	to := make([]string, 5000)
+	for i := range from {
+		from[i] = fmt.Sprintf("old %d", i)
+		to[i] = fmt.Sprintf("new %d", i)
+	}
+
+	r := Lines(from, to, Options{Context: DefaultContext})
+	if r.Added != 5000 || r.Removed != 5000 {
+		t.Errorf("got +%d -%d, want +5000 -5000", r.Added, r.Removed)
+	}
+}
+
+// lcs returns the length of the longest common subsequence
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
-	"github.com/integralist/go-fastly-cli/commands"
+	"github.com/integralist/go-fastly-cli/auth"
 	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/config"
 	"github.com/integralist/go-fastly-cli/flags"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
 	"github.com/integralist/go-fastly-cli/standalone"
 
-	"github.com/sethvargo/go-fastly/fastly"
+	"github.com/fastly/go-fastly/fastly"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,6 +25,8 @@ const appVersion = "0.0.6"
 
 var logger *logrus.Entry
 
+var errMultipleServices = errors.New("this command can only be run against a single service")
+
 func init() {
 	logrus.SetFormatter(&logrus.JSONFormatter{})
 	logrus.SetLevel(logrus.InfoLevel)
@@ -27,124 +35,195 @@ func init() {
 	})
 }
 
-func showHelp(f flags.Flags) bool {
-	if len(os.Args) < 2 || *f.Top.Help == true || *f.Top.HelpShort == true {
-		return true
+// requireSingleService stops commands that can't be run against several
+// services at once (e.g. because they prompt for confirmation)
+func requireSingleService(services []string, command string) {
+	if len(services) > 1 {
+		output.Failf(errMultipleServices, "The %s command can only be run against a single service\n", command)
 	}
-	return false
 }
 
-func showSettings(version, service string, client *fastly.Client) {
-	if version == "latest" {
-		standalone.PrintLatestSettings(service, client)
-		common.Success()
+// requireForce stops a command that activates a version of a service listed
+// as protected in the configuration file, unless -force was provided
+func requireForce(cfg *config.File, service string, force bool) {
+	if cfg.IsProtected(service) && !force {
+		output.Failf(standalone.ErrProtectedService, "Service '%s' is protected, use -force to activate it\n", common.Red(service))
 	}
+}
 
-	if version != "" {
-		settingsVersion, err := strconv.Atoi(version)
-		if err != nil {
-			fmt.Println(err)
-			common.Failure()
-		}
-
-		standalone.PrintSettingsFor(service, settingsVersion, client)
-		common.Success()
+// applyConfig replaces the flag values with the resolved settings
+// the main VCL is used as the default of each subcommand's -main flag (a
+// -main flag provided to the subcommand is itself the resolved setting)
+func applyConfig(f flags.Flags, resolved *config.Resolved) error {
+	*f.Top.Service = resolved.Get("service").Value
+	*f.Top.Directory = resolved.Get("dir").Value
+	*f.Top.Match = resolved.Get("match").Value
+	*f.Top.Skip = resolved.Get("skip").Value
+	*f.Top.Token = resolved.Get("token").Value
+	*f.Top.Profile = resolved.Get("profile").Value
+
+	concurrency, err := strconv.Atoi(resolved.Get("concurrency").Value)
+	if err != nil {
+		return fmt.Errorf("Unable to convert the concurrency setting:\n\t%+v", err)
 	}
+	*f.Top.Concurrency = concurrency
+
+	mainVCL := resolved.Get("main").Value
+	*f.Sub.MainVCL = mainVCL
+	*f.Sub.DeployMainVCL = mainVCL
+	*f.Sub.SyncMainVCL = mainVCL
+
+	return nil
 }
 
-func handleStatus(status, service string, client *fastly.Client) {
-	if status != "" && status == "latest" {
-		status, err := standalone.GetLatestServiceVersionStatus(service, client)
-		if err != nil {
-			fmt.Println(err)
-			common.Failure()
-		}
+// resolveToken retrieves the token from the stored credentials profile when
+// a token wasn't provided by either a flag or an environment variable
+func resolveToken(f flags.Flags, resolved *config.Resolved) error {
+	if *f.Top.Token != "" {
+		return nil
+	}
 
-		fmt.Println(status)
-		common.Success()
+	creds, err := auth.Load()
+	if err != nil {
+		return err
 	}
 
-	if status != "" {
-		statusVersion, err := strconv.Atoi(status)
-		if err != nil {
-			fmt.Println(err)
-			common.Failure()
-		}
+	name := creds.Selected(*f.Top.Profile)
+
+	token, err := creds.Token(name)
+	if err == auth.ErrUnknownProfile && *f.Top.Profile == "" {
+		// nothing has been stored, so there's no token to use
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to retrieve the token for profile '%s':\n\t%s", name, err)
+	}
 
-		status, err := standalone.GetStatusForVersion(service, statusVersion, client)
-		if err != nil {
-			fmt.Println(err)
-			common.Failure()
-		}
+	*f.Top.Token = token
+	resolved.Set(config.Setting{
+		Name:   "token",
+		Value:  token,
+		Source: config.SourceCredentials,
+		Detail: fmt.Sprintf("%s [profiles.%s]", creds.File(), name),
+	})
 
-		fmt.Println(status)
+	return nil
+}
+
+// verifyToken reports the scope and expiry of the token before running a
+// command that changes a service (stopping when the token isn't valid)
+func verifyToken(client api.Requester) {
+	token, err := api.VerifyToken(client)
+	if err != nil {
+		output.Failf(err, "\nUnable to verify your API token:\n\n%s\n\n", common.Red(err))
+	}
+
+	if output.Structured() {
+		return
 	}
+
+	fmt.Printf("Using API token '%s' (scope: %s, expires: %s)\n\n", common.Yellow(token.Name), common.Yellow(token.Scope), common.Yellow(token.Expiry()))
 }
 
-func main() {
-	f := flags.New()
+// app is the state shared by the commands, which is populated by configure
+// (and connect, for the commands that call the Fastly API) once the command
+// line has been parsed
+type app struct {
+	f   flags.Flags
+	ctx context.Context
+
+	cfg      *config.File
+	resolved *config.Resolved
+	services []string
+	service  string
+	client   *fastly.Client
+}
 
-	activate := *f.Top.Activate
-	debug := *f.Top.Debug
-	service := *f.Top.Service
-	settings := *f.Top.Settings
-	status := *f.Top.Status
-	token := *f.Top.Token
-	validate := *f.Top.Validate
-	version := *f.Top.Version
+// configure selects the output format and resolves each setting from the
+// flags, environment variables and configuration file
+func (a *app) configure() {
+	format, err := output.Parse(*a.f.Top.Output)
+	if err != nil {
+		fmt.Println(err)
+		common.Failure()
+	}
+	output.SetFormat(format)
 
-	if debug == true {
-		logrus.SetLevel(logrus.DebugLevel)
+	a.cfg, err = config.Load()
+	if err != nil {
+		output.Fail(err)
 	}
-	logger.Debug("flags initialised, application starting")
 
-	if version == true {
-		fmt.Println(appVersion)
-		return
+	a.resolved, err = a.cfg.Resolve(*a.f.Top.Env, a.f.Provided(), a.f.Defaults())
+	if err != nil {
+		output.Fail(err)
+	}
+
+	if err := applyConfig(a.f, a.resolved); err != nil {
+		output.Fail(err)
+	}
+
+	// expand a group name into its comma separated list of service ids
+	a.services = a.cfg.Services(*a.f.Top.Service)
+	a.service = strings.Join(a.services, ",")
+	*a.f.Top.Service = a.service
+}
+
+// authenticate configures the app, then retrieves the token from the stored
+// credentials profile (unless one was provided)
+func (a *app) authenticate() {
+	a.configure()
+
+	if err := resolveToken(a.f, a.resolved); err != nil {
+		output.Fail(err)
+	}
+}
+
+// connect authenticates and then creates the Fastly API client
+func (a *app) connect() {
+	a.authenticate()
+
+	client, err := fastly.NewClient(*a.f.Top.Token)
+	if err != nil {
+		output.Fail(err)
 	}
 
-	if showHelp(f) {
-		f.Help()
+	// retry rate limited (and failed) API requests for every command
+	httpClient := *client.HTTPClient
+	httpClient.Transport = api.NewRetryTransport(httpClient.Transport)
+	client.HTTPClient = &httpClient
+
+	a.client = client
+}
+
+func main() {
+	f := flags.New()
+
+	a := &app{
+		f:   f,
+		ctx: context.Background(),
 	}
 
-	client, err := fastly.NewClient(token)
+	inv, err := commandTree(a).Parse(os.Args[1:])
 	if err != nil {
 		fmt.Println(err)
 		common.Failure()
 	}
 
-	if activate != "" {
-		standalone.ActivateVersion(activate, service, client)
-		return
+	if *f.Top.Debug == true {
+		logrus.SetLevel(logrus.DebugLevel)
 	}
+	logger.Debug("flags initialised, application starting")
 
-	if validate != "" {
-		standalone.ValidateVersion(validate, service, client)
+	if *f.Top.Version == true {
+		fmt.Println(appVersion)
 		return
 	}
 
-	handleStatus(status, service, client)
-	showSettings(settings, service, client)
-
-	args := os.Args[1:] // strip first arg `fastly`
-	arg, counter := f.Check(args)
-	subset := args[counter:]
-
-	switch arg {
-	case "delete":
-		f.Top.Delete.Parse(subset)
-		commands.Delete(f, client)
-	case "diff":
-		f.Top.Diff.Parse(subset)
-		commands.Diff(f, client)
-	case "list":
-		f.Top.List.Parse(subset)
-		commands.List(f, client)
-	case "upload":
-		f.Top.Upload.Parse(subset)
-		commands.Upload(f, client)
-	default:
-		fmt.Printf("%v is not valid command.\n", arg)
-		common.Failure()
+	if *f.Top.Help == true || *f.Top.HelpShort == true {
+		inv.Command.Help()
+		common.Success()
 	}
+
+	inv.Run()
 }
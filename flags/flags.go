@@ -5,11 +5,11 @@ package flags
 
 import (
 	"flag"
-	"fmt"
-	"os"
 	"strings"
 
-	"github.com/integralist/go-fastly-cli/common"
+	"github.com/integralist/go-fastly-cli/config"
+	"github.com/integralist/go-fastly-cli/diff"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
 	"github.com/sirupsen/logrus"
 )
 
@@ -23,119 +23,456 @@ func init() {
 
 // TopLevelFlags defines the common settings across all commands
 type TopLevelFlags struct {
-	Help, HelpShort, Debug, Version                                              *bool
-	Token, Service, Directory, Match, Skip, Status, Activate, Validate, Settings *string
-	Delete, Diff, List, Upload                                                   *flag.FlagSet
+	Help, HelpShort, Debug, DryRun, Force, Version, Yes                                                *bool
+	Concurrency                                                                                        *int
+	Token, Service, Directory, Env, Match, Output, Profile, Skip, Status, Activate, Validate, Settings *string
+	Auth, Delete, Deploy, Diff, List, Rollback, Sync, Upload                                           *flag.FlagSet
+	SnippetDelete, SnippetDiff, SnippetGet, SnippetList, SnippetUpload                                 *flag.FlagSet
+	DictionaryCreate, DictionaryDelete, DictionaryList                                                 *flag.FlagSet
+	ItemDelete, ItemGet, ItemImport, ItemList, ItemSet                                                 *flag.FlagSet
+	ACLCreate, ACLDelete, ACLList, EntryAdd, EntryList, EntryRemove, EntrySync                         *flag.FlagSet
+	BackendCreate, BackendDelete, BackendDiff, BackendList, BackendShow, BackendUpdate                 *flag.FlagSet
+	HealthCheckCreate, HealthCheckDelete, HealthCheckList, HealthCheckUpdate                           *flag.FlagSet
+	DirectorCreate, DirectorDelete, DirectorList, DirectorUpdate                                       *flag.FlagSet
+	ConditionCreate, ConditionDelete, ConditionList, ConditionUpdate                                   *flag.FlagSet
+	DomainAdd, DomainCheck, DomainList, DomainRemove                                                   *flag.FlagSet
 }
 
-// SubCommandFlags defines the settings for the subcommands
-type SubCommandFlags struct {
-	CloneVersion     *string
-	UploadVersion    *string
-	UseLatestVersion *bool
-	VclDeleteVersion *string
-	VclListVersion   *string
-	VclName          *string
-	VclVersion       *string
+// TargetFlags defines the flags selecting the version a change to the
+// service's configuration is made to (see: vcl.Target)
+type TargetFlags struct {
+	Clone   *string
+	Comment *string
+	Latest  *bool
+	Version *string
 }
 
-// Flags defines type of structure returned to user
-type Flags struct {
-	Top TopLevelFlags
-	Sub SubCommandFlags
+// BackendFlags defines the settings of a backend to create or update, only
+// the flags provided by the user are sent (see: vcl.BackendSettings)
+type BackendFlags struct {
+	Address             *string
+	AutoLoadbalance     *bool
+	BetweenBytesTimeout *uint
+	ConnectTimeout      *uint
+	FirstByteTimeout    *uint
+	HealthCheck         *string
+	MaxConn             *uint
+	MaxTLSVersion       *string
+	MinTLSVersion       *string
+	OverrideHost        *string
+	Port                *uint
+	RequestCondition    *string
+	Shield              *string
+	SSLCertHostname     *string
+	SSLCheckCert        *bool
+	SSLSNIHostname      *string
+	UseSSL              *bool
+	Weight              *uint
 }
 
-// Help displays help instructions
-func (f Flags) Help() {
-	fmt.Printf("\nFlags:\n\n")
-
-	flag.PrintDefaults()
-
-	divider := "\n -------------------------------------------------------------------\n\n"
-	delete := "\n  fastly delete\n\tdelete a specific vcl file from the remote service\n\te.g. fastly delete -name test_file -version 123\n"
-	diff := "\n  fastly diff\n\tview a diff between your local files and the remote versions\n\te.g. fastly diff -version 123\n"
-	list := "\n  fastly list\n\tlist all vcl files found within specified remote service version\n\te.g. fastly list -version 123\n"
-	upload := "\n  fastly upload\n\tupload local files to your remote service version\n\te.g. fastly upload -version 123\n"
+// HealthCheckFlags defines the settings of a healthcheck to create or update,
+// only the flags provided by the user are sent (see: vcl.HealthCheckSettings)
+type HealthCheckFlags struct {
+	CheckInterval    *uint
+	ExpectedResponse *uint
+	Host             *string
+	HTTPVersion      *string
+	Initial          *uint
+	Method           *string
+	Path             *string
+	Threshold        *uint
+	Timeout          *uint
+	Window           *uint
+}
 
-	fmt.Printf("%sExamples:\n\n%s%s%s%s", divider, list, delete, diff, upload)
+// DirectorFlags defines the settings of a director to create or update, only
+// the flags provided by the user are sent (see: vcl.DirectorSettings)
+type DirectorFlags struct {
+	Backends *string
+	Quorum   *uint
+	Retries  *uint
+	Type     *string
+}
 
-	common.Success()
+// ConditionFlags defines the settings of a condition to create or update,
+// only the flags provided by the user are sent (see: vcl.ConditionSettings)
+type ConditionFlags struct {
+	Priority  *int
+	Statement *string
+	Type      *string
 }
 
-// Check determines if a flag was specified before the subcommand
-// then returns the subcommand argument value based on the correct index
-// followed by the index of where the subcommand's flags start in the args list
-func (f Flags) Check(args []string) (string, int) {
-	counter := 0
-	subcommandSeen := false
+// SubCommandFlags defines the settings for the subcommands
+type SubCommandFlags struct {
+	ACLCreate                 TargetFlags
+	ACLDelete                 TargetFlags
+	ACLListVersion            *string
+	AuthHelper                *string
+	BackendCreate             TargetFlags
+	BackendCreateSettings     BackendFlags
+	BackendDelete             TargetFlags
+	BackendListVersion        *string
+	BackendShowVersion        *string
+	BackendUpdate             TargetFlags
+	BackendUpdateSettings     BackendFlags
+	CloneVersion              *string
+	ConditionCreate           TargetFlags
+	ConditionCreateSettings   ConditionFlags
+	ConditionDelete           TargetFlags
+	ConditionListVersion      *string
+	ConditionUpdate           TargetFlags
+	ConditionUpdateSettings   ConditionFlags
+	DeployCloneVersion        *string
+	DeployComment             *string
+	DeployLatest              *bool
+	DeployMainVCL             *string
+	DeployVersion             *string
+	DictionaryCreate          TargetFlags
+	DictionaryDelete          TargetFlags
+	DictionaryListVersion     *string
+	DictionaryWriteOnly       *bool
+	DiffContext               *int
+	DirectorCreate            TargetFlags
+	DirectorCreateSettings    DirectorFlags
+	DirectorDelete            TargetFlags
+	DirectorListVersion       *string
+	DirectorUpdate            TargetFlags
+	DirectorUpdateSettings    DirectorFlags
+	DomainAdd                 TargetFlags
+	DomainCheckResolver       *string
+	DomainCheckVersion        *string
+	DomainListVersion         *string
+	DomainRemove              TargetFlags
+	EntryAddComment           *string
+	EntryAddVersion           *string
+	EntryListVersion          *string
+	EntryRemoveVersion        *string
+	EntrySyncConfirm          *bool
+	EntrySyncVersion          *string
+	HealthCheckCreate         TargetFlags
+	HealthCheckCreateSettings HealthCheckFlags
+	HealthCheckDelete         TargetFlags
+	HealthCheckListVersion    *string
+	HealthCheckUpdate         TargetFlags
+	HealthCheckUpdateSettings HealthCheckFlags
+	ItemDeleteVersion         *string
+	ItemGetVersion            *string
+	ItemImportConfirm         *bool
+	ItemImportFormat          *string
+	ItemImportVersion         *string
+	ItemListVersion           *string
+	ItemSetVersion            *string
+	MainVCL                   *string
+	RollbackConfirm           *bool
+	RollbackTo                *string
+	SnippetClone              *string
+	SnippetComment            *string
+	SnippetConfirm            *bool
+	SnippetContext            *int
+	SnippetDelVersion         *string
+	SnippetDiffVersion        *string
+	SnippetDynamic            *bool
+	SnippetGetVersion         *string
+	SnippetLatest             *bool
+	SnippetListVersion        *string
+	SnippetVersion            *string
+	SyncCloneVersion          *string
+	SyncComment               *string
+	SyncConfirm               *bool
+	SyncDeleteMatch           *string
+	SyncLatest                *bool
+	SyncMainVCL               *string
+	SyncVersion               *string
+	UploadComment             *string
+	UploadVersion             *string
+	UseLatestVersion          *bool
+	VclDeleteVersion          *string
+	VclListVersion            *string
+	VclName                   *string
+	VclVersion                *string
+}
 
-	for _, arg := range args {
-		if subcommandSeen {
-			break
-		}
+// Flags defines type of structure returned to user
+type Flags struct {
+	Top TopLevelFlags
+	Sub SubCommandFlags
+}
 
-		if strings.HasPrefix(arg, "-") == true {
-			counter++
-			continue
-		}
+// Provided returns the flags explicitly provided by the user, which are the
+// top level flags along with the flags of the upload, deploy and sync
+// subcommands (as their -main flag is also a setting)
+func (f Flags) Provided() map[string]string {
+	provided := map[string]string{}
+	visit := func(fl *flag.Flag) {
+		provided[fl.Name] = fl.Value.String()
+	}
 
-		if arg == "delete" || arg == "diff" || arg == "list" || arg == "upload" {
-			subcommandSeen = true
-		} else {
-			counter++
+	flag.Visit(visit)
+	for _, fs := range []*flag.FlagSet{f.Top.Upload, f.Top.Deploy, f.Top.Sync} {
+		if fs.Parsed() {
+			fs.Visit(visit)
 		}
 	}
 
-	subcommandFlagsIndex := counter + 1
-
-	logger.WithFields(logrus.Fields{
-		"args":       args,
-		"counter":    counter,
-		"subcommand": args[counter],
-		"index":      subcommandFlagsIndex,
-	}).Debug("subcommand selected")
+	return provided
+}
 
-	return args[counter], subcommandFlagsIndex
+// Defaults returns the default value of each top level flag
+func (f Flags) Defaults() map[string]string {
+	defaults := map[string]string{}
+	flag.VisitAll(func(fl *flag.Flag) {
+		defaults[fl.Name] = fl.DefValue
+	})
+	return defaults
 }
 
 // New returns defined flags
+// the flags are parsed along with the commands by the cli package
 func New() Flags {
 	topLevelFlags := TopLevelFlags{
-		Activate:  flag.String("activate", "", "specify Fastly service version to activate"),
-		Debug:     flag.Bool("debug", false, "show any error/diff output + debug logs"),
-		Delete:    flag.NewFlagSet("delete", flag.ExitOnError),
-		Diff:      flag.NewFlagSet("diff", flag.ExitOnError),
-		Directory: flag.String("dir", os.Getenv("VCL_DIRECTORY"), "vcl directory to compare files against"),
-		Help:      flag.Bool("help", false, "show available flags"),
-		HelpShort: flag.Bool("h", false, "show available flags"),
-		List:      flag.NewFlagSet("list", flag.ExitOnError),
-		Match:     flag.String("match", "", "regex for matching vcl directories (will also try: VCL_MATCH_PATH)"),
-		Service:   flag.String("service", os.Getenv("FASTLY_SERVICE_ID"), "your service id (fallback: FASTLY_SERVICE_ID)"),
-		Settings:  flag.String("settings", "", "get settings (Default TTL & Host) for specified Fastly service version (version number or latest)"),
-		Skip:      flag.String("skip", "^____", "regex for skipping vcl directories (will also try: VCL_SKIP_PATH)"),
-		Status:    flag.String("status", "", "retrieve status for the specified Fastly service 'version' (try: 'latest')"),
-		Token:     flag.String("token", os.Getenv("FASTLY_API_TOKEN"), "your fastly api token (fallback: FASTLY_API_TOKEN)"),
-		Upload:    flag.NewFlagSet("upload", flag.ExitOnError),
-		Validate:  flag.String("validate", "", "specify Fastly service version to validate"),
-		Version:   flag.Bool("version", false, "show application version"),
+		ACLCreate:         flag.NewFlagSet("create", flag.ExitOnError),
+		ACLDelete:         flag.NewFlagSet("delete", flag.ExitOnError),
+		ACLList:           flag.NewFlagSet("list", flag.ExitOnError),
+		Activate:          flag.String("activate", "", "deprecated: use the version activate command"),
+		Auth:              flag.NewFlagSet("auth", flag.ExitOnError),
+		BackendCreate:     flag.NewFlagSet("create", flag.ExitOnError),
+		BackendDelete:     flag.NewFlagSet("delete", flag.ExitOnError),
+		BackendDiff:       flag.NewFlagSet("diff", flag.ExitOnError),
+		BackendList:       flag.NewFlagSet("list", flag.ExitOnError),
+		BackendShow:       flag.NewFlagSet("show", flag.ExitOnError),
+		BackendUpdate:     flag.NewFlagSet("update", flag.ExitOnError),
+		ConditionCreate:   flag.NewFlagSet("create", flag.ExitOnError),
+		ConditionDelete:   flag.NewFlagSet("delete", flag.ExitOnError),
+		ConditionList:     flag.NewFlagSet("list", flag.ExitOnError),
+		ConditionUpdate:   flag.NewFlagSet("update", flag.ExitOnError),
+		Concurrency:       flag.Int("concurrency", vcl.DefaultConcurrency, "number of vcl files processed at the same time"),
+		Debug:             flag.Bool("debug", false, "show any error output + debug logs"),
+		Delete:            flag.NewFlagSet("delete", flag.ExitOnError),
+		Deploy:            flag.NewFlagSet("deploy", flag.ExitOnError),
+		DictionaryCreate:  flag.NewFlagSet("create", flag.ExitOnError),
+		DictionaryDelete:  flag.NewFlagSet("delete", flag.ExitOnError),
+		DictionaryList:    flag.NewFlagSet("list", flag.ExitOnError),
+		Diff:              flag.NewFlagSet("diff", flag.ExitOnError),
+		DirectorCreate:    flag.NewFlagSet("create", flag.ExitOnError),
+		DirectorDelete:    flag.NewFlagSet("delete", flag.ExitOnError),
+		DirectorList:      flag.NewFlagSet("list", flag.ExitOnError),
+		DirectorUpdate:    flag.NewFlagSet("update", flag.ExitOnError),
+		Directory:         flag.String("dir", "", "vcl directory to compare files against (fallback: VCL_DIRECTORY)"),
+		DomainAdd:         flag.NewFlagSet("add", flag.ExitOnError),
+		DomainCheck:       flag.NewFlagSet("check", flag.ExitOnError),
+		DomainList:        flag.NewFlagSet("list", flag.ExitOnError),
+		DomainRemove:      flag.NewFlagSet("remove", flag.ExitOnError),
+		DryRun:            flag.Bool("dry-run", false, "show what upload, sync, delete and activate would change without changing anything"),
+		EntryAdd:          flag.NewFlagSet("add", flag.ExitOnError),
+		EntryList:         flag.NewFlagSet("list", flag.ExitOnError),
+		EntryRemove:       flag.NewFlagSet("remove", flag.ExitOnError),
+		EntrySync:         flag.NewFlagSet("sync", flag.ExitOnError),
+		Env:               flag.String("env", "", "select a named environment from the "+config.FileName+" file"),
+		Force:             flag.Bool("force", false, "allow activating a version of a service listed as protected in the "+config.FileName+" file"),
+		HealthCheckCreate: flag.NewFlagSet("create", flag.ExitOnError),
+		HealthCheckDelete: flag.NewFlagSet("delete", flag.ExitOnError),
+		HealthCheckList:   flag.NewFlagSet("list", flag.ExitOnError),
+		HealthCheckUpdate: flag.NewFlagSet("update", flag.ExitOnError),
+		Help:              flag.Bool("help", false, "show available flags"),
+		HelpShort:         flag.Bool("h", false, "show available flags"),
+		ItemDelete:        flag.NewFlagSet("delete", flag.ExitOnError),
+		ItemGet:           flag.NewFlagSet("get", flag.ExitOnError),
+		ItemImport:        flag.NewFlagSet("import", flag.ExitOnError),
+		ItemList:          flag.NewFlagSet("list", flag.ExitOnError),
+		ItemSet:           flag.NewFlagSet("set", flag.ExitOnError),
+		List:              flag.NewFlagSet("list", flag.ExitOnError),
+		Match:             flag.String("match", "", "regex for matching vcl directories (will also try: VCL_MATCH_PATH)"),
+		Output:            flag.String("output", "text", "output format: text, json or yaml"),
+		Profile:           flag.String("profile", "", "select the stored credentials profile (fallback: FASTLY_PROFILE, see: fastly auth login)"),
+		Rollback:          flag.NewFlagSet("rollback", flag.ExitOnError),
+		Service:           flag.String("service", "", "your service id, comma separated ids or a group name (fallback: FASTLY_SERVICE_ID)"),
+		Settings:          flag.String("settings", "", "deprecated: use the version settings command"),
+		Skip:              flag.String("skip", "^____", "regex for skipping vcl directories (will also try: VCL_SKIP_PATH)"),
+		SnippetDelete:     flag.NewFlagSet("delete", flag.ExitOnError),
+		SnippetDiff:       flag.NewFlagSet("diff", flag.ExitOnError),
+		SnippetGet:        flag.NewFlagSet("get", flag.ExitOnError),
+		SnippetList:       flag.NewFlagSet("list", flag.ExitOnError),
+		SnippetUpload:     flag.NewFlagSet("upload", flag.ExitOnError),
+		Status:            flag.String("status", "", "deprecated: use the version status command"),
+		Sync:              flag.NewFlagSet("sync", flag.ExitOnError),
+		Token:             flag.String("token", "", "your fastly api token (fallback: FASTLY_API_TOKEN)"),
+		Upload:            flag.NewFlagSet("upload", flag.ExitOnError),
+		Validate:          flag.String("validate", "", "deprecated: use the version validate command"),
+		Version:           flag.Bool("version", false, "show application version"),
+		Yes:               flag.Bool("yes", false, "activate the version without asking for confirmation"),
 	}
 
-	flag.Parse()
-
 	return Flags{
 		Top: topLevelFlags,
 		Sub: subCommands(topLevelFlags),
 	}
 }
 
+// itemVersionUsage describes the -version flag of the dictionary items
+// commands, the items aren't versioned so it only selects the dictionary
+const itemVersionUsage = "specify Fastly service version to find the dictionary in (default: the active version)"
+
+// entryVersionUsage describes the -version flag of the ACL entries commands,
+// the entries aren't versioned so it only selects the ACL
+const entryVersionUsage = "specify Fastly service version to find the ACL in (default: the active version)"
+
 func subCommands(t TopLevelFlags) SubCommandFlags {
 	return SubCommandFlags{
-		CloneVersion:     t.Upload.String("clone", "", "specify Fastly service version to clone from before uploading to"),
-		UploadVersion:    t.Upload.String("version", "", "specify non-active Fastly service 'version' to upload to"),
-		UseLatestVersion: t.Upload.Bool("latest", false, "use latest Fastly service version to upload to (presumes not activated)"),
-		VclDeleteVersion: t.Delete.String("version", "", "specify Fastly service version to delete VCL file from"),
-		VclListVersion:   t.List.String("version", "", "specify Fastly service version to list VCL files from"),
-		VclName:          t.Delete.String("name", "", "specify VCL filename to delete"),
-		VclVersion:       t.Diff.String("version", "", "specify Fastly service version to verify against"),
+		ACLCreate:                 targetFlags(t.ACLCreate, "creating the ACL"),
+		ACLDelete:                 targetFlags(t.ACLDelete, "deleting the ACL"),
+		ACLListVersion:            t.ACLList.String("version", "", "specify Fastly service version to list the ACLs from"),
+		AuthHelper:                t.Auth.String("helper", "", "external command used to store the token instead of the credentials file (e.g. a keyring script)"),
+		BackendCreate:             targetFlags(t.BackendCreate, "creating the backend"),
+		BackendCreateSettings:     backendFlags(t.BackendCreate),
+		BackendDelete:             targetFlags(t.BackendDelete, "deleting the backend"),
+		BackendListVersion:        t.BackendList.String("version", "", "specify Fastly service version to list the backends from"),
+		BackendShowVersion:        t.BackendShow.String("version", "", "specify Fastly service version to show the backend from"),
+		BackendUpdate:             targetFlags(t.BackendUpdate, "updating the backend"),
+		BackendUpdateSettings:     backendFlags(t.BackendUpdate),
+		CloneVersion:              t.Upload.String("clone", "", "specify Fastly service version to clone from before uploading to"),
+		ConditionCreate:           targetFlags(t.ConditionCreate, "creating the condition"),
+		ConditionCreateSettings:   conditionFlags(t.ConditionCreate),
+		ConditionDelete:           targetFlags(t.ConditionDelete, "deleting the condition"),
+		ConditionListVersion:      t.ConditionList.String("version", "", "specify Fastly service version to list the conditions from"),
+		ConditionUpdate:           targetFlags(t.ConditionUpdate, "updating the condition"),
+		ConditionUpdateSettings:   conditionFlags(t.ConditionUpdate),
+		DeployCloneVersion:        t.Deploy.String("clone", "", "specify Fastly service version to clone from before uploading to"),
+		DeployComment:             t.Deploy.String("comment", vcl.DefaultCommentTemplate, "comment given to the cloned version, a template using: {{.SHA}} {{.ShortSHA}} {{.Branch}} {{.Dirty}} {{.Dir}} {{.User}} {{.Time}} (empty for no comment)"),
+		DeployLatest:              t.Deploy.Bool("latest", false, "use latest Fastly service version to upload to (presumes not activated)"),
+		DeployMainVCL:             t.Deploy.String("main", "", "specify VCL filename to designate as the main VCL (fallback: .fastly-main file in -dir)"),
+		DeployVersion:             t.Deploy.String("version", "", "specify non-active Fastly service 'version' to upload to"),
+		DictionaryCreate:          targetFlags(t.DictionaryCreate, "creating the dictionary"),
+		DictionaryDelete:          targetFlags(t.DictionaryDelete, "deleting the dictionary"),
+		DictionaryListVersion:     t.DictionaryList.String("version", "", "specify Fastly service version to list the dictionaries from"),
+		DictionaryWriteOnly:       t.DictionaryCreate.Bool("write-only", false, "prevent the items of the dictionary from being read back (through the api or this tool)"),
+		DiffContext:               t.Diff.Int("context", diff.DefaultContext, "number of unchanged lines to show around each difference"),
+		DirectorCreate:            targetFlags(t.DirectorCreate, "creating the director"),
+		DirectorCreateSettings:    directorFlags(t.DirectorCreate),
+		DirectorDelete:            targetFlags(t.DirectorDelete, "deleting the director"),
+		DirectorListVersion:       t.DirectorList.String("version", "", "specify Fastly service version to list the directors from"),
+		DirectorUpdate:            targetFlags(t.DirectorUpdate, "updating the director"),
+		DirectorUpdateSettings:    directorFlags(t.DirectorUpdate),
+		DomainAdd:                 targetFlags(t.DomainAdd, "adding the domain"),
+		DomainCheckResolver:       t.DomainCheck.String("resolver", "", "address of a DNS server to resolve the domains with (e.g. 1.1.1.1 or 127.0.0.1:5353), the system resolver is used by default"),
+		DomainCheckVersion:        t.DomainCheck.String("version", "", "specify Fastly service version to check the domains of"),
+		DomainListVersion:         t.DomainList.String("version", "", "specify Fastly service version to list the domains from"),
+		DomainRemove:              targetFlags(t.DomainRemove, "removing the domain"),
+		EntryAddComment:           t.EntryAdd.String("comment", "", "comment given to the entry"),
+		EntryAddVersion:           t.EntryAdd.String("version", "", entryVersionUsage),
+		EntryListVersion:          t.EntryList.String("version", "", entryVersionUsage),
+		EntryRemoveVersion:        t.EntryRemove.String("version", "", entryVersionUsage),
+		EntrySyncConfirm:          t.EntrySync.Bool("yes", false, "apply the sync without asking for confirmation"),
+		EntrySyncVersion:          t.EntrySync.String("version", "", entryVersionUsage),
+		HealthCheckCreate:         targetFlags(t.HealthCheckCreate, "creating the healthcheck"),
+		HealthCheckCreateSettings: healthCheckFlags(t.HealthCheckCreate),
+		HealthCheckDelete:         targetFlags(t.HealthCheckDelete, "deleting the healthcheck"),
+		HealthCheckListVersion:    t.HealthCheckList.String("version", "", "specify Fastly service version to list the healthchecks from"),
+		HealthCheckUpdate:         targetFlags(t.HealthCheckUpdate, "updating the healthcheck"),
+		HealthCheckUpdateSettings: healthCheckFlags(t.HealthCheckUpdate),
+		ItemDeleteVersion:         t.ItemDelete.String("version", "", itemVersionUsage),
+		ItemGetVersion:            t.ItemGet.String("version", "", itemVersionUsage),
+		ItemImportConfirm:         t.ItemImport.Bool("yes", false, "apply the import without asking for confirmation"),
+		ItemImportFormat:          t.ItemImport.String("format", "", "format of the items file: json or csv (default: the file extension)"),
+		ItemImportVersion:         t.ItemImport.String("version", "", itemVersionUsage),
+		ItemListVersion:           t.ItemList.String("version", "", itemVersionUsage),
+		ItemSetVersion:            t.ItemSet.String("version", "", itemVersionUsage),
+		RollbackConfirm:           t.Rollback.Bool("yes", false, "activate the rollback version without asking for confirmation"),
+		RollbackTo:                t.Rollback.String("to", "", "specify Fastly service version to roll back to (default: previously active version)"),
+		SnippetClone:              t.SnippetUpload.String("clone", "", "specify Fastly service version to clone from before uploading the snippets to"),
+		SnippetComment:            t.SnippetUpload.String("comment", vcl.DefaultCommentTemplate, "comment given to the cloned version, a template using: {{.SHA}} {{.ShortSHA}} {{.Branch}} {{.Dirty}} {{.Dir}} {{.User}} {{.Time}} (empty for no comment)"),
+		SnippetConfirm:            t.SnippetUpload.Bool("yes", false, "upload the dynamic snippets without asking for confirmation"),
+		SnippetContext:            t.SnippetDiff.Int("context", diff.DefaultContext, "number of unchanged lines to show around each difference"),
+		SnippetDelVersion:         t.SnippetDelete.String("version", "", "specify Fastly service version to delete the snippet from"),
+		SnippetDiffVersion:        t.SnippetDiff.String("version", "", "specify Fastly service version to compare the snippets against"),
+		SnippetDynamic:            t.SnippetUpload.Bool("dynamic", false, "update the content of the dynamic snippets in place (no new version, the changes are live immediately)"),
+		SnippetGetVersion:         t.SnippetGet.String("version", "", "specify Fastly service version to get the snippet from"),
+		SnippetLatest:             t.SnippetUpload.Bool("latest", false, "use latest Fastly service version to upload the snippets to (presumes not activated)"),
+		SnippetListVersion:        t.SnippetList.String("version", "", "specify Fastly service version to list the snippets from"),
+		SnippetVersion:            t.SnippetUpload.String("version", "", "specify non-active Fastly service 'version' to upload the snippets to (or with -dynamic, to look them up in)"),
+		SyncCloneVersion:          t.Sync.String("clone", "", "specify Fastly service version to clone from before syncing to"),
+		SyncComment:               t.Sync.String("comment", vcl.DefaultCommentTemplate, "comment given to the cloned version, a template using: {{.SHA}} {{.ShortSHA}} {{.Branch}} {{.Dirty}} {{.Dir}} {{.User}} {{.Time}} (empty for no comment)"),
+		SyncConfirm:               t.Sync.Bool("yes", false, "apply the sync plan without asking for confirmation"),
+		SyncDeleteMatch:           t.Sync.String("delete-match", "", "regex for matching the names of the remote vcl files that can be deleted (required to delete any with -match or -skip)"),
+		SyncLatest:                t.Sync.Bool("latest", false, "use latest Fastly service version to sync to (presumes not activated)"),
+		SyncMainVCL:               t.Sync.String("main", "", "specify VCL filename to designate as the main VCL (fallback: .fastly-main file in -dir)"),
+		SyncVersion:               t.Sync.String("version", "", "specify non-active Fastly service 'version' to sync to"),
+		MainVCL:                   t.Upload.String("main", "", "specify VCL filename to designate as the main VCL (fallback: .fastly-main file in -dir)"),
+		UploadComment:             t.Upload.String("comment", vcl.DefaultCommentTemplate, "comment given to the cloned version, a template using: {{.SHA}} {{.ShortSHA}} {{.Branch}} {{.Dirty}} {{.Dir}} {{.User}} {{.Time}} (empty for no comment)"),
+		UploadVersion:             t.Upload.String("version", "", "specify non-active Fastly service 'version' to upload to"),
+		UseLatestVersion:          t.Upload.Bool("latest", false, "use latest Fastly service version to upload to (presumes not activated)"),
+		VclDeleteVersion:          t.Delete.String("version", "", "specify Fastly service version to delete VCL file from"),
+		VclListVersion:            t.List.String("version", "", "specify Fastly service version to list VCL files from"),
+		VclName:                   t.Delete.String("name", "", "specify VCL filename to delete"),
+		VclVersion:                t.Diff.String("version", "", "specify Fastly service version to verify against"),
+	}
+}
+
+// targetFlags defines the flags of a command that changes the service's
+// configuration, describing the change as e.g. "creating the dictionary"
+func targetFlags(fs *flag.FlagSet, change string) TargetFlags {
+	return TargetFlags{
+		Clone:   fs.String("clone", "", "specify Fastly service version to clone from before "+change),
+		Comment: fs.String("comment", vcl.DefaultCommentTemplate, "comment given to the cloned version, a template using: {{.SHA}} {{.ShortSHA}} {{.Branch}} {{.Dirty}} {{.Dir}} {{.User}} {{.Time}} (empty for no comment)"),
+		Latest:  fs.Bool("latest", false, "use latest Fastly service version for "+change+" (presumes not activated)"),
+		Version: fs.String("version", "", "specify non-active Fastly service 'version' for "+change),
+	}
+}
+
+// backendFlags defines the settings of a backend
+func backendFlags(fs *flag.FlagSet) BackendFlags {
+	return BackendFlags{
+		Address:             fs.String("address", "", "hostname or ip address of the backend"),
+		AutoLoadbalance:     fs.Bool("auto-loadbalance", false, "include the backend in the automatic load balancing"),
+		BetweenBytesTimeout: fs.Uint("between-bytes-timeout", 0, "milliseconds to wait between bytes of the response"),
+		ConnectTimeout:      fs.Uint("connect-timeout", 0, "milliseconds to wait for a connection to the backend"),
+		FirstByteTimeout:    fs.Uint("first-byte-timeout", 0, "milliseconds to wait for the first byte of the response"),
+		HealthCheck:         fs.String("healthcheck", "", "name of the healthcheck used to check the backend"),
+		MaxConn:             fs.Uint("max-conn", 0, "maximum number of connections to the backend"),
+		MaxTLSVersion:       fs.String("max-tls-version", "", "maximum TLS version of the connection: "+strings.Join(vcl.TLSVersions, ", ")),
+		MinTLSVersion:       fs.String("min-tls-version", "", "minimum TLS version of the connection: "+strings.Join(vcl.TLSVersions, ", ")),
+		OverrideHost:        fs.String("override-host", "", "hostname sent to the backend in the Host header"),
+		Port:                fs.Uint("port", 0, "port of the backend (default: 80)"),
+		RequestCondition:    fs.String("request-condition", "", "name of the request condition that selects the backend"),
+		Shield:              fs.String("shield", "", "shield POP of the backend (e.g. lhr-uk)"),
+		SSLCertHostname:     fs.String("ssl-cert-hostname", "", "hostname the backend's certificate is checked against"),
+		SSLCheckCert:        fs.Bool("ssl-check-cert", false, "check the backend's certificate is valid"),
+		SSLSNIHostname:      fs.String("ssl-sni-hostname", "", "hostname sent to the backend with SNI"),
+		UseSSL:              fs.Bool("use-ssl", false, "connect to the backend with TLS"),
+		Weight:              fs.Uint("weight", 0, "weight of the backend when load balancing"),
+	}
+}
+
+// healthCheckFlags defines the settings of a healthcheck
+func healthCheckFlags(fs *flag.FlagSet) HealthCheckFlags {
+	return HealthCheckFlags{
+		CheckInterval:    fs.Uint("check-interval", 0, "milliseconds between each check"),
+		ExpectedResponse: fs.Uint("expected-response", 0, "status code of a healthy response (default: 200)"),
+		Host:             fs.String("host", "", "Host header sent with the check"),
+		HTTPVersion:      fs.String("http-version", "", "HTTP version of the check: 1.0 or 1.1"),
+		Initial:          fs.Uint("initial", 0, "number of checks assumed to have passed when the version is activated"),
+		Method:           fs.String("method", "", "HTTP method of the check (e.g. HEAD)"),
+		Path:             fs.String("path", "", "path requested by the check"),
+		Threshold:        fs.Uint("threshold", 0, "number of the last -window checks that must pass for the backend to be healthy"),
+		Timeout:          fs.Uint("timeout", 0, "milliseconds to wait for the response of a check"),
+		Window:           fs.Uint("window", 0, "number of the most recent checks considered"),
+	}
+}
+
+// directorFlags defines the settings of a director
+func directorFlags(fs *flag.FlagSet) DirectorFlags {
+	return DirectorFlags{
+		Backends: fs.String("backends", "", "comma separated names of the backends of the director (replacing its backends)"),
+		Quorum:   fs.Uint("quorum", 0, "percentage of the backends that must be healthy for the director to be healthy"),
+		Retries:  fs.Uint("retries", 0, "number of backends to try before failing the request"),
+		Type:     fs.String("type", "", "how the director selects a backend: "+strings.Join(vcl.DirectorTypes, ", ")+" (default: random)"),
+	}
+}
+
+// conditionFlags defines the settings of a condition
+func conditionFlags(fs *flag.FlagSet) ConditionFlags {
+	return ConditionFlags{
+		Priority:  fs.Int("priority", 0, "order the conditions of the same type are evaluated in (lowest first)"),
+		Statement: fs.String("statement", "", "VCL statement of the condition (e.g. req.url ~ \"^/api/\")"),
+		Type:      fs.String("type", "", "where the condition is evaluated: "+strings.Join(vcl.ConditionTypes, ", ")+" (default: REQUEST)"),
 	}
 }
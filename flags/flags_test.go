@@ -0,0 +1,19 @@
+package flags
+
+import "testing"
+
+func TestProvided(t *testing.T) {
+	f := New()
+
+	if err := f.Top.Upload.Parse([]string{"-main", "entry", "-version", "3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	provided := f.Provided()
+	if provided["main"] != "entry" {
+		t.Errorf("got main %q, want %q", provided["main"], "entry")
+	}
+	if _, ok := provided["service"]; ok {
+		t.Error("a top level flag that wasn't provided was included")
+	}
+}
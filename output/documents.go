@@ -0,0 +1,371 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// ErrorDocument is written when a command fails before producing a result
+type ErrorDocument struct {
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+	Error   string `json:"error" yaml:"error"`
+}
+
+// FileDocument describes the outcome for a single VCL file
+//
+// Status is one of: created, updated, deleted, failed (upload/delete),
+// same, different, failed (diff) or create, update, delete (plans)
+type FileDocument struct {
+	Name    string `json:"name" yaml:"name"`
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`
+	Status  string `json:"status" yaml:"status"`
+	Added   int    `json:"added,omitempty" yaml:"added,omitempty"`
+	Removed int    `json:"removed,omitempty" yaml:"removed,omitempty"`
+	Diff    string `json:"diff,omitempty" yaml:"diff,omitempty"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ListDocument is the structured form of the list command
+type ListDocument struct {
+	Service string         `json:"service" yaml:"service"`
+	Version int            `json:"version" yaml:"version"`
+	Files   []ListFileItem `json:"files" yaml:"files"`
+}
+
+// ListFileItem is a single remote VCL file
+type ListFileItem struct {
+	Name string `json:"name" yaml:"name"`
+	Main bool   `json:"main" yaml:"main"`
+}
+
+// DiffDocument is the structured form of the diff command
+type DiffDocument struct {
+	Service     string         `json:"service" yaml:"service"`
+	Version     int            `json:"version" yaml:"version"`
+	Differences int            `json:"differences" yaml:"differences"`
+	Failed      int            `json:"failed" yaml:"failed"`
+	Files       []FileDocument `json:"files" yaml:"files"`
+}
+
+// UploadDocument is the structured form of the upload (and deploy and sync)
+// command
+type UploadDocument struct {
+	Service    string         `json:"service" yaml:"service"`
+	Version    int            `json:"version" yaml:"version"`
+	ClonedFrom int            `json:"cloned_from,omitempty" yaml:"cloned_from,omitempty"`
+	Comment    string         `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Files      []FileDocument `json:"files" yaml:"files"`
+	Failed     int            `json:"failed" yaml:"failed"`
+	Main       string         `json:"main,omitempty" yaml:"main,omitempty"`
+	MainError  string         `json:"main_error,omitempty" yaml:"main_error,omitempty"`
+}
+
+// DeleteDocument is the structured form of the delete command
+type DeleteDocument struct {
+	Service string `json:"service" yaml:"service"`
+	Version int    `json:"version" yaml:"version"`
+	Name    string `json:"name" yaml:"name"`
+}
+
+// PlanDocument is the structured form of a dry run
+type PlanDocument struct {
+	Service string         `json:"service" yaml:"service"`
+	Version int            `json:"version" yaml:"version"`
+	Clone   bool           `json:"clone" yaml:"clone"`
+	Comment string         `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Main    string         `json:"main,omitempty" yaml:"main,omitempty"`
+	Files   []FileDocument `json:"files" yaml:"files"`
+}
+
+// DeployDocument is the structured form of the deploy command
+type DeployDocument struct {
+	Upload    *UploadDocument   `json:"upload,omitempty" yaml:"upload,omitempty"`
+	Validate  *ValidateDocument `json:"validate,omitempty" yaml:"validate,omitempty"`
+	Activated bool              `json:"activated" yaml:"activated"`
+	Error     string            `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ValidateDocument is the structured form of the -validate flag
+type ValidateDocument struct {
+	Service string `json:"service" yaml:"service"`
+	Version int    `json:"version" yaml:"version"`
+	Valid   bool   `json:"valid" yaml:"valid"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// StatusDocument is the structured form of the -status flag
+type StatusDocument struct {
+	Service string `json:"service" yaml:"service"`
+	Version int    `json:"version" yaml:"version"`
+	Active  bool   `json:"active" yaml:"active"`
+}
+
+// SettingsDocument is the structured form of the -settings flag
+type SettingsDocument struct {
+	Service     string `json:"service" yaml:"service"`
+	Version     int    `json:"version" yaml:"version"`
+	DefaultHost string `json:"default_host" yaml:"default_host"`
+	DefaultTTL  uint   `json:"default_ttl" yaml:"default_ttl"`
+}
+
+// ActivateDocument is the structured form of the -activate flag
+// Changed lists the VCL files that differ from the previous version
+type ActivateDocument struct {
+	Service  string   `json:"service" yaml:"service"`
+	Version  int      `json:"version" yaml:"version"`
+	Previous int      `json:"previous,omitempty" yaml:"previous,omitempty"`
+	Comment  string   `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Locked   bool     `json:"locked" yaml:"locked"`
+	Valid    bool     `json:"valid" yaml:"valid"`
+	Message  string   `json:"message,omitempty" yaml:"message,omitempty"`
+	Changed  []string `json:"changed" yaml:"changed"`
+	DryRun   bool     `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// TargetItem is the version a change was (or would be) made to
+type TargetItem struct {
+	Version    int    `json:"version" yaml:"version"`
+	Clone      bool   `json:"clone,omitempty" yaml:"clone,omitempty"`
+	ClonedFrom int    `json:"cloned_from,omitempty" yaml:"cloned_from,omitempty"`
+	Comment    string `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// Error builds the document for a command that failed
+func Error(err error) ErrorDocument {
+	return ErrorDocument{Error: errorString(err)}
+}
+
+// List builds the document for the list command
+func List(r *vcl.ListResult) ListDocument {
+	doc := ListDocument{
+		Service: r.Service,
+		Version: r.Version,
+		Files:   []ListFileItem{},
+	}
+
+	for _, file := range r.Files {
+		doc.Files = append(doc.Files, ListFileItem{Name: file.Name, Main: file.Main})
+	}
+
+	return doc
+}
+
+// Diff builds the document for the diff command
+func Diff(r *vcl.DiffResult) DiffDocument {
+	doc := DiffDocument{
+		Service: r.Service,
+		Version: r.Version,
+		Failed:  len(r.Failed),
+		Files:   []FileDocument{},
+	}
+
+	for _, fd := range r.Files {
+		file := FileDocument{
+			Name: fd.Name,
+			Path: fd.Path,
+		}
+
+		switch {
+		case fd.Err != nil:
+			file.Status = "failed"
+			file.Error = errorString(fd.Err)
+		case fd.Result.Equal():
+			file.Status = "same"
+		default:
+			doc.Differences++
+			file.Status = "different"
+			file.Added = fd.Result.Added
+			file.Removed = fd.Result.Removed
+			file.Diff = fd.Unified(r.Version)
+		}
+
+		doc.Files = append(doc.Files, file)
+	}
+
+	return doc
+}
+
+// Upload builds the document for the upload command
+func Upload(r *vcl.UploadResult) UploadDocument {
+	doc := UploadDocument{
+		Service:    r.Service,
+		Version:    r.Version,
+		ClonedFrom: r.ClonedFrom,
+		Comment:    r.Comment,
+		Files:      []FileDocument{},
+		Failed:     len(r.Failed),
+		Main:       r.Main,
+		MainError:  errorString(r.MainErr),
+	}
+
+	for _, fr := range r.Files {
+		file := FileDocument{
+			Name:  fr.Name,
+			Path:  fr.Path,
+			Error: errorString(fr.Err),
+		}
+
+		switch {
+		case fr.Err != nil:
+			file.Status = "failed"
+		case fr.Deleted:
+			file.Status = "deleted"
+		case fr.Created:
+			file.Status = "created"
+		default:
+			file.Status = "updated"
+		}
+
+		doc.Files = append(doc.Files, file)
+	}
+
+	return doc
+}
+
+// Delete builds the document for the delete command
+func Delete(r *vcl.DeleteResult) DeleteDocument {
+	return DeleteDocument{
+		Service: r.Service,
+		Version: r.Version,
+		Name:    r.Name,
+	}
+}
+
+// Sync builds the document of an applied sync, which has the same form as
+// an upload (the deleted files have the "deleted" status)
+func Sync(r *vcl.SyncResult) UploadDocument {
+	return Upload((*vcl.UploadResult)(r))
+}
+
+// Plan builds the document for a dry run
+func Plan(p *vcl.Plan) PlanDocument {
+	doc := PlanDocument{
+		Service: p.Service,
+		Version: p.Version,
+		Clone:   p.Clone,
+		Comment: p.Comment,
+		Main:    p.Main,
+		Files:   []FileDocument{},
+	}
+
+	add := func(files []vcl.PlannedFile, status string) {
+		for _, file := range files {
+			local := file.Path
+			if local == "" {
+				local = "/dev/null"
+			}
+
+			doc.Files = append(doc.Files, FileDocument{
+				Name:    file.Name,
+				Path:    file.Path,
+				Status:  status,
+				Added:   file.Diff.Added,
+				Removed: file.Diff.Removed,
+				Diff:    file.Diff.Unified(fmt.Sprintf("%s (version %d)", file.Name, p.Version), local),
+			})
+		}
+	}
+
+	add(p.Create, "create")
+	add(p.Update, "update")
+	add(p.Delete, "delete")
+
+	return doc
+}
+
+// Deploy builds the document for the deploy command
+func Deploy(r *vcl.DeployResult, err error) DeployDocument {
+	doc := DeployDocument{
+		Activated: r.Activate != nil,
+		Error:     errorString(err),
+	}
+
+	if r.Upload != nil {
+		upload := Upload(r.Upload)
+		doc.Upload = &upload
+	}
+
+	if r.Validate != nil {
+		validate := Validate(r.Validate)
+		doc.Validate = &validate
+	}
+
+	return doc
+}
+
+// Validate builds the document for the -validate flag
+func Validate(r *vcl.ValidateResult) ValidateDocument {
+	return ValidateDocument{
+		Service: r.Service,
+		Version: r.Version,
+		Valid:   r.Valid,
+		Message: r.Message,
+	}
+}
+
+// Status builds the document for the -status flag
+func Status(r *vcl.StatusResult) StatusDocument {
+	return StatusDocument{
+		Service: r.Service,
+		Version: r.Version,
+		Active:  r.Active,
+	}
+}
+
+// Settings builds the document for the -settings flag
+func Settings(r *vcl.SettingsResult) SettingsDocument {
+	return SettingsDocument{
+		Service:     r.Service,
+		Version:     r.Version,
+		DefaultHost: r.DefaultHost,
+		DefaultTTL:  r.DefaultTTL,
+	}
+}
+
+// Activate builds the document for the -activate flag from the plan that was
+// confirmed and the version that was then activated
+func Activate(p *vcl.ActivatePlan, r *vcl.ActivateResult) ActivateDocument {
+	doc := ActivatePlan(p)
+	doc.Version = r.Version
+	doc.DryRun = false
+	return doc
+}
+
+// ActivatePlan builds the document for an -activate dry run
+func ActivatePlan(p *vcl.ActivatePlan) ActivateDocument {
+	changed := []string{}
+	for _, vd := range p.Files {
+		changed = append(changed, vd.Name)
+	}
+
+	return ActivateDocument{
+		Service:  p.Service,
+		Version:  p.Version,
+		Previous: p.Active,
+		Comment:  p.Comment,
+		Locked:   p.Locked,
+		Valid:    p.Valid,
+		Message:  p.Message,
+		Changed:  changed,
+		DryRun:   true,
+	}
+}
+
+// Target builds the version of a change made by a command
+func Target(tv vcl.TargetVersion) TargetItem {
+	return TargetItem{
+		Version:    tv.Version,
+		Clone:      tv.Clone,
+		ClonedFrom: tv.ClonedFrom,
+		Comment:    tv.Comment,
+	}
+}
+
+func warnings(problems []vcl.Problem) []string {
+	items := []string{}
+	for _, p := range problems {
+		items = append(items, p.String())
+	}
+	return items
+}
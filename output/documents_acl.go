@@ -0,0 +1,165 @@
+package output
+
+import "github.com/integralist/go-fastly-cli/pkg/vcl"
+
+// ACLItem is a single ACL
+type ACLItem struct {
+	Name string `json:"name" yaml:"name"`
+	ID   string `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// ACLsDocument is the structured form of the acl list command
+type ACLsDocument struct {
+	Service string    `json:"service" yaml:"service"`
+	Version int       `json:"version" yaml:"version"`
+	ACLs    []ACLItem `json:"acls" yaml:"acls"`
+}
+
+// ACLDocument is the structured form of the acl create and delete commands
+type ACLDocument struct {
+	Service    string `json:"service" yaml:"service"`
+	TargetItem `yaml:",inline"`
+	ACLItem    `yaml:",inline"`
+	DryRun     bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// EntryItem is a single ACL entry, Entry is its address (prefixed with ! when
+// negated)
+type EntryItem struct {
+	Entry   string `json:"entry" yaml:"entry"`
+	IP      string `json:"ip" yaml:"ip"`
+	Subnet  string `json:"subnet,omitempty" yaml:"subnet,omitempty"`
+	Negated bool   `json:"negated" yaml:"negated"`
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	ID      string `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// EntriesDocument is the structured form of the acl entries list command
+type EntriesDocument struct {
+	Service string      `json:"service" yaml:"service"`
+	Version int         `json:"version" yaml:"version"`
+	ACL     string      `json:"acl" yaml:"acl"`
+	Entries []EntryItem `json:"entries" yaml:"entries"`
+}
+
+// EntryDocument is the structured form of the acl entries add and remove
+// commands
+type EntryDocument struct {
+	Service   string `json:"service" yaml:"service"`
+	Version   int    `json:"version" yaml:"version"`
+	ACL       string `json:"acl" yaml:"acl"`
+	EntryItem `yaml:",inline"`
+	Removed   bool `json:"removed,omitempty" yaml:"removed,omitempty"`
+	DryRun    bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// EntryUpdateItem is an ACL entry whose negation or comment is updated
+type EntryUpdateItem struct {
+	Old EntryItem `json:"old" yaml:"old"`
+	New EntryItem `json:"new" yaml:"new"`
+}
+
+// ACLSyncDocument is the structured form of the acl entries sync command,
+// Batches is only included once the sync was applied
+type ACLSyncDocument struct {
+	Service   string            `json:"service" yaml:"service"`
+	Version   int               `json:"version" yaml:"version"`
+	ACL       string            `json:"acl" yaml:"acl"`
+	Add       []EntryItem       `json:"add" yaml:"add"`
+	Update    []EntryUpdateItem `json:"update" yaml:"update"`
+	Remove    []EntryItem       `json:"remove" yaml:"remove"`
+	Unchanged int               `json:"unchanged" yaml:"unchanged"`
+	Warnings  []string          `json:"warnings" yaml:"warnings"`
+	Batches   int               `json:"batches,omitempty" yaml:"batches,omitempty"`
+	Error     string            `json:"error,omitempty" yaml:"error,omitempty"`
+	DryRun    bool              `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// ACLs builds the document for the acl list command
+func ACLs(r *vcl.ACLsResult) ACLsDocument {
+	doc := ACLsDocument{
+		Service: r.Service,
+		Version: r.Version,
+		ACLs:    []ACLItem{},
+	}
+
+	for _, acl := range r.ACLs {
+		doc.ACLs = append(doc.ACLs, ACLItem{Name: acl.Name, ID: acl.ID})
+	}
+
+	return doc
+}
+
+// ACL builds the document for the acl create and delete commands
+func ACL(r *vcl.ACLResult) ACLDocument {
+	return ACLDocument{
+		Service:    r.Service,
+		TargetItem: Target(r.TargetVersion),
+		ACLItem:    ACLItem{Name: r.ACL.Name, ID: r.ACL.ID},
+	}
+}
+
+// ACLEntries builds the document for the acl entries list command
+func ACLEntries(r *vcl.EntriesResult) EntriesDocument {
+	return EntriesDocument{
+		Service: r.Service,
+		Version: r.Version,
+		ACL:     r.ACL.Name,
+		Entries: entryItems(r.Entries),
+	}
+}
+
+// ACLEntry builds the document for the acl entries add and remove commands
+func ACLEntry(r *vcl.EntryResult) EntryDocument {
+	return EntryDocument{
+		Service:   r.Service,
+		Version:   r.Version,
+		ACL:       r.ACL.Name,
+		EntryItem: entryItem(r.Entry),
+	}
+}
+
+// ACLSync builds the document for the acl entries sync command
+// the result is nil when the sync wasn't applied
+func ACLSync(p *vcl.ACLSyncPlan, r *vcl.ACLSyncResult, err error) ACLSyncDocument {
+	doc := ACLSyncDocument{
+		Service:   p.Service,
+		Version:   p.Version,
+		ACL:       p.ACL.Name,
+		Add:       entryItems(p.Add),
+		Update:    []EntryUpdateItem{},
+		Remove:    entryItems(p.Remove),
+		Unchanged: p.Unchanged,
+		Warnings:  warnings(p.Warnings),
+		Error:     errorString(err),
+	}
+
+	for _, update := range p.Update {
+		doc.Update = append(doc.Update, EntryUpdateItem{Old: entryItem(update.Old), New: entryItem(update.New)})
+	}
+
+	if r != nil {
+		doc.Batches = r.Batches
+	}
+
+	return doc
+}
+
+func entryItems(entries []vcl.ACLEntry) []EntryItem {
+	items := []EntryItem{}
+	for _, entry := range entries {
+		items = append(items, entryItem(entry))
+	}
+	return items
+}
+
+func entryItem(e vcl.ACLEntry) EntryItem {
+	return EntryItem{
+		Entry:   e.String(),
+		IP:      e.IP,
+		Subnet:  e.Subnet,
+		Negated: e.Negated,
+		Comment: e.Comment,
+		ID:      e.ID,
+	}
+}
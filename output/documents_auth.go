@@ -0,0 +1,52 @@
+package output
+
+import (
+	"github.com/integralist/go-fastly-cli/auth"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// TokenDocument is the structured form of the auth login command
+type TokenDocument struct {
+	Profile   string `json:"profile" yaml:"profile"`
+	Name      string `json:"name" yaml:"name"`
+	Scope     string `json:"scope" yaml:"scope"`
+	ExpiresAt string `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+}
+
+// ProfilesDocument is the structured form of the auth list command
+type ProfilesDocument struct {
+	File     string        `json:"file" yaml:"file"`
+	Default  string        `json:"default" yaml:"default"`
+	Profiles []ProfileItem `json:"profiles" yaml:"profiles"`
+}
+
+// ProfileItem is a single stored profile (the token itself is never included)
+type ProfileItem struct {
+	Name   string `json:"name" yaml:"name"`
+	Helper string `json:"helper,omitempty" yaml:"helper,omitempty"`
+}
+
+// Token builds the document for the auth login command
+func Token(profile string, t *api.Token) TokenDocument {
+	return TokenDocument{
+		Profile:   profile,
+		Name:      t.Name,
+		Scope:     t.Scope,
+		ExpiresAt: t.ExpiresAt,
+	}
+}
+
+// Profiles builds the document for the auth list command
+func Profiles(c *auth.Credentials) ProfilesDocument {
+	doc := ProfilesDocument{
+		File:     c.File(),
+		Default:  c.Selected(""),
+		Profiles: []ProfileItem{},
+	}
+
+	for _, name := range c.Names() {
+		doc.Profiles = append(doc.Profiles, ProfileItem{Name: name, Helper: c.Profiles[name].Helper})
+	}
+
+	return doc
+}
@@ -0,0 +1,172 @@
+package output
+
+import "github.com/integralist/go-fastly-cli/pkg/vcl"
+
+// BackendItem is a single backend
+type BackendItem struct {
+	Name                string `json:"name" yaml:"name"`
+	Address             string `json:"address" yaml:"address"`
+	Port                uint   `json:"port" yaml:"port"`
+	OverrideHost        string `json:"override_host,omitempty" yaml:"override_host,omitempty"`
+	UseSSL              bool   `json:"use_ssl" yaml:"use_ssl"`
+	SSLCheckCert        bool   `json:"ssl_check_cert" yaml:"ssl_check_cert"`
+	SSLCertHostname     string `json:"ssl_cert_hostname,omitempty" yaml:"ssl_cert_hostname,omitempty"`
+	SSLSNIHostname      string `json:"ssl_sni_hostname,omitempty" yaml:"ssl_sni_hostname,omitempty"`
+	MinTLSVersion       string `json:"min_tls_version,omitempty" yaml:"min_tls_version,omitempty"`
+	MaxTLSVersion       string `json:"max_tls_version,omitempty" yaml:"max_tls_version,omitempty"`
+	ConnectTimeout      uint   `json:"connect_timeout" yaml:"connect_timeout"`
+	FirstByteTimeout    uint   `json:"first_byte_timeout" yaml:"first_byte_timeout"`
+	BetweenBytesTimeout uint   `json:"between_bytes_timeout" yaml:"between_bytes_timeout"`
+	MaxConn             uint   `json:"max_conn" yaml:"max_conn"`
+	Weight              uint   `json:"weight" yaml:"weight"`
+	AutoLoadbalance     bool   `json:"auto_loadbalance" yaml:"auto_loadbalance"`
+	HealthCheck         string `json:"healthcheck,omitempty" yaml:"healthcheck,omitempty"`
+	RequestCondition    string `json:"request_condition,omitempty" yaml:"request_condition,omitempty"`
+	Shield              string `json:"shield,omitempty" yaml:"shield,omitempty"`
+	Comment             string `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// BackendsDocument is the structured form of the backend list command
+type BackendsDocument struct {
+	Service  string        `json:"service" yaml:"service"`
+	Version  int           `json:"version" yaml:"version"`
+	Backends []BackendItem `json:"backends" yaml:"backends"`
+}
+
+// BackendShowDocument is the structured form of the backend show command
+type BackendShowDocument struct {
+	Service     string `json:"service" yaml:"service"`
+	Version     int    `json:"version" yaml:"version"`
+	BackendItem `yaml:",inline"`
+}
+
+// FieldChangeItem is a backend setting whose value differs
+type FieldChangeItem struct {
+	Field string `json:"field" yaml:"field"`
+	From  string `json:"from" yaml:"from"`
+	To    string `json:"to" yaml:"to"`
+}
+
+// BackendDocument is the structured form of the backend create, update and
+// delete commands
+type BackendDocument struct {
+	Service    string `json:"service" yaml:"service"`
+	TargetItem `yaml:",inline"`
+	Backend    BackendItem       `json:"backend" yaml:"backend"`
+	Changes    []FieldChangeItem `json:"changes" yaml:"changes"`
+	Warnings   []string          `json:"warnings" yaml:"warnings"`
+	DryRun     bool              `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// BackendDiffItem is a backend that differs between two versions, Status is
+// one of: added, removed or changed
+type BackendDiffItem struct {
+	Name    string            `json:"name" yaml:"name"`
+	Status  string            `json:"status" yaml:"status"`
+	Changes []FieldChangeItem `json:"changes" yaml:"changes"`
+}
+
+// BackendDiffDocument is the structured form of the backend diff command
+type BackendDiffDocument struct {
+	Service  string            `json:"service" yaml:"service"`
+	From     int               `json:"from" yaml:"from"`
+	To       int               `json:"to" yaml:"to"`
+	Backends []BackendDiffItem `json:"backends" yaml:"backends"`
+}
+
+// Backends builds the document for the backend list command
+func Backends(r *vcl.BackendsResult) BackendsDocument {
+	doc := BackendsDocument{
+		Service:  r.Service,
+		Version:  r.Version,
+		Backends: []BackendItem{},
+	}
+
+	for _, b := range r.Backends {
+		doc.Backends = append(doc.Backends, backendItem(b))
+	}
+
+	return doc
+}
+
+// BackendShow builds the document for the backend show command
+func BackendShow(r *vcl.BackendShowResult) BackendShowDocument {
+	return BackendShowDocument{
+		Service:     r.Service,
+		Version:     r.Version,
+		BackendItem: backendItem(r.Backend),
+	}
+}
+
+// Backend builds the document for the backend create, update and delete
+// commands
+func Backend(r *vcl.BackendResult) BackendDocument {
+	return BackendDocument{
+		Service:    r.Service,
+		TargetItem: Target(r.TargetVersion),
+		Backend:    backendItem(r.Backend),
+		Changes:    fieldChanges(r.Changes),
+		Warnings:   warnings(r.Warnings),
+	}
+}
+
+// BackendDiff builds the document for the backend diff command
+func BackendDiff(r *vcl.BackendDiffResult) BackendDiffDocument {
+	doc := BackendDiffDocument{
+		Service:  r.Service,
+		From:     r.From,
+		To:       r.To,
+		Backends: []BackendDiffItem{},
+	}
+
+	for _, bd := range r.Backends {
+		status := "changed"
+		switch {
+		case bd.From == nil:
+			status = "added"
+		case bd.To == nil:
+			status = "removed"
+		}
+
+		doc.Backends = append(doc.Backends, BackendDiffItem{
+			Name:    bd.Name,
+			Status:  status,
+			Changes: fieldChanges(bd.Changes),
+		})
+	}
+
+	return doc
+}
+
+func backendItem(b vcl.Backend) BackendItem {
+	return BackendItem{
+		Name:                b.Name,
+		Address:             b.Address,
+		Port:                b.Port,
+		OverrideHost:        b.OverrideHost,
+		UseSSL:              b.UseSSL,
+		SSLCheckCert:        b.SSLCheckCert,
+		SSLCertHostname:     b.SSLCertHostname,
+		SSLSNIHostname:      b.SSLSNIHostname,
+		MinTLSVersion:       b.MinTLSVersion,
+		MaxTLSVersion:       b.MaxTLSVersion,
+		ConnectTimeout:      b.ConnectTimeout,
+		FirstByteTimeout:    b.FirstByteTimeout,
+		BetweenBytesTimeout: b.BetweenBytesTimeout,
+		MaxConn:             b.MaxConn,
+		Weight:              b.Weight,
+		AutoLoadbalance:     b.AutoLoadbalance,
+		HealthCheck:         b.HealthCheck,
+		RequestCondition:    b.RequestCondition,
+		Shield:              b.Shield,
+		Comment:             b.Comment,
+	}
+}
+
+func fieldChanges(changes []vcl.FieldChange) []FieldChangeItem {
+	items := []FieldChangeItem{}
+	for _, change := range changes {
+		items = append(items, FieldChangeItem{Field: change.Field, From: change.From, To: change.To})
+	}
+	return items
+}
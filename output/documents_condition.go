@@ -0,0 +1,67 @@
+package output
+
+import "github.com/integralist/go-fastly-cli/pkg/vcl"
+
+// ConditionItem is a single condition of a service version
+type ConditionItem struct {
+	Name      string `json:"name" yaml:"name"`
+	Type      string `json:"type" yaml:"type"`
+	Statement string `json:"statement" yaml:"statement"`
+	Priority  int    `json:"priority" yaml:"priority"`
+	Comment   string `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// ConditionsDocument is the structured form of the condition list command
+type ConditionsDocument struct {
+	Service    string          `json:"service" yaml:"service"`
+	Version    int             `json:"version" yaml:"version"`
+	Conditions []ConditionItem `json:"conditions" yaml:"conditions"`
+}
+
+// ConditionDocument is the structured form of the condition create, update
+// and delete commands
+type ConditionDocument struct {
+	Service    string `json:"service" yaml:"service"`
+	TargetItem `yaml:",inline"`
+	Condition  ConditionItem `json:"condition" yaml:"condition"`
+	Unchanged  bool          `json:"unchanged,omitempty" yaml:"unchanged,omitempty"`
+	Warnings   []string      `json:"warnings" yaml:"warnings"`
+	DryRun     bool          `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// Conditions builds the document for the condition list command
+func Conditions(r *vcl.ConditionsResult) ConditionsDocument {
+	doc := ConditionsDocument{
+		Service:    r.Service,
+		Version:    r.Version,
+		Conditions: []ConditionItem{},
+	}
+
+	for _, c := range r.Conditions {
+		doc.Conditions = append(doc.Conditions, conditionItem(c))
+	}
+
+	return doc
+}
+
+// Condition builds the document for the condition create, update and delete
+// commands
+func Condition(r *vcl.ConditionResult) ConditionDocument {
+	return ConditionDocument{
+		Service:    r.Service,
+		TargetItem: Target(r.TargetVersion),
+		Condition:  conditionItem(r.Condition),
+		Unchanged:  r.Unchanged,
+		Warnings:   warnings(r.Warnings),
+	}
+}
+
+func conditionItem(c vcl.Condition) ConditionItem {
+	return ConditionItem{
+		Name:      c.Name,
+		Type:      c.Type,
+		Statement: c.Statement,
+		Priority:  c.Priority,
+		Comment:   c.Comment,
+	}
+}
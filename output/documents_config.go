@@ -0,0 +1,39 @@
+package output
+
+import "github.com/integralist/go-fastly-cli/config"
+
+// ConfigDocument is the structured form of the config show command
+type ConfigDocument struct {
+	File     string        `json:"file" yaml:"file"`
+	Env      string        `json:"env" yaml:"env"`
+	Settings []SettingItem `json:"settings" yaml:"settings"`
+}
+
+// SettingItem is a single resolved setting
+type SettingItem struct {
+	Name   string `json:"name" yaml:"name"`
+	Value  string `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+	Detail string `json:"detail,omitempty" yaml:"detail,omitempty"`
+}
+
+// Config builds the document for the config show command
+// settings are passed separately so sensitive values can be masked
+func Config(r *config.Resolved, settings []config.Setting) ConfigDocument {
+	doc := ConfigDocument{
+		File:     r.File,
+		Env:      r.Env,
+		Settings: []SettingItem{},
+	}
+
+	for _, setting := range settings {
+		doc.Settings = append(doc.Settings, SettingItem{
+			Name:   setting.Name,
+			Value:  setting.Value,
+			Source: string(setting.Source),
+			Detail: setting.Detail,
+		})
+	}
+
+	return doc
+}
@@ -0,0 +1,155 @@
+package output
+
+import "github.com/integralist/go-fastly-cli/pkg/vcl"
+
+// DictionaryItem is a single dictionary
+type DictionaryItem struct {
+	Name      string `json:"name" yaml:"name"`
+	ID        string `json:"id,omitempty" yaml:"id,omitempty"`
+	WriteOnly bool   `json:"write_only" yaml:"write_only"`
+}
+
+// DictionariesDocument is the structured form of the dictionary list command
+type DictionariesDocument struct {
+	Service      string           `json:"service" yaml:"service"`
+	Version      int              `json:"version" yaml:"version"`
+	Dictionaries []DictionaryItem `json:"dictionaries" yaml:"dictionaries"`
+}
+
+// DictionaryDocument is the structured form of the dictionary create and
+// delete commands
+type DictionaryDocument struct {
+	Service        string `json:"service" yaml:"service"`
+	TargetItem     `yaml:",inline"`
+	DictionaryItem `yaml:",inline"`
+	DryRun         bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// KeyValueItem is a single dictionary item
+type KeyValueItem struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// DictionaryItemsDocument is the structured form of the dictionary items
+// list command
+type DictionaryItemsDocument struct {
+	Service    string         `json:"service" yaml:"service"`
+	Dictionary string         `json:"dictionary" yaml:"dictionary"`
+	Items      []KeyValueItem `json:"items" yaml:"items"`
+}
+
+// DictionaryKeyDocument is the structured form of the dictionary items get,
+// set and delete commands
+type DictionaryKeyDocument struct {
+	Service      string `json:"service" yaml:"service"`
+	Dictionary   string `json:"dictionary" yaml:"dictionary"`
+	KeyValueItem `yaml:",inline"`
+	Deleted      bool `json:"deleted,omitempty" yaml:"deleted,omitempty"`
+	DryRun       bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// ItemChangeItem is a single item change of an import
+type ItemChangeItem struct {
+	Key string `json:"key" yaml:"key"`
+	Old string `json:"old,omitempty" yaml:"old,omitempty"`
+	New string `json:"new,omitempty" yaml:"new,omitempty"`
+}
+
+// ImportDocument is the structured form of the dictionary items import
+// command, Batches is only included once the import was applied
+type ImportDocument struct {
+	Service    string           `json:"service" yaml:"service"`
+	Dictionary string           `json:"dictionary" yaml:"dictionary"`
+	Add        []ItemChangeItem `json:"add" yaml:"add"`
+	Change     []ItemChangeItem `json:"change" yaml:"change"`
+	Remove     []ItemChangeItem `json:"remove" yaml:"remove"`
+	Unchanged  int              `json:"unchanged" yaml:"unchanged"`
+	Batches    int              `json:"batches,omitempty" yaml:"batches,omitempty"`
+	DryRun     bool             `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// Dictionaries builds the document for the dictionary list command
+func Dictionaries(r *vcl.DictionariesResult) DictionariesDocument {
+	doc := DictionariesDocument{
+		Service:      r.Service,
+		Version:      r.Version,
+		Dictionaries: []DictionaryItem{},
+	}
+
+	for _, dict := range r.Dictionaries {
+		doc.Dictionaries = append(doc.Dictionaries, dictionaryItem(dict))
+	}
+
+	return doc
+}
+
+// Dictionary builds the document for the dictionary create and delete
+// commands
+func Dictionary(r *vcl.DictionaryResult) DictionaryDocument {
+	return DictionaryDocument{
+		Service:        r.Service,
+		TargetItem:     Target(r.TargetVersion),
+		DictionaryItem: dictionaryItem(r.Dictionary),
+	}
+}
+
+// DictionaryItems builds the document for the dictionary items list command
+func DictionaryItems(r *vcl.ItemsResult) DictionaryItemsDocument {
+	doc := DictionaryItemsDocument{
+		Service:    r.Service,
+		Dictionary: r.Dictionary.Name,
+		Items:      []KeyValueItem{},
+	}
+
+	for _, item := range r.Items {
+		doc.Items = append(doc.Items, KeyValueItem{Key: item.Key, Value: item.Value})
+	}
+
+	return doc
+}
+
+// DictionaryKey builds the document for the dictionary items get, set and
+// delete commands
+func DictionaryKey(r *vcl.ItemResult) DictionaryKeyDocument {
+	return DictionaryKeyDocument{
+		Service:      r.Service,
+		Dictionary:   r.Dictionary.Name,
+		KeyValueItem: KeyValueItem{Key: r.Key, Value: r.Value},
+	}
+}
+
+// Import builds the document for the dictionary items import command
+// the result is nil when the import wasn't applied
+func Import(p *vcl.ImportPlan, r *vcl.ImportResult) ImportDocument {
+	doc := ImportDocument{
+		Service:    p.Service,
+		Dictionary: p.Dictionary.Name,
+		Add:        itemChanges(p.Add),
+		Change:     itemChanges(p.Change),
+		Remove:     itemChanges(p.Remove),
+		Unchanged:  p.Unchanged,
+	}
+
+	if r != nil {
+		doc.Batches = r.Batches
+	}
+
+	return doc
+}
+
+func dictionaryItem(d vcl.Dictionary) DictionaryItem {
+	return DictionaryItem{
+		Name:      d.Name,
+		ID:        d.ID,
+		WriteOnly: d.WriteOnly,
+	}
+}
+
+func itemChanges(changes []vcl.ItemChange) []ItemChangeItem {
+	items := []ItemChangeItem{}
+	for _, change := range changes {
+		items = append(items, ItemChangeItem{Key: change.Key, Old: change.Old, New: change.New})
+	}
+	return items
+}
@@ -0,0 +1,74 @@
+package output
+
+import "github.com/integralist/go-fastly-cli/pkg/vcl"
+
+// DirectorItem is a single director of a service version
+type DirectorItem struct {
+	Name     string   `json:"name" yaml:"name"`
+	Type     string   `json:"type" yaml:"type"`
+	Quorum   uint     `json:"quorum" yaml:"quorum"`
+	Retries  uint     `json:"retries" yaml:"retries"`
+	Backends []string `json:"backends" yaml:"backends"`
+	Comment  string   `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// DirectorsDocument is the structured form of the director list command
+type DirectorsDocument struct {
+	Service   string         `json:"service" yaml:"service"`
+	Version   int            `json:"version" yaml:"version"`
+	Directors []DirectorItem `json:"directors" yaml:"directors"`
+}
+
+// DirectorDocument is the structured form of the director create, update and
+// delete commands
+type DirectorDocument struct {
+	Service    string `json:"service" yaml:"service"`
+	TargetItem `yaml:",inline"`
+	Director   DirectorItem `json:"director" yaml:"director"`
+	Unchanged  bool         `json:"unchanged,omitempty" yaml:"unchanged,omitempty"`
+	Warnings   []string     `json:"warnings" yaml:"warnings"`
+	DryRun     bool         `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// Directors builds the document for the director list command
+func Directors(r *vcl.DirectorsResult) DirectorsDocument {
+	doc := DirectorsDocument{
+		Service:   r.Service,
+		Version:   r.Version,
+		Directors: []DirectorItem{},
+	}
+
+	for _, d := range r.Directors {
+		doc.Directors = append(doc.Directors, directorItem(d))
+	}
+
+	return doc
+}
+
+// Director builds the document for the director create, update and delete
+// commands
+func Director(r *vcl.DirectorResult) DirectorDocument {
+	return DirectorDocument{
+		Service:    r.Service,
+		TargetItem: Target(r.TargetVersion),
+		Director:   directorItem(r.Director),
+		Unchanged:  r.Unchanged,
+		Warnings:   warnings(r.Warnings),
+	}
+}
+
+func directorItem(d vcl.Director) DirectorItem {
+	backends := d.Backends
+	if backends == nil {
+		backends = []string{}
+	}
+
+	return DirectorItem{
+		Name:     d.Name,
+		Type:     d.Type,
+		Quorum:   d.Quorum,
+		Retries:  d.Retries,
+		Backends: backends,
+		Comment:  d.Comment,
+	}
+}
@@ -0,0 +1,93 @@
+package output
+
+import "github.com/integralist/go-fastly-cli/pkg/vcl"
+
+// DomainItem is a single domain of a service version
+type DomainItem struct {
+	Name    string `json:"name" yaml:"name"`
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// DomainsDocument is the structured form of the domain list command
+type DomainsDocument struct {
+	Service string       `json:"service" yaml:"service"`
+	Version int          `json:"version" yaml:"version"`
+	Domains []DomainItem `json:"domains" yaml:"domains"`
+}
+
+// DomainDocument is the structured form of the domain add and remove
+// commands
+type DomainDocument struct {
+	Service    string `json:"service" yaml:"service"`
+	TargetItem `yaml:",inline"`
+	Domain     DomainItem `json:"domain" yaml:"domain"`
+	DryRun     bool       `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// DomainCheckItem is the DNS of a domain as seen by Fastly and resolved
+// locally, Status is one of: ok, not-fastly, no-cname, unresolved or wildcard
+type DomainCheckItem struct {
+	Name        string `json:"name" yaml:"name"`
+	Correct     bool   `json:"correct" yaml:"correct"`
+	Status      string `json:"status" yaml:"status"`
+	LocalCNAME  string `json:"local_cname,omitempty" yaml:"local_cname,omitempty"`
+	FastlyCNAME string `json:"fastly_cname,omitempty" yaml:"fastly_cname,omitempty"`
+	FastlyValid bool   `json:"fastly_valid" yaml:"fastly_valid"`
+	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// DomainCheckDocument is the structured form of the domain check command
+type DomainCheckDocument struct {
+	Service  string            `json:"service" yaml:"service"`
+	Version  int               `json:"version" yaml:"version"`
+	Failures int               `json:"failures" yaml:"failures"`
+	Domains  []DomainCheckItem `json:"domains" yaml:"domains"`
+}
+
+// Domains builds the document for the domain list command
+func Domains(r *vcl.DomainsResult) DomainsDocument {
+	doc := DomainsDocument{
+		Service: r.Service,
+		Version: r.Version,
+		Domains: []DomainItem{},
+	}
+
+	for _, d := range r.Domains {
+		doc.Domains = append(doc.Domains, DomainItem{Name: d.Name, Comment: d.Comment})
+	}
+
+	return doc
+}
+
+// Domain builds the document for the domain add and remove commands
+func Domain(r *vcl.DomainResult) DomainDocument {
+	return DomainDocument{
+		Service:    r.Service,
+		TargetItem: Target(r.TargetVersion),
+		Domain:     DomainItem{Name: r.Domain.Name, Comment: r.Domain.Comment},
+	}
+}
+
+// DomainCheck builds the document for the domain check command
+func DomainCheck(r *vcl.DomainCheckResult) DomainCheckDocument {
+	doc := DomainCheckDocument{
+		Service:  r.Service,
+		Version:  r.Version,
+		Failures: r.Failures(),
+		Domains:  []DomainCheckItem{},
+	}
+
+	for _, d := range r.Domains {
+		doc.Domains = append(doc.Domains, DomainCheckItem{
+			Name:        d.Name,
+			Correct:     d.Correct(),
+			Status:      d.Status,
+			LocalCNAME:  d.LocalCNAME,
+			FastlyCNAME: d.FastlyCNAME,
+			FastlyValid: d.FastlyValid,
+			Error:       d.Error,
+		})
+	}
+
+	return doc
+}
@@ -0,0 +1,81 @@
+package output
+
+import "github.com/integralist/go-fastly-cli/pkg/vcl"
+
+// HealthCheckItem is a single healthcheck of a service version
+type HealthCheckItem struct {
+	Name             string `json:"name" yaml:"name"`
+	Method           string `json:"method" yaml:"method"`
+	Host             string `json:"host" yaml:"host"`
+	Path             string `json:"path" yaml:"path"`
+	HTTPVersion      string `json:"http_version" yaml:"http_version"`
+	ExpectedResponse uint   `json:"expected_response" yaml:"expected_response"`
+	Timeout          uint   `json:"timeout" yaml:"timeout"`
+	CheckInterval    uint   `json:"check_interval" yaml:"check_interval"`
+	Window           uint   `json:"window" yaml:"window"`
+	Threshold        uint   `json:"threshold" yaml:"threshold"`
+	Initial          uint   `json:"initial" yaml:"initial"`
+	Comment          string `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// HealthChecksDocument is the structured form of the healthcheck list command
+type HealthChecksDocument struct {
+	Service      string            `json:"service" yaml:"service"`
+	Version      int               `json:"version" yaml:"version"`
+	HealthChecks []HealthCheckItem `json:"healthchecks" yaml:"healthchecks"`
+}
+
+// HealthCheckDocument is the structured form of the healthcheck create,
+// update and delete commands
+type HealthCheckDocument struct {
+	Service     string `json:"service" yaml:"service"`
+	TargetItem  `yaml:",inline"`
+	HealthCheck HealthCheckItem `json:"healthcheck" yaml:"healthcheck"`
+	Unchanged   bool            `json:"unchanged,omitempty" yaml:"unchanged,omitempty"`
+	Warnings    []string        `json:"warnings" yaml:"warnings"`
+	DryRun      bool            `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// HealthChecks builds the document for the healthcheck list command
+func HealthChecks(r *vcl.HealthChecksResult) HealthChecksDocument {
+	doc := HealthChecksDocument{
+		Service:      r.Service,
+		Version:      r.Version,
+		HealthChecks: []HealthCheckItem{},
+	}
+
+	for _, hc := range r.HealthChecks {
+		doc.HealthChecks = append(doc.HealthChecks, healthCheckItem(hc))
+	}
+
+	return doc
+}
+
+// HealthCheck builds the document for the healthcheck create, update and
+// delete commands
+func HealthCheck(r *vcl.HealthCheckResult) HealthCheckDocument {
+	return HealthCheckDocument{
+		Service:     r.Service,
+		TargetItem:  Target(r.TargetVersion),
+		HealthCheck: healthCheckItem(r.HealthCheck),
+		Unchanged:   r.Unchanged,
+		Warnings:    warnings(r.Warnings),
+	}
+}
+
+func healthCheckItem(hc vcl.HealthCheck) HealthCheckItem {
+	return HealthCheckItem{
+		Name:             hc.Name,
+		Method:           hc.Method,
+		Host:             hc.Host,
+		Path:             hc.Path,
+		HTTPVersion:      hc.HTTPVersion,
+		ExpectedResponse: hc.ExpectedResponse,
+		Timeout:          hc.Timeout,
+		CheckInterval:    hc.CheckInterval,
+		Window:           hc.Window,
+		Threshold:        hc.Threshold,
+		Initial:          hc.Initial,
+		Comment:          hc.Comment,
+	}
+}
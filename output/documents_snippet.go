@@ -0,0 +1,224 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// SnippetItem is a single snippet (Content is only included by snippet get)
+type SnippetItem struct {
+	Name     string `json:"name" yaml:"name"`
+	Type     string `json:"type" yaml:"type"`
+	Priority int    `json:"priority" yaml:"priority"`
+	Dynamic  bool   `json:"dynamic" yaml:"dynamic"`
+	ID       string `json:"id,omitempty" yaml:"id,omitempty"`
+	Content  string `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// SnippetsDocument is the structured form of the snippet list command
+type SnippetsDocument struct {
+	Service  string        `json:"service" yaml:"service"`
+	Version  int           `json:"version" yaml:"version"`
+	Snippets []SnippetItem `json:"snippets" yaml:"snippets"`
+}
+
+// SnippetDocument is the structured form of the snippet get and delete commands
+type SnippetDocument struct {
+	Service     string `json:"service" yaml:"service"`
+	Version     int    `json:"version" yaml:"version"`
+	SnippetItem `yaml:",inline"`
+	DryRun      bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// SnippetFileDocument describes the outcome for a single snippet
+//
+// Status is one of: same, different, local_only, remote_only (diff) or
+// create, update, unchanged, skipped (plans)
+type SnippetFileDocument struct {
+	FileDocument `yaml:",inline"`
+	Changes      []string `json:"changes,omitempty" yaml:"changes,omitempty"`
+	Reason       string   `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// SnippetDiffDocument is the structured form of the snippet diff command
+type SnippetDiffDocument struct {
+	Service     string                `json:"service" yaml:"service"`
+	Version     int                   `json:"version" yaml:"version"`
+	Differences int                   `json:"differences" yaml:"differences"`
+	Snippets    []SnippetFileDocument `json:"snippets" yaml:"snippets"`
+}
+
+// SnippetPlanDocument is the structured form of a snippet upload dry run
+type SnippetPlanDocument struct {
+	Service  string                `json:"service" yaml:"service"`
+	Version  int                   `json:"version" yaml:"version"`
+	Clone    bool                  `json:"clone" yaml:"clone"`
+	Comment  string                `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Dynamic  bool                  `json:"dynamic" yaml:"dynamic"`
+	Snippets []SnippetFileDocument `json:"snippets" yaml:"snippets"`
+}
+
+// SnippetUploadDocument is the structured form of the snippet upload command
+type SnippetUploadDocument struct {
+	Service    string         `json:"service" yaml:"service"`
+	Version    int            `json:"version" yaml:"version"`
+	ClonedFrom int            `json:"cloned_from,omitempty" yaml:"cloned_from,omitempty"`
+	Comment    string         `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Dynamic    bool           `json:"dynamic" yaml:"dynamic"`
+	Snippets   []FileDocument `json:"snippets" yaml:"snippets"`
+	Failed     int            `json:"failed" yaml:"failed"`
+}
+
+// Snippets builds the document for the snippet list command
+func Snippets(r *vcl.SnippetsResult) SnippetsDocument {
+	doc := SnippetsDocument{
+		Service:  r.Service,
+		Version:  r.Version,
+		Snippets: []SnippetItem{},
+	}
+
+	for _, snippet := range r.Snippets {
+		item := snippetItem(snippet)
+		item.Content = ""
+		doc.Snippets = append(doc.Snippets, item)
+	}
+
+	return doc
+}
+
+// Snippet builds the document for the snippet get and delete commands
+func Snippet(r *vcl.SnippetResult) SnippetDocument {
+	return SnippetDocument{
+		Service:     r.Service,
+		Version:     r.Version,
+		SnippetItem: snippetItem(r.Snippet),
+	}
+}
+
+// SnippetDiff builds the document for the snippet diff command
+func SnippetDiff(r *vcl.SnippetDiffResult) SnippetDiffDocument {
+	doc := SnippetDiffDocument{
+		Service:     r.Service,
+		Version:     r.Version,
+		Differences: r.Differences(),
+		Snippets:    []SnippetFileDocument{},
+	}
+
+	for _, sd := range r.Snippets {
+		file := SnippetFileDocument{
+			FileDocument: FileDocument{Name: sd.Name},
+			Changes:      sd.Changes,
+		}
+		if sd.Local != nil {
+			file.Path = sd.Local.Path
+		}
+
+		switch {
+		case sd.Equal():
+			file.Status = "same"
+		case sd.Remote == nil:
+			file.Status = "local_only"
+		case sd.Local == nil:
+			file.Status = "remote_only"
+		default:
+			file.Status = "different"
+		}
+
+		if !sd.Result.Equal() {
+			file.Added = sd.Result.Added
+			file.Removed = sd.Result.Removed
+			file.Diff = sd.Unified(r.Version)
+		}
+
+		doc.Snippets = append(doc.Snippets, file)
+	}
+
+	return doc
+}
+
+// SnippetPlan builds the document for a snippet upload dry run
+func SnippetPlan(p *vcl.SnippetPlan) SnippetPlanDocument {
+	doc := SnippetPlanDocument{
+		Service:  p.Service,
+		Version:  p.Version,
+		Clone:    p.Clone,
+		Comment:  p.Comment,
+		Dynamic:  p.Dynamic,
+		Snippets: []SnippetFileDocument{},
+	}
+
+	add := func(snippets []vcl.PlannedSnippet, status string) {
+		for _, planned := range snippets {
+			file := SnippetFileDocument{
+				FileDocument: FileDocument{
+					Name:   planned.Name,
+					Path:   planned.Path,
+					Status: status,
+				},
+				Changes: planned.Changes,
+				Reason:  planned.Reason,
+			}
+
+			if !planned.Diff.Equal() {
+				file.Added = planned.Diff.Added
+				file.Removed = planned.Diff.Removed
+				file.Diff = planned.Diff.Unified(fmt.Sprintf("%s (version %d)", planned.Name, p.Version), planned.Path)
+			}
+
+			doc.Snippets = append(doc.Snippets, file)
+		}
+	}
+
+	add(p.Create, "create")
+	add(p.Update, "update")
+	add(p.Unchanged, "unchanged")
+	add(p.Skipped, "skipped")
+
+	return doc
+}
+
+// SnippetUpload builds the document for the snippet upload command
+func SnippetUpload(r *vcl.SnippetUploadResult) SnippetUploadDocument {
+	doc := SnippetUploadDocument{
+		Service:    r.Service,
+		Version:    r.Version,
+		ClonedFrom: r.ClonedFrom,
+		Comment:    r.Comment,
+		Dynamic:    r.Dynamic,
+		Snippets:   []FileDocument{},
+		Failed:     len(r.Failed),
+	}
+
+	for _, fr := range r.Snippets {
+		file := FileDocument{
+			Name:  fr.Name,
+			Path:  fr.Path,
+			Error: errorString(fr.Err),
+		}
+
+		switch {
+		case fr.Err != nil:
+			file.Status = "failed"
+		case fr.Created:
+			file.Status = "created"
+		default:
+			file.Status = "updated"
+		}
+
+		doc.Snippets = append(doc.Snippets, file)
+	}
+
+	return doc
+}
+
+func snippetItem(s vcl.Snippet) SnippetItem {
+	return SnippetItem{
+		Name:     s.Name,
+		Type:     s.Type,
+		Priority: s.Priority,
+		Dynamic:  s.Dynamic,
+		ID:       s.ID,
+		Content:  s.Content,
+	}
+}
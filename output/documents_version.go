@@ -0,0 +1,96 @@
+package output
+
+import "github.com/integralist/go-fastly-cli/pkg/vcl"
+
+// VersionItem is a single service version
+type VersionItem struct {
+	Version   int    `json:"version" yaml:"version"`
+	Active    bool   `json:"active" yaml:"active"`
+	Locked    bool   `json:"locked" yaml:"locked"`
+	Deployed  bool   `json:"deployed" yaml:"deployed"`
+	Staging   bool   `json:"staging" yaml:"staging"`
+	Testing   bool   `json:"testing" yaml:"testing"`
+	Comment   string `json:"comment" yaml:"comment"`
+	CreatedAt string `json:"created_at" yaml:"created_at"`
+	UpdatedAt string `json:"updated_at" yaml:"updated_at"`
+}
+
+// VersionsDocument is the structured form of the version list command
+type VersionsDocument struct {
+	Service  string        `json:"service" yaml:"service"`
+	Versions []VersionItem `json:"versions" yaml:"versions"`
+}
+
+// VersionDocument is the structured form of the version show, lock and
+// comment commands (Files is only populated by show)
+type VersionDocument struct {
+	Service     string `json:"service" yaml:"service"`
+	VersionItem `yaml:",inline"`
+	Files       []ListFileItem `json:"files,omitempty" yaml:"files,omitempty"`
+	DryRun      bool           `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// CloneDocument is the structured form of the version clone command
+type CloneDocument struct {
+	Service string `json:"service" yaml:"service"`
+	From    int    `json:"from" yaml:"from"`
+	Version int    `json:"version,omitempty" yaml:"version,omitempty"`
+	DryRun  bool   `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// Versions builds the document for the version list command
+func Versions(r *vcl.VersionsResult) VersionsDocument {
+	doc := VersionsDocument{
+		Service:  r.Service,
+		Versions: []VersionItem{},
+	}
+
+	for _, v := range r.Versions {
+		doc.Versions = append(doc.Versions, versionItem(v))
+	}
+
+	return doc
+}
+
+// Version builds the document for the version lock and comment commands
+func Version(r *vcl.VersionResult) VersionDocument {
+	return VersionDocument{
+		Service:     r.Service,
+		VersionItem: versionItem(r.VersionInfo),
+	}
+}
+
+// Describe builds the document for the version show command
+func Describe(r *vcl.DescribeResult) VersionDocument {
+	doc := Version(&r.VersionResult)
+	doc.Files = []ListFileItem{}
+
+	for _, file := range r.Files {
+		doc.Files = append(doc.Files, ListFileItem{Name: file.Name, Main: file.Main})
+	}
+
+	return doc
+}
+
+// Clone builds the document for the version clone command
+func Clone(r *vcl.CloneResult) CloneDocument {
+	return CloneDocument{
+		Service: r.Service,
+		From:    r.From,
+		Version: r.Version,
+	}
+}
+
+func versionItem(v vcl.VersionInfo) VersionItem {
+	return VersionItem{
+		Version:   v.Number,
+		Active:    v.Active,
+		Locked:    v.Locked,
+		Deployed:  v.Deployed,
+		Staging:   v.Staging,
+		Testing:   v.Testing,
+		Comment:   v.Comment,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+}
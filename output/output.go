@@ -0,0 +1,105 @@
+// Output is a package that controls how command results are presented, either
+// as coloured human readable text (the default) or as a single structured
+// (JSON or YAML) document written to stdout.
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/integralist/go-fastly-cli/common"
+	"gopkg.in/yaml.v2"
+)
+
+// Format is the type of output produced by a command
+type Format string
+
+// the supported output formats
+const (
+	Text Format = "text"
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+// the format selected via the -output flag
+var format = Text
+
+// stdout is where structured documents are written
+var stdout io.Writer = os.Stdout
+
+// Parse converts the user provided value into a Format
+func Parse(value string) (Format, error) {
+	switch Format(value) {
+	case Text, JSON, YAML:
+		return Format(value), nil
+	}
+	return "", fmt.Errorf("Unsupported output format '%s' (try: text, json or yaml)", value)
+}
+
+// SetFormat selects the output format for the remainder of the process
+// colours are disabled for structured formats
+func SetFormat(f Format) {
+	format = f
+
+	if Structured() {
+		color.NoColor = true
+	}
+}
+
+// Structured reports whether a structured (i.e. non text) format is selected
+// commands should suppress any human readable text when this is true
+func Structured() bool {
+	return format != Text
+}
+
+// Write renders the document to stdout in the selected structured format
+func Write(doc interface{}) error {
+	var (
+		b   []byte
+		err error
+	)
+
+	switch format {
+	case JSON:
+		b, err = json.MarshalIndent(doc, "", "  ")
+		b = append(b, '\n')
+	case YAML:
+		b, err = yaml.Marshal(doc)
+	default:
+		return fmt.Errorf("output format '%s' is not a structured format", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = stdout.Write(b)
+	return err
+}
+
+// Fail reports the error and then exits with an error code
+// for structured formats an ErrorDocument is written instead of the text
+func Fail(err error) {
+	Failf(err, "%s\n", err)
+}
+
+// Failf is like Fail but prints the formatted text for the Text format
+func Failf(err error, text string, a ...interface{}) {
+	if Structured() {
+		Write(Error(err))
+	} else {
+		fmt.Printf(text, a...)
+	}
+	common.Failure()
+}
+
+// errorString converts an error into a value suitable for a document
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/integralist/go-fastly-cli/common"
+)
+
+// Report is the outcome of running a command against a single service
+// Text prints the human readable form and Doc is the structured form
+type Report struct {
+	Service string
+	Text    func()
+	Doc     interface{}
+	Code    int
+}
+
+// ErrorReport is the report for a command that failed before producing a
+// result, text is the human readable description of the failure
+func ErrorReport(service string, err error, text string, a ...interface{}) Report {
+	return Report{
+		Service: service,
+		Text:    func() { fmt.Printf(text, a...) },
+		Doc:     ErrorDocument{Service: service, Error: errorString(err)},
+		Code:    common.ExitFailure,
+	}
+}
+
+// Services runs the command against every service at the same time, then
+// presents the reports grouped by service (in the order the services were
+// given) and exits with the combined exit code of every service
+//
+// For a single service the report is presented exactly as if the command
+// had been run on its own
+func Services(services []string, command func(service string) Report) {
+	reports := make([]Report, len(services))
+
+	var wg sync.WaitGroup
+	for i, service := range services {
+		wg.Add(1)
+		go func(i int, service string) {
+			defer wg.Done()
+			reports[i] = command(service)
+		}(i, service)
+	}
+	wg.Wait()
+
+	grouped := len(reports) > 1
+	docs := make([]interface{}, 0, len(reports))
+	codes := make([]int, 0, len(reports))
+
+	for _, report := range reports {
+		codes = append(codes, report.Code)
+
+		if Structured() {
+			docs = append(docs, report.Doc)
+			continue
+		}
+
+		if grouped {
+			fmt.Printf("\n=== Service: %s ===\n", common.Yellow(report.Service))
+		}
+		report.Text()
+	}
+
+	if Structured() {
+		if grouped {
+			Write(docs)
+		} else if len(docs) == 1 {
+			Write(docs[0])
+		}
+	}
+
+	code := CombineExitCodes(codes...)
+	if grouped && !Structured() {
+		fmt.Printf("\n%d service(s) processed, exit code %d\n", len(reports), code)
+	}
+
+	os.Exit(code)
+}
+
+// CombineExitCodes returns the most severe of the exit codes, a general
+// error is the most severe followed by a partial failure and then
+// differences being found
+func CombineExitCodes(codes ...int) int {
+	severity := map[int]int{
+		common.ExitSuccess:        0,
+		common.ExitDifferences:    1,
+		common.ExitPartialFailure: 2,
+		common.ExitFailure:        3,
+	}
+
+	combined := common.ExitSuccess
+	for _, code := range codes {
+		if severity[code] > severity[combined] {
+			combined = code
+		}
+	}
+	return combined
+}
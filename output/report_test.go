@@ -0,0 +1,30 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/integralist/go-fastly-cli/common"
+)
+
+func TestCombineExitCodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		codes []int
+		code  int
+	}{
+		{name: "no services", codes: nil, code: common.ExitSuccess},
+		{name: "every service succeeded", codes: []int{common.ExitSuccess, common.ExitSuccess}, code: common.ExitSuccess},
+		{name: "differences", codes: []int{common.ExitSuccess, common.ExitDifferences}, code: common.ExitDifferences},
+		{name: "partial failure over differences", codes: []int{common.ExitDifferences, common.ExitPartialFailure, common.ExitSuccess}, code: common.ExitPartialFailure},
+		{name: "failure over every other code", codes: []int{common.ExitPartialFailure, common.ExitFailure, common.ExitDifferences}, code: common.ExitFailure},
+		{name: "order doesn't matter", codes: []int{common.ExitFailure, common.ExitSuccess, common.ExitPartialFailure}, code: common.ExitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := CombineExitCodes(tt.codes...); code != tt.code {
+				t.Errorf("got exit code %d, want %d", code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Api is a package that defines the subset of the go-fastly client used by
+// this project, so alternative implementations (e.g. an in-memory fake for
+// tests) can be swapped in for the real Fastly API.
+
+package api
+
+import "github.com/fastly/go-fastly/fastly"
+
+// Client is satisfied by *fastly.Client
+// the Requester is used for the endpoints go-fastly doesn't provide (or fully
+// decode)
+type Client interface {
+	Requester
+
+	ListVersions(*fastly.ListVersionsInput) ([]*fastly.Version, error)
+	GetVersion(*fastly.GetVersionInput) (*fastly.Version, error)
+	CloneVersion(*fastly.CloneVersionInput) (*fastly.Version, error)
+	UpdateVersion(*fastly.UpdateVersionInput) (*fastly.Version, error)
+	LockVersion(*fastly.LockVersionInput) (*fastly.Version, error)
+	ActivateVersion(*fastly.ActivateVersionInput) (*fastly.Version, error)
+	ValidateVersion(*fastly.ValidateVersionInput) (bool, string, error)
+
+	GetSettings(*fastly.GetSettingsInput) (*fastly.Settings, error)
+
+	ListVCLs(*fastly.ListVCLsInput) ([]*fastly.VCL, error)
+	GetVCL(*fastly.GetVCLInput) (*fastly.VCL, error)
+	CreateVCL(*fastly.CreateVCLInput) (*fastly.VCL, error)
+	UpdateVCL(*fastly.UpdateVCLInput) (*fastly.VCL, error)
+	ActivateVCL(*fastly.ActivateVCLInput) (*fastly.VCL, error)
+	DeleteVCL(*fastly.DeleteVCLInput) error
+
+	ListSnippets(*fastly.ListSnippetsInput) ([]*fastly.Snippet, error)
+	GetSnippet(*fastly.GetSnippetInput) (*fastly.Snippet, error)
+	CreateSnippet(*fastly.CreateSnippetInput) (*fastly.Snippet, error)
+	DeleteSnippet(*fastly.DeleteSnippetInput) error
+	GetDynamicSnippet(*fastly.GetDynamicSnippetInput) (*fastly.DynamicSnippet, error)
+	UpdateDynamicSnippet(*fastly.UpdateDynamicSnippetInput) (*fastly.DynamicSnippet, error)
+
+	ListDictionaries(*fastly.ListDictionariesInput) ([]*fastly.Dictionary, error)
+	GetDictionary(*fastly.GetDictionaryInput) (*fastly.Dictionary, error)
+	CreateDictionary(*fastly.CreateDictionaryInput) (*fastly.Dictionary, error)
+	DeleteDictionary(*fastly.DeleteDictionaryInput) error
+	ListDictionaryItems(*fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error)
+	GetDictionaryItem(*fastly.GetDictionaryItemInput) (*fastly.DictionaryItem, error)
+	DeleteDictionaryItem(*fastly.DeleteDictionaryItemInput) error
+	BatchModifyDictionaryItems(*fastly.BatchModifyDictionaryItemsInput) error
+
+	ListACLs(*fastly.ListACLsInput) ([]*fastly.ACL, error)
+	CreateACL(*fastly.CreateACLInput) (*fastly.ACL, error)
+	DeleteACL(*fastly.DeleteACLInput) error
+	ListACLEntries(*fastly.ListACLEntriesInput) ([]*fastly.ACLEntry, error)
+	CreateACLEntry(*fastly.CreateACLEntryInput) (*fastly.ACLEntry, error)
+	DeleteACLEntry(*fastly.DeleteACLEntryInput) error
+	BatchModifyACLEntries(*fastly.BatchModifyACLEntriesInput) error
+
+	ListBackends(*fastly.ListBackendsInput) ([]*fastly.Backend, error)
+	GetBackend(*fastly.GetBackendInput) (*fastly.Backend, error)
+	CreateBackend(*fastly.CreateBackendInput) (*fastly.Backend, error)
+	UpdateBackend(*fastly.UpdateBackendInput) (*fastly.Backend, error)
+	DeleteBackend(*fastly.DeleteBackendInput) error
+
+	ListHealthChecks(*fastly.ListHealthChecksInput) ([]*fastly.HealthCheck, error)
+	CreateHealthCheck(*fastly.CreateHealthCheckInput) (*fastly.HealthCheck, error)
+	UpdateHealthCheck(*fastly.UpdateHealthCheckInput) (*fastly.HealthCheck, error)
+	DeleteHealthCheck(*fastly.DeleteHealthCheckInput) error
+
+	CreateDirector(*fastly.CreateDirectorInput) (*fastly.Director, error)
+	UpdateDirector(*fastly.UpdateDirectorInput) (*fastly.Director, error)
+	DeleteDirector(*fastly.DeleteDirectorInput) error
+	CreateDirectorBackend(*fastly.CreateDirectorBackendInput) (*fastly.DirectorBackend, error)
+	DeleteDirectorBackend(*fastly.DeleteDirectorBackendInput) error
+
+	ListConditions(*fastly.ListConditionsInput) ([]*fastly.Condition, error)
+	CreateCondition(*fastly.CreateConditionInput) (*fastly.Condition, error)
+	UpdateCondition(*fastly.UpdateConditionInput) (*fastly.Condition, error)
+	DeleteCondition(*fastly.DeleteConditionInput) error
+
+	ListDomains(*fastly.ListDomainsInput) ([]*fastly.Domain, error)
+	CreateDomain(*fastly.CreateDomainInput) (*fastly.Domain, error)
+	DeleteDomain(*fastly.DeleteDomainInput) error
+}
+
+// compile time check that the real client satisfies the interface
+var _ Client = (*fastly.Client)(nil)
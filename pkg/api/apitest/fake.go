@@ -0,0 +1,2432 @@
+// Apitest is a package that provides an in-memory implementation of the
+// api.Client interface, modelling services, versions (including their
+// locked/active state), VCL files, snippets, dictionaries, ACLs, backends,
+// healthchecks, directors, conditions and domains, so commands can be
+// exercised offline.
+
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// compile time check that the fake satisfies the interfaces
+var (
+	_ api.Client    = (*Fake)(nil)
+	_ api.Requester = (*Fake)(nil)
+)
+
+// Fake is an in-memory Fastly backend
+// it is safe for concurrent use
+type Fake struct {
+	mu       sync.Mutex
+	services map[string]*service
+	failures map[string]error
+	calls    map[string]int
+
+	// ids is used to give each snippet, dictionary and ACL (entry) a unique id
+	ids int
+
+	// checks are the outcome of Fastly checking the DNS of each domain keyed
+	// by the domain name (DNS isn't specific to a service)
+	checks map[string]api.DomainCheck
+}
+
+type service struct {
+	versions map[int]*version
+
+	// dynamic is the content of each dynamic snippet keyed by id, it's
+	// shared by every version (so can change without a new version)
+	dynamic map[string]string
+
+	// items are the items of each dictionary keyed by the dictionary id,
+	// which are also shared by every version
+	items map[string]map[string]string
+
+	// entries are the entries of each ACL keyed by the ACL id, which are
+	// also shared by every version
+	entries map[string][]*fastly.ACLEntry
+}
+
+type version struct {
+	fastly.Version
+	settings fastly.Settings
+	vcls     map[string]*fastly.VCL
+	snippets map[string]*fastly.Snippet
+	dicts    map[string]*fastly.Dictionary
+	acls     map[string]*fastly.ACL
+	backends map[string]*fastly.Backend
+
+	healthChecks map[string]*fastly.HealthCheck
+	directors    map[string]*fastly.Director
+	conditions   map[string]*fastly.Condition
+	domains      map[string]*fastly.Domain
+
+	// members are the backends of each director keyed by the director name
+	members map[string]map[string]bool
+}
+
+// NewFake returns an empty in-memory backend
+func NewFake() *Fake {
+	return &Fake{
+		services: map[string]*service{},
+		failures: map[string]error{},
+		calls:    map[string]int{},
+		checks:   map[string]api.DomainCheck{},
+	}
+}
+
+// AddService creates a service with a single (inactive) version and returns
+// that version's number
+func (f *Fake) AddService(id string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := &service{
+		versions: map[int]*version{},
+		dynamic:  map[string]string{},
+		items:    map[string]map[string]string{},
+		entries:  map[string][]*fastly.ACLEntry{},
+	}
+	f.services[id] = s
+
+	return s.add(id, nil).Number
+}
+
+// AddVersion creates a new empty version of the service
+func (f *Fake) AddVersion(serviceID string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, err := f.service(serviceID)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.add(serviceID, nil).Number, nil
+}
+
+// SetVCL stores a VCL file in the given version regardless of its locked state
+func (f *Fake) SetVCL(serviceID string, versionNumber int, name, content string, main bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.version(serviceID, versionNumber)
+	if err != nil {
+		return err
+	}
+
+	v.vcls[name] = &fastly.VCL{
+		ServiceID: serviceID,
+		Version:   versionNumber,
+		Name:      name,
+		Main:      main,
+		Content:   content,
+	}
+
+	return nil
+}
+
+// SetSnippet stores a snippet in the given version regardless of its locked
+// state, the content of a dynamic snippet is stored outside of the version
+func (f *Fake) SetSnippet(serviceID string, versionNumber int, snippet fastly.Snippet) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.version(serviceID, versionNumber)
+	if err != nil {
+		return err
+	}
+
+	f.storeSnippet(serviceID, v, &snippet)
+	return nil
+}
+
+// DynamicSnippet returns the current content of the dynamic snippet
+func (f *Fake) DynamicSnippet(serviceID, id string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, err := f.service(serviceID)
+	if err != nil {
+		return "", err
+	}
+
+	content, ok := s.dynamic[id]
+	if !ok {
+		return "", httpError(http.StatusNotFound)
+	}
+	return content, nil
+}
+
+// SetDictionary creates a dictionary in the given version regardless of its
+// locked state, along with its items, and returns the dictionary's id
+func (f *Fake) SetDictionary(serviceID string, versionNumber int, name string, items map[string]string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.version(serviceID, versionNumber)
+	if err != nil {
+		return "", err
+	}
+
+	dict := f.storeDictionary(serviceID, v, name, false)
+	for key, value := range items {
+		f.services[serviceID].items[dict.ID][key] = value
+	}
+
+	return dict.ID, nil
+}
+
+// DictionaryItems returns the items of the dictionary keyed by item key
+func (f *Fake) DictionaryItems(serviceID, id string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	items, err := f.items(serviceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := map[string]string{}
+	for key, value := range items {
+		copied[key] = value
+	}
+	return copied, nil
+}
+
+// SetACL creates an ACL in the given version regardless of its locked state,
+// along with its entries, and returns the ACL's id
+func (f *Fake) SetACL(serviceID string, versionNumber int, name string, entries []fastly.ACLEntry) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.version(serviceID, versionNumber)
+	if err != nil {
+		return "", err
+	}
+
+	acl := f.storeACL(serviceID, v, name)
+	for _, entry := range entries {
+		copied := entry
+		copied.ServiceID = serviceID
+		copied.ACLID = acl.ID
+		copied.ID = f.nextID("entry")
+		f.services[serviceID].entries[acl.ID] = append(f.services[serviceID].entries[acl.ID], &copied)
+	}
+
+	return acl.ID, nil
+}
+
+// ACLEntries returns the entries of the ACL in the order they were created
+func (f *Fake) ACLEntries(serviceID, id string) ([]fastly.ACLEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := f.entries(serviceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := make([]fastly.ACLEntry, 0, len(entries))
+	for _, entry := range entries {
+		copied = append(copied, *entry)
+	}
+	return copied, nil
+}
+
+// SetBackend stores a backend in the given version regardless of its locked
+// state
+func (f *Fake) SetBackend(serviceID string, versionNumber int, backend fastly.Backend) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.version(serviceID, versionNumber)
+	if err != nil {
+		return err
+	}
+
+	backend.ServiceID = serviceID
+	backend.Version = versionNumber
+	v.backends[backend.Name] = &backend
+
+	return nil
+}
+
+// SetHealthCheck stores a healthcheck in the given version regardless of its
+// locked state
+func (f *Fake) SetHealthCheck(serviceID string, versionNumber int, healthCheck fastly.HealthCheck) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.version(serviceID, versionNumber)
+	if err != nil {
+		return err
+	}
+
+	healthCheck.ServiceID = serviceID
+	healthCheck.Version = versionNumber
+	v.healthChecks[healthCheck.Name] = &healthCheck
+
+	return nil
+}
+
+// SetDirector stores a director along with its backends in the given version
+// regardless of its locked state (the backends aren't required to exist)
+func (f *Fake) SetDirector(serviceID string, versionNumber int, director fastly.Director, backends []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.version(serviceID, versionNumber)
+	if err != nil {
+		return err
+	}
+
+	director.ServiceID = serviceID
+	director.Version = versionNumber
+	v.directors[director.Name] = &director
+
+	v.members[director.Name] = map[string]bool{}
+	for _, backend := range backends {
+		v.members[director.Name][backend] = true
+	}
+
+	return nil
+}
+
+// SetCondition stores a condition in the given version regardless of its
+// locked state
+func (f *Fake) SetCondition(serviceID string, versionNumber int, condition fastly.Condition) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.version(serviceID, versionNumber)
+	if err != nil {
+		return err
+	}
+
+	condition.ServiceID = serviceID
+	condition.Version = versionNumber
+	v.conditions[condition.Name] = &condition
+
+	return nil
+}
+
+// SetDomain stores a domain in the given version regardless of its locked
+// state
+func (f *Fake) SetDomain(serviceID string, versionNumber int, domain fastly.Domain) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.version(serviceID, versionNumber)
+	if err != nil {
+		return err
+	}
+
+	domain.ServiceID = serviceID
+	domain.Version = versionNumber
+	v.domains[domain.Name] = &domain
+
+	return nil
+}
+
+// SetDomainCheck sets the outcome of Fastly checking the DNS of the domain,
+// a domain without one is reported as having no CNAME
+func (f *Fake) SetDomainCheck(check api.DomainCheck) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.checks[check.Name] = check
+}
+
+// VCLs returns the content of each VCL file in the given version keyed by name
+func (f *Fake) VCLs(serviceID string, versionNumber int) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.version(serviceID, versionNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{}
+	for name, vcl := range v.vcls {
+		files[name] = vcl.Content
+	}
+
+	return files, nil
+}
+
+// FailOn makes every subsequent call to the named method (e.g. "CreateVCL")
+// return the given error, passing a nil error removes the failure
+func (f *Fake) FailOn(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.failures, method)
+		return
+	}
+	f.failures[method] = err
+}
+
+// Calls returns the number of times the named method has been called
+func (f *Fake) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[method]
+}
+
+// ListVersions implements api.Client
+func (f *Fake) ListVersions(i *fastly.ListVersionsInput) ([]*fastly.Version, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListVersions"); err != nil {
+		return nil, err
+	}
+
+	s, err := f.service(i.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []*fastly.Version
+	for _, v := range s.versions {
+		copied := v.Version
+		versions = append(versions, &copied)
+	}
+
+	return versions, nil
+}
+
+// GetVersion implements api.Client
+func (f *Fake) GetVersion(i *fastly.GetVersionInput) (*fastly.Version, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetVersion"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := v.Version
+	return &copied, nil
+}
+
+// CloneVersion implements api.Client
+func (f *Fake) CloneVersion(i *fastly.CloneVersionInput) (*fastly.Version, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CloneVersion"); err != nil {
+		return nil, err
+	}
+
+	source, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := f.services[i.Service].add(i.Service, source)
+
+	copied := clone.Version
+	return &copied, nil
+}
+
+// UpdateVersion implements api.Client
+// only the comment can be updated, which is also allowed for locked versions
+func (f *Fake) UpdateVersion(i *fastly.UpdateVersionInput) (*fastly.Version, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("UpdateVersion"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Comment = i.Comment
+
+	copied := v.Version
+	return &copied, nil
+}
+
+// LockVersion implements api.Client
+func (f *Fake) LockVersion(i *fastly.LockVersionInput) (*fastly.Version, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("LockVersion"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Locked = true
+
+	copied := v.Version
+	return &copied, nil
+}
+
+// ActivateVersion implements api.Client
+// the activated version is locked and any previously active version is
+// deactivated, invalid versions cannot be activated
+func (f *Fake) ActivateVersion(i *fastly.ActivateVersionInput) (*fastly.Version, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ActivateVersion"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if valid, _ := v.validate(); !valid {
+		return nil, httpError(http.StatusBadRequest)
+	}
+
+	for _, other := range f.services[i.Service].versions {
+		other.Active = false
+	}
+
+	v.Active = true
+	v.Locked = true
+	v.Deployed = true
+
+	copied := v.Version
+	return &copied, nil
+}
+
+// ValidateVersion implements api.Client
+func (f *Fake) ValidateVersion(i *fastly.ValidateVersionInput) (bool, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ValidateVersion"); err != nil {
+		return false, "", err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return false, "", err
+	}
+
+	valid, msg := v.validate()
+	return valid, msg, nil
+}
+
+// GetSettings implements api.Client
+func (f *Fake) GetSettings(i *fastly.GetSettingsInput) (*fastly.Settings, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetSettings"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := v.settings
+	return &copied, nil
+}
+
+// ListVCLs implements api.Client
+// files are returned sorted by name
+func (f *Fake) ListVCLs(i *fastly.ListVCLsInput) ([]*fastly.VCL, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListVCLs"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(v.vcls))
+	for name := range v.vcls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vcls := make([]*fastly.VCL, 0, len(names))
+	for _, name := range names {
+		copied := *v.vcls[name]
+		vcls = append(vcls, &copied)
+	}
+
+	return vcls, nil
+}
+
+// GetVCL implements api.Client
+func (f *Fake) GetVCL(i *fastly.GetVCLInput) (*fastly.VCL, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetVCL"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	vcl, ok := v.vcls[i.Name]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	copied := *vcl
+	return &copied, nil
+}
+
+// CreateVCL implements api.Client
+func (f *Fake) CreateVCL(i *fastly.CreateVCLInput) (*fastly.VCL, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateVCL"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := v.vcls[i.Name]; ok {
+		return nil, httpError(http.StatusConflict)
+	}
+
+	vcl := &fastly.VCL{
+		ServiceID: i.Service,
+		Version:   i.Version,
+		Name:      i.Name,
+		Main:      i.Main,
+		Content:   i.Content,
+	}
+	v.vcls[i.Name] = vcl
+
+	copied := *vcl
+	return &copied, nil
+}
+
+// UpdateVCL implements api.Client
+func (f *Fake) UpdateVCL(i *fastly.UpdateVCLInput) (*fastly.VCL, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("UpdateVCL"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	vcl, ok := v.vcls[i.Name]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	if i.NewName != "" && i.NewName != i.Name {
+		if _, exists := v.vcls[i.NewName]; exists {
+			return nil, httpError(http.StatusConflict)
+		}
+		delete(v.vcls, i.Name)
+		vcl.Name = i.NewName
+		v.vcls[i.NewName] = vcl
+	}
+
+	if i.Content != "" {
+		vcl.Content = i.Content
+	}
+
+	copied := *vcl
+	return &copied, nil
+}
+
+// ActivateVCL implements api.Client
+// the named file becomes the only main VCL in the version
+func (f *Fake) ActivateVCL(i *fastly.ActivateVCLInput) (*fastly.VCL, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ActivateVCL"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	vcl, ok := v.vcls[i.Name]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	for _, other := range v.vcls {
+		other.Main = false
+	}
+	vcl.Main = true
+
+	copied := *vcl
+	return &copied, nil
+}
+
+// DeleteVCL implements api.Client
+func (f *Fake) DeleteVCL(i *fastly.DeleteVCLInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteVCL"); err != nil {
+		return err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := v.vcls[i.Name]; !ok {
+		return httpError(http.StatusNotFound)
+	}
+	delete(v.vcls, i.Name)
+
+	return nil
+}
+
+// ListSnippets implements api.Client
+// snippets are returned sorted by name
+func (f *Fake) ListSnippets(i *fastly.ListSnippetsInput) ([]*fastly.Snippet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListSnippets"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(v.snippets))
+	for name := range v.snippets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	snippets := make([]*fastly.Snippet, 0, len(names))
+	for _, name := range names {
+		copied := *v.snippets[name]
+		snippets = append(snippets, &copied)
+	}
+
+	return snippets, nil
+}
+
+// GetSnippet implements api.Client
+func (f *Fake) GetSnippet(i *fastly.GetSnippetInput) (*fastly.Snippet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetSnippet"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	snippet, ok := v.snippets[i.Name]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	copied := *snippet
+	return &copied, nil
+}
+
+// CreateSnippet implements api.Client
+func (f *Fake) CreateSnippet(i *fastly.CreateSnippetInput) (*fastly.Snippet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateSnippet"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := v.snippets[i.Name]; ok {
+		return nil, httpError(http.StatusConflict)
+	}
+
+	snippet := &fastly.Snippet{
+		Name:     i.Name,
+		Priority: i.Priority,
+		Dynamic:  i.Dynamic,
+		Content:  i.Content,
+		Type:     i.Type,
+	}
+	f.storeSnippet(i.Service, v, snippet)
+
+	copied := *snippet
+	return &copied, nil
+}
+
+// DeleteSnippet implements api.Client
+func (f *Fake) DeleteSnippet(i *fastly.DeleteSnippetInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteSnippet"); err != nil {
+		return err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := v.snippets[i.Name]; !ok {
+		return httpError(http.StatusNotFound)
+	}
+	delete(v.snippets, i.Name)
+
+	return nil
+}
+
+// GetDynamicSnippet implements api.Client
+func (f *Fake) GetDynamicSnippet(i *fastly.GetDynamicSnippetInput) (*fastly.DynamicSnippet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetDynamicSnippet"); err != nil {
+		return nil, err
+	}
+
+	s, err := f.service(i.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	content, ok := s.dynamic[i.ID]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	return &fastly.DynamicSnippet{ServiceID: i.Service, ID: i.ID, Content: content}, nil
+}
+
+// UpdateDynamicSnippet implements api.Client
+// the content changes for every version (including locked and active ones)
+func (f *Fake) UpdateDynamicSnippet(i *fastly.UpdateDynamicSnippetInput) (*fastly.DynamicSnippet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("UpdateDynamicSnippet"); err != nil {
+		return nil, err
+	}
+
+	s, err := f.service(i.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := s.dynamic[i.ID]; !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+	s.dynamic[i.ID] = i.Content
+
+	return &fastly.DynamicSnippet{ServiceID: i.Service, ID: i.ID, Content: i.Content}, nil
+}
+
+// ListDictionaries implements api.Client
+// dictionaries are returned sorted by name
+func (f *Fake) ListDictionaries(i *fastly.ListDictionariesInput) ([]*fastly.Dictionary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListDictionaries"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(v.dicts))
+	for name := range v.dicts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dicts := make([]*fastly.Dictionary, 0, len(names))
+	for _, name := range names {
+		copied := *v.dicts[name]
+		dicts = append(dicts, &copied)
+	}
+
+	return dicts, nil
+}
+
+// GetDictionary implements api.Client
+func (f *Fake) GetDictionary(i *fastly.GetDictionaryInput) (*fastly.Dictionary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetDictionary"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	dict, ok := v.dicts[i.Name]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	copied := *dict
+	return &copied, nil
+}
+
+// CreateDictionary implements api.Client
+func (f *Fake) CreateDictionary(i *fastly.CreateDictionaryInput) (*fastly.Dictionary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateDictionary"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := v.dicts[i.Name]; ok {
+		return nil, httpError(http.StatusConflict)
+	}
+
+	dict := f.storeDictionary(i.Service, v, i.Name, bool(i.WriteOnly))
+
+	copied := *dict
+	return &copied, nil
+}
+
+// DeleteDictionary implements api.Client
+func (f *Fake) DeleteDictionary(i *fastly.DeleteDictionaryInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteDictionary"); err != nil {
+		return err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := v.dicts[i.Name]; !ok {
+		return httpError(http.StatusNotFound)
+	}
+	delete(v.dicts, i.Name)
+
+	return nil
+}
+
+// ListDictionaryItems implements api.Client
+// items are returned sorted by key
+func (f *Fake) ListDictionaryItems(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListDictionaryItems"); err != nil {
+		return nil, err
+	}
+
+	items, err := f.items(i.Service, i.Dictionary)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	list := make([]*fastly.DictionaryItem, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, dictionaryItem(i.Service, i.Dictionary, key, items[key]))
+	}
+
+	return list, nil
+}
+
+// GetDictionaryItem implements api.Client
+func (f *Fake) GetDictionaryItem(i *fastly.GetDictionaryItemInput) (*fastly.DictionaryItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetDictionaryItem"); err != nil {
+		return nil, err
+	}
+
+	items, err := f.items(i.Service, i.Dictionary)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := items[i.ItemKey]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	return dictionaryItem(i.Service, i.Dictionary, i.ItemKey, value), nil
+}
+
+// DeleteDictionaryItem implements api.Client
+func (f *Fake) DeleteDictionaryItem(i *fastly.DeleteDictionaryItemInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteDictionaryItem"); err != nil {
+		return err
+	}
+
+	items, err := f.items(i.Service, i.Dictionary)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := items[i.ItemKey]; !ok {
+		return httpError(http.StatusNotFound)
+	}
+	delete(items, i.ItemKey)
+
+	return nil
+}
+
+// BatchModifyDictionaryItems implements api.Client
+// the batch is rejected (without any changes) when it's too large or any of
+// its operations can't be applied
+func (f *Fake) BatchModifyDictionaryItems(i *fastly.BatchModifyDictionaryItemsInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("BatchModifyDictionaryItems"); err != nil {
+		return err
+	}
+
+	items, err := f.items(i.Service, i.Dictionary)
+	if err != nil {
+		return err
+	}
+
+	if len(i.Items) > fastly.BatchModifyMaximumOperations {
+		return httpError(http.StatusBadRequest)
+	}
+
+	modified := map[string]string{}
+	for key, value := range items {
+		modified[key] = value
+	}
+
+	for _, item := range i.Items {
+		_, exists := modified[item.ItemKey]
+
+		switch item.Operation {
+		case fastly.CreateBatchOperation:
+			if exists {
+				return httpError(http.StatusConflict)
+			}
+			modified[item.ItemKey] = item.ItemValue
+		case fastly.UpdateBatchOperation:
+			if !exists {
+				return httpError(http.StatusNotFound)
+			}
+			modified[item.ItemKey] = item.ItemValue
+		case fastly.UpsertBatchOperation:
+			modified[item.ItemKey] = item.ItemValue
+		case fastly.DeleteBatchOperation:
+			if !exists {
+				return httpError(http.StatusNotFound)
+			}
+			delete(modified, item.ItemKey)
+		default:
+			return httpError(http.StatusBadRequest)
+		}
+	}
+
+	f.services[i.Service].items[i.Dictionary] = modified
+	return nil
+}
+
+// ListACLs implements api.Client
+// ACLs are returned sorted by name
+func (f *Fake) ListACLs(i *fastly.ListACLsInput) ([]*fastly.ACL, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListACLs"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(v.acls))
+	for name := range v.acls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	acls := make([]*fastly.ACL, 0, len(names))
+	for _, name := range names {
+		copied := *v.acls[name]
+		acls = append(acls, &copied)
+	}
+
+	return acls, nil
+}
+
+// CreateACL implements api.Client
+func (f *Fake) CreateACL(i *fastly.CreateACLInput) (*fastly.ACL, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateACL"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := v.acls[i.Name]; ok {
+		return nil, httpError(http.StatusConflict)
+	}
+
+	acl := f.storeACL(i.Service, v, i.Name)
+
+	copied := *acl
+	return &copied, nil
+}
+
+// DeleteACL implements api.Client
+func (f *Fake) DeleteACL(i *fastly.DeleteACLInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteACL"); err != nil {
+		return err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := v.acls[i.Name]; !ok {
+		return httpError(http.StatusNotFound)
+	}
+	delete(v.acls, i.Name)
+
+	return nil
+}
+
+// ListACLEntries implements api.Client
+// entries are returned in the order they were created
+func (f *Fake) ListACLEntries(i *fastly.ListACLEntriesInput) ([]*fastly.ACLEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListACLEntries"); err != nil {
+		return nil, err
+	}
+
+	entries, err := f.entries(i.Service, i.ACL)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*fastly.ACLEntry, 0, len(entries))
+	for _, entry := range entries {
+		copied := *entry
+		list = append(list, &copied)
+	}
+
+	return list, nil
+}
+
+// CreateACLEntry implements api.Client
+func (f *Fake) CreateACLEntry(i *fastly.CreateACLEntryInput) (*fastly.ACLEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateACLEntry"); err != nil {
+		return nil, err
+	}
+
+	entries, err := f.entries(i.Service, i.ACL)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.IP == "" {
+		return nil, httpError(http.StatusBadRequest)
+	}
+
+	entry := &fastly.ACLEntry{
+		ServiceID: i.Service,
+		ACLID:     i.ACL,
+		ID:        f.nextID("entry"),
+		IP:        i.IP,
+		Subnet:    i.Subnet,
+		Negated:   bool(i.Negated),
+		Comment:   i.Comment,
+	}
+	f.services[i.Service].entries[i.ACL] = append(entries, entry)
+
+	copied := *entry
+	return &copied, nil
+}
+
+// DeleteACLEntry implements api.Client
+func (f *Fake) DeleteACLEntry(i *fastly.DeleteACLEntryInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteACLEntry"); err != nil {
+		return err
+	}
+
+	entries, err := f.entries(i.Service, i.ACL)
+	if err != nil {
+		return err
+	}
+
+	for n, entry := range entries {
+		if entry.ID == i.ID {
+			f.services[i.Service].entries[i.ACL] = append(entries[:n:n], entries[n+1:]...)
+			return nil
+		}
+	}
+
+	return httpError(http.StatusNotFound)
+}
+
+// BatchModifyACLEntries implements api.Client
+// the batch is rejected (without any changes) when it's too large or any of
+// its operations can't be applied
+func (f *Fake) BatchModifyACLEntries(i *fastly.BatchModifyACLEntriesInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("BatchModifyACLEntries"); err != nil {
+		return err
+	}
+
+	entries, err := f.entries(i.Service, i.ACL)
+	if err != nil {
+		return err
+	}
+
+	if len(i.Entries) > fastly.BatchModifyMaximumOperations {
+		return httpError(http.StatusBadRequest)
+	}
+
+	modified := make([]*fastly.ACLEntry, 0, len(entries))
+	for _, entry := range entries {
+		copied := *entry
+		modified = append(modified, &copied)
+	}
+
+	find := func(id string) int {
+		for n, entry := range modified {
+			if entry.ID == id {
+				return n
+			}
+		}
+		return -1
+	}
+
+	// ids are only assigned once the whole batch is known to apply
+	ids := f.ids
+
+	for _, op := range i.Entries {
+		switch op.Operation {
+		case fastly.CreateBatchOperation:
+			if op.IP == "" {
+				return httpError(http.StatusBadRequest)
+			}
+			ids++
+			modified = append(modified, &fastly.ACLEntry{
+				ServiceID: i.Service,
+				ACLID:     i.ACL,
+				ID:        fmt.Sprintf("entry%d", ids),
+				IP:        op.IP,
+				Subnet:    op.Subnet,
+				Negated:   bool(op.Negated),
+				Comment:   op.Comment,
+			})
+		case fastly.UpdateBatchOperation:
+			n := find(op.ID)
+			if n < 0 {
+				return httpError(http.StatusNotFound)
+			}
+			if op.IP != "" {
+				modified[n].IP = op.IP
+				modified[n].Subnet = op.Subnet
+			}
+			modified[n].Negated = bool(op.Negated)
+			modified[n].Comment = op.Comment
+		case fastly.DeleteBatchOperation:
+			n := find(op.ID)
+			if n < 0 {
+				return httpError(http.StatusNotFound)
+			}
+			modified = append(modified[:n:n], modified[n+1:]...)
+		default:
+			return httpError(http.StatusBadRequest)
+		}
+	}
+
+	f.ids = ids
+	f.services[i.Service].entries[i.ACL] = modified
+	return nil
+}
+
+// ListBackends implements api.Client
+// backends are returned sorted by name
+func (f *Fake) ListBackends(i *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListBackends"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(v.backends))
+	for name := range v.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	backends := make([]*fastly.Backend, 0, len(names))
+	for _, name := range names {
+		copied := *v.backends[name]
+		backends = append(backends, &copied)
+	}
+
+	return backends, nil
+}
+
+// GetBackend implements api.Client
+func (f *Fake) GetBackend(i *fastly.GetBackendInput) (*fastly.Backend, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetBackend"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, ok := v.backends[i.Name]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	copied := *backend
+	return &copied, nil
+}
+
+// CreateBackend implements api.Client
+func (f *Fake) CreateBackend(i *fastly.CreateBackendInput) (*fastly.Backend, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateBackend"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := v.backends[i.Name]; ok {
+		return nil, httpError(http.StatusConflict)
+	}
+
+	if i.Name == "" || i.Address == "" {
+		return nil, httpError(http.StatusBadRequest)
+	}
+
+	backend := &fastly.Backend{
+		ServiceID:           i.Service,
+		Version:             i.Version,
+		Name:                i.Name,
+		Comment:             i.Comment,
+		Address:             i.Address,
+		Port:                i.Port,
+		OverrideHost:        i.OverrideHost,
+		ConnectTimeout:      i.ConnectTimeout,
+		MaxConn:             i.MaxConn,
+		ErrorThreshold:      i.ErrorThreshold,
+		FirstByteTimeout:    i.FirstByteTimeout,
+		BetweenBytesTimeout: i.BetweenBytesTimeout,
+		Weight:              i.Weight,
+		RequestCondition:    i.RequestCondition,
+		HealthCheck:         i.HealthCheck,
+		Shield:              i.Shield,
+		SSLHostname:         i.SSLHostname,
+		SSLCertHostname:     i.SSLCertHostname,
+		SSLSNIHostname:      i.SSLSNIHostname,
+		MinTLSVersion:       i.MinTLSVersion,
+		MaxTLSVersion:       i.MaxTLSVersion,
+	}
+	if backend.Port == 0 {
+		backend.Port = 80
+	}
+	if i.AutoLoadbalance != nil {
+		backend.AutoLoadbalance = bool(*i.AutoLoadbalance)
+	}
+	if i.UseSSL != nil {
+		backend.UseSSL = bool(*i.UseSSL)
+	}
+	if i.SSLCheckCert != nil {
+		backend.SSLCheckCert = bool(*i.SSLCheckCert)
+	}
+	v.backends[i.Name] = backend
+
+	copied := *backend
+	return &copied, nil
+}
+
+// UpdateBackend implements api.Client
+// as with the Fastly API, only the fields that are set are changed
+func (f *Fake) UpdateBackend(i *fastly.UpdateBackendInput) (*fastly.Backend, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("UpdateBackend"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, ok := v.backends[i.Name]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	if i.NewName != "" && i.NewName != i.Name {
+		if _, exists := v.backends[i.NewName]; exists {
+			return nil, httpError(http.StatusConflict)
+		}
+	}
+
+	updated := *backend
+	setString(&updated.Name, i.NewName)
+	setString(&updated.Comment, i.Comment)
+	setString(&updated.Address, i.Address)
+	setUint(&updated.Port, i.Port)
+	setString(&updated.OverrideHost, i.OverrideHost)
+	setUint(&updated.ConnectTimeout, i.ConnectTimeout)
+	setUint(&updated.MaxConn, i.MaxConn)
+	setUint(&updated.ErrorThreshold, i.ErrorThreshold)
+	setUint(&updated.FirstByteTimeout, i.FirstByteTimeout)
+	setUint(&updated.BetweenBytesTimeout, i.BetweenBytesTimeout)
+	setBool(&updated.AutoLoadbalance, i.AutoLoadbalance)
+	setUint(&updated.Weight, i.Weight)
+	setString(&updated.RequestCondition, i.RequestCondition)
+	setString(&updated.HealthCheck, i.HealthCheck)
+	setString(&updated.Shield, i.Shield)
+	setBool(&updated.UseSSL, i.UseSSL)
+	setBool(&updated.SSLCheckCert, i.SSLCheckCert)
+	setString(&updated.SSLHostname, i.SSLHostname)
+	setString(&updated.SSLCertHostname, i.SSLCertHostname)
+	setString(&updated.SSLSNIHostname, i.SSLSNIHostname)
+	setString(&updated.MinTLSVersion, i.MinTLSVersion)
+	setString(&updated.MaxTLSVersion, i.MaxTLSVersion)
+
+	delete(v.backends, i.Name)
+	v.backends[updated.Name] = &updated
+
+	copied := updated
+	return &copied, nil
+}
+
+// DeleteBackend implements api.Client
+func (f *Fake) DeleteBackend(i *fastly.DeleteBackendInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteBackend"); err != nil {
+		return err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := v.backends[i.Name]; !ok {
+		return httpError(http.StatusNotFound)
+	}
+	delete(v.backends, i.Name)
+
+	return nil
+}
+
+// ListHealthChecks implements api.Client
+// healthchecks are returned sorted by name
+func (f *Fake) ListHealthChecks(i *fastly.ListHealthChecksInput) ([]*fastly.HealthCheck, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListHealthChecks"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(v.healthChecks))
+	for name := range v.healthChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	healthChecks := make([]*fastly.HealthCheck, 0, len(names))
+	for _, name := range names {
+		copied := *v.healthChecks[name]
+		healthChecks = append(healthChecks, &copied)
+	}
+
+	return healthChecks, nil
+}
+
+// CreateHealthCheck implements api.Client
+func (f *Fake) CreateHealthCheck(i *fastly.CreateHealthCheckInput) (*fastly.HealthCheck, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateHealthCheck"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.Name == "" {
+		return nil, httpError(http.StatusBadRequest)
+	}
+	if _, ok := v.healthChecks[i.Name]; ok {
+		return nil, httpError(http.StatusConflict)
+	}
+
+	healthCheck := &fastly.HealthCheck{
+		ServiceID:        i.Service,
+		Version:          i.Version,
+		Name:             i.Name,
+		Comment:          i.Comment,
+		Method:           i.Method,
+		Host:             i.Host,
+		Path:             i.Path,
+		HTTPVersion:      i.HTTPVersion,
+		Timeout:          i.Timeout,
+		CheckInterval:    i.CheckInterval,
+		ExpectedResponse: i.ExpectedResponse,
+		Window:           i.Window,
+		Threshold:        i.Threshold,
+		Initial:          i.Initial,
+	}
+	v.healthChecks[i.Name] = healthCheck
+
+	copied := *healthCheck
+	return &copied, nil
+}
+
+// UpdateHealthCheck implements api.Client
+// as with the Fastly API, only the fields that are set are changed
+func (f *Fake) UpdateHealthCheck(i *fastly.UpdateHealthCheckInput) (*fastly.HealthCheck, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("UpdateHealthCheck"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	healthCheck, ok := v.healthChecks[i.Name]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	if i.NewName != "" && i.NewName != i.Name {
+		if _, exists := v.healthChecks[i.NewName]; exists {
+			return nil, httpError(http.StatusConflict)
+		}
+	}
+
+	updated := *healthCheck
+	setString(&updated.Name, i.NewName)
+	setString(&updated.Comment, i.Comment)
+	setString(&updated.Method, i.Method)
+	setString(&updated.Host, i.Host)
+	setString(&updated.Path, i.Path)
+	setString(&updated.HTTPVersion, i.HTTPVersion)
+	setUint(&updated.Timeout, i.Timeout)
+	setUint(&updated.CheckInterval, i.CheckInterval)
+	setUint(&updated.ExpectedResponse, i.ExpectedResponse)
+	setUint(&updated.Window, i.Window)
+	setUint(&updated.Threshold, i.Threshold)
+	setUint(&updated.Initial, i.Initial)
+
+	delete(v.healthChecks, i.Name)
+	v.healthChecks[updated.Name] = &updated
+
+	copied := updated
+	return &copied, nil
+}
+
+// DeleteHealthCheck implements api.Client
+func (f *Fake) DeleteHealthCheck(i *fastly.DeleteHealthCheckInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteHealthCheck"); err != nil {
+		return err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := v.healthChecks[i.Name]; !ok {
+		return httpError(http.StatusNotFound)
+	}
+	delete(v.healthChecks, i.Name)
+
+	return nil
+}
+
+// CreateDirector implements api.Client
+func (f *Fake) CreateDirector(i *fastly.CreateDirectorInput) (*fastly.Director, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateDirector"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.Name == "" {
+		return nil, httpError(http.StatusBadRequest)
+	}
+	if _, ok := v.directors[i.Name]; ok {
+		return nil, httpError(http.StatusConflict)
+	}
+
+	director := &fastly.Director{
+		ServiceID: i.Service,
+		Version:   i.Version,
+		Name:      i.Name,
+		Comment:   i.Comment,
+		Quorum:    i.Quorum,
+		Type:      i.Type,
+		Retries:   i.Retries,
+	}
+	if director.Type == 0 {
+		director.Type = fastly.DirectorTypeRandom
+	}
+	v.directors[i.Name] = director
+	v.members[i.Name] = map[string]bool{}
+
+	copied := *director
+	return &copied, nil
+}
+
+// UpdateDirector implements api.Client
+// as with the Fastly API, only the fields that are set are changed
+func (f *Fake) UpdateDirector(i *fastly.UpdateDirectorInput) (*fastly.Director, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("UpdateDirector"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	director, ok := v.directors[i.Name]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	setString(&director.Comment, i.Comment)
+	setUint(&director.Quorum, i.Quorum)
+	setUint(&director.Retries, i.Retries)
+	if i.Type != 0 {
+		director.Type = i.Type
+	}
+
+	copied := *director
+	return &copied, nil
+}
+
+// DeleteDirector implements api.Client
+// the director's backends are removed from it along with the director
+func (f *Fake) DeleteDirector(i *fastly.DeleteDirectorInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteDirector"); err != nil {
+		return err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := v.directors[i.Name]; !ok {
+		return httpError(http.StatusNotFound)
+	}
+	delete(v.directors, i.Name)
+	delete(v.members, i.Name)
+
+	return nil
+}
+
+// CreateDirectorBackend implements api.Client
+func (f *Fake) CreateDirectorBackend(i *fastly.CreateDirectorBackendInput) (*fastly.DirectorBackend, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateDirectorBackend"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := v.directors[i.Director]; !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+	if _, ok := v.backends[i.Backend]; !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+	if v.members[i.Director][i.Backend] {
+		return nil, httpError(http.StatusConflict)
+	}
+	v.members[i.Director][i.Backend] = true
+
+	return &fastly.DirectorBackend{
+		ServiceID: i.Service,
+		Version:   i.Version,
+		Director:  i.Director,
+		Backend:   i.Backend,
+	}, nil
+}
+
+// DeleteDirectorBackend implements api.Client
+func (f *Fake) DeleteDirectorBackend(i *fastly.DeleteDirectorBackendInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteDirectorBackend"); err != nil {
+		return err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return err
+	}
+
+	if !v.members[i.Director][i.Backend] {
+		return httpError(http.StatusNotFound)
+	}
+	delete(v.members[i.Director], i.Backend)
+
+	return nil
+}
+
+// ListConditions implements api.Client
+// conditions are returned sorted by name
+func (f *Fake) ListConditions(i *fastly.ListConditionsInput) ([]*fastly.Condition, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListConditions"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(v.conditions))
+	for name := range v.conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	conditions := make([]*fastly.Condition, 0, len(names))
+	for _, name := range names {
+		copied := *v.conditions[name]
+		conditions = append(conditions, &copied)
+	}
+
+	return conditions, nil
+}
+
+// CreateCondition implements api.Client
+func (f *Fake) CreateCondition(i *fastly.CreateConditionInput) (*fastly.Condition, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateCondition"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.Name == "" || i.Statement == "" {
+		return nil, httpError(http.StatusBadRequest)
+	}
+	if _, ok := v.conditions[i.Name]; ok {
+		return nil, httpError(http.StatusConflict)
+	}
+
+	condition := &fastly.Condition{
+		ServiceID: i.Service,
+		Version:   i.Version,
+		Name:      i.Name,
+		Statement: i.Statement,
+		Type:      i.Type,
+		Priority:  i.Priority,
+	}
+	v.conditions[i.Name] = condition
+
+	copied := *condition
+	return &copied, nil
+}
+
+// UpdateCondition implements api.Client
+// as with the Fastly API, only the fields that are set are changed
+func (f *Fake) UpdateCondition(i *fastly.UpdateConditionInput) (*fastly.Condition, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("UpdateCondition"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, ok := v.conditions[i.Name]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	setString(&condition.Comment, i.Comment)
+	setString(&condition.Statement, i.Statement)
+	setString(&condition.Type, i.Type)
+	if i.Priority != 0 {
+		condition.Priority = i.Priority
+	}
+
+	copied := *condition
+	return &copied, nil
+}
+
+// DeleteCondition implements api.Client
+func (f *Fake) DeleteCondition(i *fastly.DeleteConditionInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteCondition"); err != nil {
+		return err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := v.conditions[i.Name]; !ok {
+		return httpError(http.StatusNotFound)
+	}
+	delete(v.conditions, i.Name)
+
+	return nil
+}
+
+// ListDomains implements api.Client
+// domains are returned sorted by name
+func (f *Fake) ListDomains(i *fastly.ListDomainsInput) ([]*fastly.Domain, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("ListDomains"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.version(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.sortedDomains(), nil
+}
+
+// CreateDomain implements api.Client
+func (f *Fake) CreateDomain(i *fastly.CreateDomainInput) (*fastly.Domain, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateDomain"); err != nil {
+		return nil, err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	// a domain can only belong to a single service
+	for id, s := range f.services {
+		for _, other := range s.versions {
+			if _, ok := other.domains[i.Name]; ok && id != i.Service {
+				return nil, httpError(http.StatusConflict)
+			}
+		}
+	}
+
+	if _, ok := v.domains[i.Name]; ok {
+		return nil, httpError(http.StatusConflict)
+	}
+
+	domain := &fastly.Domain{
+		ServiceID: i.Service,
+		Version:   i.Version,
+		Name:      i.Name,
+		Comment:   i.Comment,
+	}
+	v.domains[i.Name] = domain
+
+	copied := *domain
+	return &copied, nil
+}
+
+// DeleteDomain implements api.Client
+func (f *Fake) DeleteDomain(i *fastly.DeleteDomainInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteDomain"); err != nil {
+		return err
+	}
+
+	v, err := f.unlockedVersion(i.Service, i.Version)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := v.domains[i.Name]; !ok {
+		return httpError(http.StatusNotFound)
+	}
+	delete(v.domains, i.Name)
+
+	return nil
+}
+
+// Get implements api.Requester for the director list and domain check_all
+// endpoints, any other path responds with a 404
+func (f *Fake) Get(path string, ro *fastly.RequestOptions) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("Get"); err != nil {
+		return nil, err
+	}
+
+	// /service/<id>/version/<number>/director
+	// /service/<id>/version/<number>/domain/check_all
+	parts := strings.Split(path, "/")
+	if len(parts) < 6 || parts[1] != "service" || parts[3] != "version" {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	number, err := strconv.Atoi(parts[4])
+	if err != nil {
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	v, err := f.version(parts[2], number)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	switch {
+	case len(parts) == 6 && parts[5] == "director":
+		result = v.directorList()
+	case len(parts) == 7 && parts[5] == "domain" && parts[6] == "check_all":
+		result = f.domainChecks(v)
+	default:
+		return nil, httpError(http.StatusNotFound)
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+// directorList is the version's directors as the API lists them, including
+// the names of their backends
+func (v *version) directorList() []interface{} {
+	names := make([]string, 0, len(v.directors))
+	for name := range v.directors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := []interface{}{}
+	for _, name := range names {
+		backends := []string{}
+		for backend := range v.members[name] {
+			backends = append(backends, backend)
+		}
+		sort.Strings(backends)
+
+		d := v.directors[name]
+		list = append(list, map[string]interface{}{
+			"name":     name,
+			"comment":  d.Comment,
+			"type":     d.Type,
+			"quorum":   d.Quorum,
+			"retries":  d.Retries,
+			"backends": backends,
+		})
+	}
+
+	return list
+}
+
+// domainChecks is the result of checking the version's domains
+func (f *Fake) domainChecks(v *version) []interface{} {
+	checks := []interface{}{}
+	for _, domain := range v.sortedDomains() {
+		check := f.checks[domain.Name]
+
+		var cname interface{}
+		if check.CNAME != "" {
+			cname = check.CNAME
+		}
+
+		checks = append(checks, []interface{}{
+			map[string]interface{}{
+				"name":       domain.Name,
+				"comment":    domain.Comment,
+				"service_id": domain.ServiceID,
+				"version":    domain.Version,
+			},
+			cname,
+			check.Valid,
+		})
+	}
+
+	return checks
+}
+
+// storeDictionary creates an (empty) dictionary in the version, the caller
+// must hold the lock
+func (f *Fake) storeDictionary(serviceID string, v *version, name string, writeOnly bool) *fastly.Dictionary {
+	dict := &fastly.Dictionary{
+		ServiceID: serviceID,
+		Version:   v.Number,
+		ID:        f.nextID("dictionary"),
+		Name:      name,
+		WriteOnly: writeOnly,
+	}
+	v.dicts[name] = dict
+	f.services[serviceID].items[dict.ID] = map[string]string{}
+
+	return dict
+}
+
+// items returns the items of the dictionary, the caller must hold the lock
+func (f *Fake) items(serviceID, id string) (map[string]string, error) {
+	s, err := f.service(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := s.items[id]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+	return items, nil
+}
+
+func dictionaryItem(serviceID, id, key, value string) *fastly.DictionaryItem {
+	return &fastly.DictionaryItem{
+		ServiceID:    serviceID,
+		DictionaryID: id,
+		ItemKey:      key,
+		ItemValue:    value,
+	}
+}
+
+// storeACL creates an (empty) ACL in the version, the caller must hold the
+// lock
+func (f *Fake) storeACL(serviceID string, v *version, name string) *fastly.ACL {
+	acl := &fastly.ACL{
+		ServiceID: serviceID,
+		Version:   v.Number,
+		ID:        f.nextID("acl"),
+		Name:      name,
+	}
+	v.acls[name] = acl
+	f.services[serviceID].entries[acl.ID] = []*fastly.ACLEntry{}
+
+	return acl
+}
+
+// entries returns the entries of the ACL, the caller must hold the lock
+func (f *Fake) entries(serviceID, id string) ([]*fastly.ACLEntry, error) {
+	s, err := f.service(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := s.entries[id]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+	return entries, nil
+}
+
+// nextID returns a unique id for a new object, the caller must hold the lock
+func (f *Fake) nextID(prefix string) string {
+	f.ids++
+	return fmt.Sprintf("%s%d", prefix, f.ids)
+}
+
+// storeSnippet gives the snippet an id (unless it has one) and stores it in
+// the version, the caller must hold the lock
+func (f *Fake) storeSnippet(serviceID string, v *version, snippet *fastly.Snippet) {
+	if snippet.ID == "" {
+		snippet.ID = f.nextID("snippet")
+	}
+
+	snippet.ServiceID = serviceID
+	snippet.Version = v.Number
+	v.snippets[snippet.Name] = snippet
+
+	if snippet.Dynamic == 1 {
+		f.services[serviceID].dynamic[snippet.ID] = snippet.Content
+	}
+}
+
+// record counts the call and returns any failure registered via FailOn
+// the caller must hold the lock
+func (f *Fake) record(method string) error {
+	f.calls[method]++
+	return f.failures[method]
+}
+
+func (f *Fake) service(id string) (*service, error) {
+	s, ok := f.services[id]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+	return s, nil
+}
+
+func (f *Fake) version(serviceID string, number int) (*version, error) {
+	s, err := f.service(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := s.versions[number]
+	if !ok {
+		return nil, httpError(http.StatusNotFound)
+	}
+	return v, nil
+}
+
+// unlockedVersion returns the version only if it can still be modified
+func (f *Fake) unlockedVersion(serviceID string, number int) (*version, error) {
+	v, err := f.version(serviceID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Locked {
+		return nil, httpError(http.StatusConflict)
+	}
+	return v, nil
+}
+
+// add creates the next version of the service, copying the settings, VCL
+// files, snippets, dictionaries, ACLs, backends, healthchecks, directors,
+// conditions and domains from the source version when one is provided
+func (s *service) add(serviceID string, source *version) *version {
+	number := 1
+	for n := range s.versions {
+		if n >= number {
+			number = n + 1
+		}
+	}
+
+	v := &version{
+		Version: fastly.Version{
+			Number:    number,
+			ServiceID: serviceID,
+		},
+		settings: fastly.Settings{
+			ServiceID: serviceID,
+			Version:   number,
+		},
+		vcls:     map[string]*fastly.VCL{},
+		snippets: map[string]*fastly.Snippet{},
+		dicts:    map[string]*fastly.Dictionary{},
+		acls:     map[string]*fastly.ACL{},
+		backends: map[string]*fastly.Backend{},
+
+		healthChecks: map[string]*fastly.HealthCheck{},
+		directors:    map[string]*fastly.Director{},
+		conditions:   map[string]*fastly.Condition{},
+		domains:      map[string]*fastly.Domain{},
+		members:      map[string]map[string]bool{},
+	}
+
+	if source != nil {
+		v.settings.DefaultHost = source.settings.DefaultHost
+		v.settings.DefaultTTL = source.settings.DefaultTTL
+
+		for name, vcl := range source.vcls {
+			copied := *vcl
+			copied.Version = number
+			v.vcls[name] = &copied
+		}
+
+		for name, snippet := range source.snippets {
+			copied := *snippet
+			copied.Version = number
+			v.snippets[name] = &copied
+		}
+
+		for name, dict := range source.dicts {
+			copied := *dict
+			copied.Version = number
+			v.dicts[name] = &copied
+		}
+
+		for name, acl := range source.acls {
+			copied := *acl
+			copied.Version = number
+			v.acls[name] = &copied
+		}
+
+		for name, backend := range source.backends {
+			copied := *backend
+			copied.Version = number
+			v.backends[name] = &copied
+		}
+
+		for name, healthCheck := range source.healthChecks {
+			copied := *healthCheck
+			copied.Version = number
+			v.healthChecks[name] = &copied
+		}
+
+		for name, director := range source.directors {
+			copied := *director
+			copied.Version = number
+			v.directors[name] = &copied
+		}
+
+		for name, condition := range source.conditions {
+			copied := *condition
+			copied.Version = number
+			v.conditions[name] = &copied
+		}
+
+		for name, domain := range source.domains {
+			copied := *domain
+			copied.Version = number
+			v.domains[name] = &copied
+		}
+
+		for director, backends := range source.members {
+			v.members[director] = map[string]bool{}
+			for backend := range backends {
+				v.members[director][backend] = true
+			}
+		}
+	}
+
+	s.versions[number] = v
+	return v
+}
+
+// validate mirrors the Fastly requirement that a version with custom VCL
+// files must designate exactly one of them as the main VCL
+func (v *version) validate() (bool, string) {
+	if len(v.vcls) == 0 {
+		return true, ""
+	}
+
+	main := 0
+	for _, vcl := range v.vcls {
+		if vcl.Main {
+			main++
+		}
+	}
+
+	if main != 1 {
+		return false, fmt.Sprintf("expected exactly one main VCL, found %d", main)
+	}
+	return true, ""
+}
+
+// sortedDomains returns copies of the domains of the version sorted by name
+func (v *version) sortedDomains() []*fastly.Domain {
+	names := make([]string, 0, len(v.domains))
+	for name := range v.domains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	domains := make([]*fastly.Domain, 0, len(names))
+	for _, name := range names {
+		copied := *v.domains[name]
+		domains = append(domains, &copied)
+	}
+	return domains
+}
+
+func httpError(status int) error {
+	return &fastly.HTTPError{StatusCode: status}
+}
+
+// setString, setUint and setBool change a field of an update the same way as
+// the Fastly API, where a zero value leaves the field as it is
+
+func setString(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
+
+func setUint(field *uint, value uint) {
+	if value != 0 {
+		*field = value
+	}
+}
+
+func setBool(field *bool, value *fastly.Compatibool) {
+	if value != nil {
+		*field = bool(*value)
+	}
+}
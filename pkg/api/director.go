@@ -0,0 +1,40 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+// Director is a director of a service version as the API lists it, along
+// with the names of its backends
+type Director struct {
+	Name     string              `json:"name"`
+	Comment  string              `json:"comment"`
+	Type     fastly.DirectorType `json:"type"`
+	Quorum   uint                `json:"quorum"`
+	Retries  uint                `json:"retries"`
+	Backends []string            `json:"backends"`
+}
+
+// Directors returns every director of the service version in a single
+// request (go-fastly doesn't decode the backends listed with each director,
+// and otherwise a director's backends can only be found by asking about each
+// backend in turn)
+func Directors(client Requester, service string, version int) ([]Director, error) {
+	path := fmt.Sprintf("/service/%s/version/%d/director", url.PathEscape(service), version)
+	resp, err := client.Get(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var directors []Director
+	if err := json.NewDecoder(resp.Body).Decode(&directors); err != nil {
+		return nil, err
+	}
+
+	return directors, nil
+}
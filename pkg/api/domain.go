@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// DomainCheck is the outcome of Fastly checking the DNS of a domain
+// CNAME is the name Fastly found the domain pointing at (empty when there's
+// none) and Valid reports whether it points at Fastly
+type DomainCheck struct {
+	Name  string
+	CNAME string
+	Valid bool
+}
+
+// CheckDomains asks Fastly to check the DNS of every domain of the service
+// version (go-fastly doesn't provide the check_all endpoint)
+//
+// Each domain is described by the API as a [domain, cname, valid] array
+func CheckDomains(client Requester, service string, version int) ([]DomainCheck, error) {
+	path := fmt.Sprintf("/service/%s/version/%d/domain/check_all", url.PathEscape(service), version)
+	resp, err := client.Get(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var raw [][]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	checks := make([]DomainCheck, 0, len(raw))
+	for _, fields := range raw {
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected domain check %s", fields)
+		}
+
+		var domain struct {
+			Name string `json:"name"`
+		}
+		var cname *string
+		var valid bool
+
+		if err := json.Unmarshal(fields[0], &domain); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(fields[1], &cname); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(fields[2], &valid); err != nil {
+			return nil, err
+		}
+
+		check := DomainCheck{Name: domain.Name, Valid: valid}
+		if cname != nil {
+			check.CNAME = *cname
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
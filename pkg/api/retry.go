@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logger *logrus.Entry
+
+func init() {
+	logger = logrus.WithFields(logrus.Fields{
+		"package": "api",
+	})
+}
+
+// defaults used by NewRetryTransport
+const (
+	DefaultMaxRetries = 5
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// RetryTransport is a http.RoundTripper that retries requests rejected for
+// being rate limited (429) or failing on the server (5xx, only for idempotent
+// methods) using exponential backoff, and pauses requests once the Fastly
+// rate limit has been used up
+//
+// Assign it to the HTTPClient of a *fastly.Client so every API call made by
+// the commands is covered
+type RetryTransport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// exhausted is set once Fastly reports no requests remain until reset
+	mu        sync.Mutex
+	exhausted bool
+	reset     time.Time
+}
+
+// NewRetryTransport wraps the base transport (http.DefaultTransport when nil)
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &RetryTransport{
+		Base:       base,
+		MaxRetries: DefaultMaxRetries,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+//
+// Each retry sends a clone of the request (with its body read again), so the
+// request isn't modified, and the wait before it ends early (with the
+// context's error) once the request's context is done
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	current := req
+
+	for attempt := 0; ; attempt++ {
+		if err := t.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.Base.RoundTrip(current)
+		if err != nil {
+			return nil, err
+		}
+
+		t.recordRateLimit(resp)
+
+		if !retryable(req.Method, resp.StatusCode) || attempt >= t.MaxRetries {
+			return resp, nil
+		}
+
+		// the body has to be sent again, which isn't possible for every request
+		next, err := rewind(req)
+		if err != nil {
+			return resp, nil
+		}
+
+		delay := t.backoff(attempt, resp)
+		resp.Body.Close()
+
+		logger.WithFields(logrus.Fields{
+			"status":  resp.StatusCode,
+			"attempt": attempt + 1,
+			"delay":   delay.String(),
+		}).Debug("retrying request")
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		current = next
+	}
+}
+
+// retryable reports whether the response is worth retrying, a rate limited
+// request (429) was never processed so is always retried, but a server
+// failure (5xx) is only retried for idempotent methods as the change may
+// have been made (e.g. a version cloned) before the request failed
+func retryable(method string, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	if status < 500 {
+		return false
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// errNoRewind is returned by rewind when the body can't be read again
+var errNoRewind = errors.New("the request body can't be sent again")
+
+// rewind returns a clone of the request to send again, with a new copy of
+// its body
+func rewind(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return clone, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, errNoRewind
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone.Body = body
+
+	return clone, nil
+}
+
+// sleep waits for the delay, returning early with the context's error once
+// the context is done
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// backoff returns the delay before the next attempt, honouring any
+// Retry-After header sent with the response (either delay is capped at
+// MaxBackoff, so a server can't stall the CLI indefinitely)
+func (t *RetryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		if time.Duration(seconds) > t.MaxBackoff/time.Second {
+			return t.MaxBackoff
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	delay := t.MinBackoff << uint(attempt)
+	if delay <= 0 || delay > t.MaxBackoff {
+		delay = t.MaxBackoff
+	}
+	return delay
+}
+
+// recordRateLimit keeps track of the rate limit headers sent by Fastly
+func (t *RetryTransport) recordRateLimit(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("Fastly-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.exhausted = remaining <= 0
+	if reset, err := strconv.ParseInt(resp.Header.Get("Fastly-RateLimit-Reset"), 10, 64); err == nil {
+		t.reset = time.Unix(reset, 0)
+	}
+}
+
+// waitForRateLimit pauses until the rate limit resets when there are no
+// requests remaining (the wait is capped at MaxBackoff), the context's error
+// is returned when it's done before then
+func (t *RetryTransport) waitForRateLimit(ctx context.Context) error {
+	t.mu.Lock()
+	exhausted, reset := t.exhausted, t.reset
+	t.mu.Unlock()
+
+	if !exhausted {
+		return nil
+	}
+
+	delay := reset.Sub(time.Now())
+	if reset.IsZero() || delay > t.MaxBackoff {
+		delay = t.MaxBackoff
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	logger.WithField("delay", delay.String()).Debug("rate limit exhausted, waiting for reset")
+	if err := sleep(ctx, delay); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.exhausted = false
+	t.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// server responds with each of the statuses in turn (then 200), recording
+// the body of every request
+type server struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := http.StatusOK
+	if len(s.bodies) < len(s.statuses) {
+		status = s.statuses[len(s.bodies)]
+	}
+	s.bodies = append(s.bodies, string(body))
+
+	w.WriteHeader(status)
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int
+		attempts int
+		status   int
+	}{
+		{
+			name:     "retries a rate limited request",
+			method:   http.MethodPost,
+			statuses: []int{http.StatusTooManyRequests, http.StatusTooManyRequests},
+			attempts: 3,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "retries a failed idempotent request",
+			method:   http.MethodPut,
+			statuses: []int{http.StatusServiceUnavailable},
+			attempts: 2,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "doesn't retry a failed post",
+			method:   http.MethodPost,
+			statuses: []int{http.StatusBadGateway},
+			attempts: 1,
+			status:   http.StatusBadGateway,
+		},
+		{
+			name:     "doesn't retry a client error",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusNotFound},
+			attempts: 1,
+			status:   http.StatusNotFound,
+		},
+		{
+			name:     "gives up after the maximum retries",
+			method:   http.MethodGet,
+			statuses: []int{500, 500, 500, 500},
+			attempts: 3,
+			status:   500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{statuses: tt.statuses}
+			ts := httptest.NewServer(s)
+			defer ts.Close()
+
+			transport := NewRetryTransport(nil)
+			transport.MaxRetries = 2
+			transport.MinBackoff = time.Millisecond
+
+			req, err := http.NewRequest(tt.method, ts.URL, strings.NewReader("name=main"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := req.Body
+
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.status)
+			}
+			if len(s.bodies) != tt.attempts {
+				t.Errorf("got %d attempts, want %d", len(s.bodies), tt.attempts)
+			}
+			for i, b := range s.bodies {
+				if b != "name=main" {
+					t.Errorf("attempt %d sent body %q", i+1, b)
+				}
+			}
+			if req.Body != body {
+				t.Error("the request was modified")
+			}
+		})
+	}
+}
+
+func TestRetryTransportCancel(t *testing.T) {
+	ts := httptest.NewServer(&server{statuses: []int{http.StatusTooManyRequests}})
+	defer ts.Close()
+
+	transport := NewRetryTransport(nil)
+	transport.MinBackoff = time.Hour
+	transport.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := transport.RoundTrip(req.WithContext(ctx))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the backoff wasn't cancelled")
+	}
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		delay      time.Duration
+	}{
+		{name: "first attempt", attempt: 0, delay: time.Second},
+		{name: "doubles each attempt", attempt: 2, delay: 4 * time.Second},
+		{name: "capped at the maximum", attempt: 10, delay: 30 * time.Second},
+		{name: "retry after", attempt: 0, retryAfter: "5", delay: 5 * time.Second},
+		{name: "retry after zero", attempt: 2, retryAfter: "0", delay: 0},
+		{name: "retry after capped at the maximum", attempt: 0, retryAfter: "86400", delay: 30 * time.Second},
+		{name: "retry after overflowing", attempt: 0, retryAfter: "9223372036854775807", delay: 30 * time.Second},
+		{name: "retry after that isn't a number", attempt: 1, retryAfter: "soon", delay: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := NewRetryTransport(nil)
+			transport.MinBackoff = time.Second
+			transport.MaxBackoff = 30 * time.Second
+
+			resp := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+
+			if delay := transport.backoff(tt.attempt, resp); delay != tt.delay {
+				t.Errorf("got delay %s, want %s", delay, tt.delay)
+			}
+		})
+	}
+}
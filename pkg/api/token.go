@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+// Requester is satisfied by *fastly.Client and is used for the endpoints that
+// go-fastly doesn't (yet) provide a dedicated method for
+type Requester interface {
+	Get(string, *fastly.RequestOptions) (*http.Response, error)
+}
+
+// compile time check that the real client satisfies the interface
+var _ Requester = (*fastly.Client)(nil)
+
+// Token describes the API token used to make requests
+// ExpiresAt is empty when the token never expires
+type Token struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	UserID    string   `json:"user_id"`
+	Scope     string   `json:"scope"`
+	Services  []string `json:"services"`
+	ExpiresAt string   `json:"expires_at"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// VerifyToken retrieves the details of the API token used by the client
+// an error (e.g. a 401 HTTPError) is returned when the token isn't valid
+func VerifyToken(client Requester) (*Token, error) {
+	resp, err := client.Get("/tokens/self", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token Token
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Expiry describes when the token expires
+func (t Token) Expiry() string {
+	if t.ExpiresAt == "" {
+		return "never"
+	}
+	return t.ExpiresAt
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+// GetSortedVersions returns all fastly service versions sorted by number
+// (the fastly API doesn't return sorted data)
+func GetSortedVersions(serviceID string, client Client) ([]*fastly.Version, error) {
+	versions, err := client.ListVersions(&fastly.ListVersionsInput{
+		Service: serviceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("There was a problem getting the version list:\n\n%s", err)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Number < versions[j].Number
+	})
+
+	return versions, nil
+}
+
+// GetLatestVCLVersion returns latest fastly service version
+// This service version isn't necessarily the currently active version
+func GetLatestVCLVersion(serviceID string, client Client) (int, error) {
+	// we have to get all the versions and then sort them to find the actual latest
+	versions, err := GetSortedVersions(serviceID, client)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(versions) == 0 {
+		return 0, fmt.Errorf("There are no versions for service %s", serviceID)
+	}
+
+	return versions[len(versions)-1].Number, nil
+}
+
+// GetActiveVersion returns the currently active fastly service version
+// zero is returned when the service has no active version
+func GetActiveVersion(serviceID string, client Client) (int, error) {
+	versions, err := GetSortedVersions(serviceID, client)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range versions {
+		if v.Active {
+			return v.Number, nil
+		}
+	}
+
+	return 0, nil
+}
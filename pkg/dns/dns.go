@@ -0,0 +1,305 @@
+// Dns is a package that resolves the canonical name (CNAME) of a domain,
+// either with the system resolver or by querying a DNS server directly (e.g.
+// a public resolver, or a local stub server when testing).
+
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+)
+
+// Resolver finds the canonical name of a host by following its CNAME
+// records, a host without a CNAME record is its own canonical name
+//
+// *net.Resolver satisfies the interface
+type Resolver interface {
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// System resolves hosts the same way as the rest of the operating system
+var System Resolver = net.DefaultResolver
+
+// DefaultTimeout is how long a Server waits for a response when neither the
+// context nor the server sets a deadline
+const DefaultTimeout = 5 * time.Second
+
+// the record types and response codes used from RFC 1035
+const (
+	typeA      = 1
+	typeCNAME  = 5
+	classINET  = 1
+	rcodeNXDom = 3
+
+	// flagTC is set in the third byte of the header when the response was
+	// truncated (to fit in a UDP packet)
+	flagTC = 0x02
+
+	// maxPointers bounds the compression pointers followed in a single name,
+	// so a malformed response can't loop forever
+	maxPointers = 32
+)
+
+var (
+	errMalformed = errors.New("malformed DNS response")
+	errTruncated = errors.New("truncated DNS response")
+)
+
+// Server resolves hosts by querying a single DNS server over UDP (or TCP when
+// the response is truncated), Address is a host and optional port (the port
+// defaults to 53)
+type Server struct {
+	Address string
+	Timeout time.Duration
+}
+
+// LookupCNAME asks the server for the A records of the host and follows the
+// CNAME records of the answer, as a recursive resolver returns the whole
+// chain of CNAME records along with the addresses (a truncated response is
+// asked for again over TCP)
+//
+// The canonical name is fully qualified (ends with a dot) as with the system
+// resolver, and an unknown host is reported as a *net.DNSError
+func (s Server) LookupCNAME(ctx context.Context, host string) (string, error) {
+	name := fqdn(host)
+
+	address := s.Address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "53")
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	query, err := buildQuery(id, name)
+	if err != nil {
+		return "", &net.DNSError{Err: err.Error(), Name: host, Server: address}
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		timeout := s.Timeout
+		if timeout == 0 {
+			timeout = DefaultTimeout
+		}
+		deadline = time.Now().Add(timeout)
+	}
+
+	msg, err := exchange(ctx, "udp", address, deadline, id, query)
+	if err == nil && truncated(msg) {
+		// the answer didn't fit in a UDP response, so ask again over TCP
+		msg, err = exchange(ctx, "tcp", address, deadline, id, query)
+	}
+	if err != nil {
+		return "", &net.DNSError{Err: err.Error(), Name: host, Server: address, IsTimeout: isTimeout(err)}
+	}
+
+	cname, err := parseResponse(msg, name)
+	if err != nil {
+		return "", &net.DNSError{Err: err.Error(), Name: host, Server: address}
+	}
+	return cname, nil
+}
+
+// exchange sends the query to the server and returns the response to it,
+// over TCP each message is prefixed with its length (RFC 1035 4.2.2)
+func exchange(ctx context.Context, network, address string, deadline time.Time, id uint16, query []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(deadline)
+
+	if network == "tcp" {
+		msg := make([]byte, 2, 2+len(query))
+		binary.BigEndian.PutUint16(msg, uint16(len(query)))
+		if _, err := conn.Write(append(msg, query...)); err != nil {
+			return nil, err
+		}
+
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		resp := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, resp); err != nil {
+			return nil, err
+		}
+
+		if len(resp) < 2 || binary.BigEndian.Uint16(resp) != id {
+			return nil, errMalformed
+		}
+		return resp, nil
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		// a response to an earlier (timed out) query is ignored
+		if n < 2 || binary.BigEndian.Uint16(buf) != id {
+			continue
+		}
+
+		return buf[:n], nil
+	}
+}
+
+// buildQuery encodes a recursive query for the A records of the name
+func buildQuery(id uint16, name string) ([]byte, error) {
+	msg := make([]byte, 12, 512)
+	binary.BigEndian.PutUint16(msg[0:], id)
+	binary.BigEndian.PutUint16(msg[2:], 1<<8) // recursion desired
+	binary.BigEndian.PutUint16(msg[4:], 1)    // a single question
+
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" || len(label) > 63 {
+			return nil, fmt.Errorf("invalid domain name '%s'", name)
+		}
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0, 0, typeA, 0, classINET)
+
+	return msg, nil
+}
+
+// parseResponse follows the CNAME records in the answer section from the
+// name, returning the last name of the chain
+func parseResponse(msg []byte, name string) (string, error) {
+	if len(msg) < 12 {
+		return "", errMalformed
+	}
+
+	if truncated(msg) {
+		return "", errTruncated
+	}
+
+	if rcode := msg[3] & 0x0f; rcode == rcodeNXDom {
+		return "", errors.New("no such host")
+	} else if rcode != 0 {
+		return "", fmt.Errorf("server failure (rcode %d)", rcode)
+	}
+
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	answers := int(binary.BigEndian.Uint16(msg[6:]))
+
+	off := 12
+	for i := 0; i < questions; i++ {
+		_, next, err := readName(msg, off)
+		if err != nil {
+			return "", err
+		}
+		off = next + 4
+	}
+
+	cnames := map[string]string{}
+	for i := 0; i < answers; i++ {
+		owner, next, err := readName(msg, off)
+		if err != nil {
+			return "", err
+		}
+		if next+10 > len(msg) {
+			return "", errMalformed
+		}
+
+		rrType := binary.BigEndian.Uint16(msg[next:])
+		length := int(binary.BigEndian.Uint16(msg[next+8:]))
+		data := next + 10
+		if data+length > len(msg) {
+			return "", errMalformed
+		}
+
+		if rrType == typeCNAME {
+			target, _, err := readName(msg, data)
+			if err != nil {
+				return "", err
+			}
+			cnames[strings.ToLower(owner)] = target
+		}
+
+		off = data + length
+	}
+
+	canonical := name
+	for i := 0; i < len(cnames); i++ {
+		target, ok := cnames[strings.ToLower(canonical)]
+		if !ok {
+			break
+		}
+		canonical = target
+	}
+
+	return canonical, nil
+}
+
+// readName decodes the (possibly compressed) name at off, returning the
+// offset after it
+func readName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	next := -1
+
+	for pointers := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errMalformed
+		}
+
+		length := int(msg[off])
+		switch {
+		case length == 0:
+			if next < 0 {
+				next = off + 1
+			}
+			return strings.Join(labels, ".") + ".", next, nil
+
+		case length&0xc0 == 0xc0:
+			if off+1 >= len(msg) || pointers == maxPointers {
+				return "", 0, errMalformed
+			}
+			if next < 0 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3fff)
+			pointers++
+
+		default:
+			if off+1+length > len(msg) {
+				return "", 0, errMalformed
+			}
+			labels = append(labels, string(msg[off+1:off+1+length]))
+			off += 1 + length
+		}
+	}
+}
+
+// truncated reports whether the TC bit of the response is set
+func truncated(msg []byte) bool {
+	return len(msg) > 2 && msg[2]&flagTC != 0
+}
+
+func fqdn(host string) string {
+	if strings.HasSuffix(host, ".") {
+		return host
+	}
+	return host + "."
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
@@ -0,0 +1,239 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stub answers every query with the response returned by respond
+func stub(t *testing.T, respond func(query []byte) []byte) net.PacketConn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			conn.WriteTo(respond(buf[:n]), addr)
+		}
+	}()
+
+	return conn
+}
+
+// encode returns the uncompressed name
+func encode(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+// pointer returns a compression pointer to the offset
+func pointer(off int) []byte {
+	return []byte{0xc0 | byte(off>>8), byte(off)}
+}
+
+// cname returns a CNAME record of the (encoded) owner and target names
+func cname(owner, target []byte) []byte {
+	record := append([]byte(nil), owner...)
+	record = append(record, 0, typeCNAME, 0, classINET, 0, 0, 0, 60, byte(len(target)>>8), byte(len(target)))
+	return append(record, target...)
+}
+
+// reply returns the response to the query with the header flags and rcode,
+// the question of the query and the answers
+func reply(query []byte, flags, rcode byte, answers ...[]byte) []byte {
+	msg := make([]byte, 12)
+	copy(msg, query[:2])
+	msg[2] = 0x81 | flags // response, recursion desired
+	msg[3] = 0x80 | rcode // recursion available
+	binary.BigEndian.PutUint16(msg[4:], 1)
+	binary.BigEndian.PutUint16(msg[6:], uint16(len(answers)))
+
+	msg = append(msg, query[12:]...)
+	for _, answer := range answers {
+		msg = append(msg, answer...)
+	}
+	return msg
+}
+
+func TestServerLookupCNAME(t *testing.T) {
+	// the query for www.example.com has its name at offset 12 (example.com
+	// at 16) and the answers start at offset 33
+	tests := []struct {
+		name    string
+		respond func(query []byte) []byte
+		cname   string
+		err     string
+	}{
+		{
+			name: "no CNAME",
+			respond: func(q []byte) []byte {
+				return reply(q, 0, 0)
+			},
+			cname: "www.example.com.",
+		},
+		{
+			name: "CNAME chain",
+			respond: func(q []byte) []byte {
+				return reply(q, 0, 0,
+					cname(encode("global.prod.fastly.net"), encode("151.101.1.57.example")),
+					cname(encode("www.example.com"), encode("example.global.ssl.fastly.net")),
+					cname(encode("example.global.ssl.fastly.net"), encode("global.prod.fastly.net")),
+				)
+			},
+			cname: "151.101.1.57.example.",
+		},
+		{
+			name: "compression pointers",
+			respond: func(q []byte) []byte {
+				// the first target is cdn.example.com (pointing at the question's
+				// example.com) and its rdata starts at offset 45
+				return reply(q, 0, 0,
+					cname(pointer(12), append([]byte{3, 'c', 'd', 'n'}, pointer(16)...)),
+					cname(pointer(45), encode("global.prod.fastly.net")),
+				)
+			},
+			cname: "global.prod.fastly.net.",
+		},
+		{
+			name: "case insensitive",
+			respond: func(q []byte) []byte {
+				return reply(q, 0, 0, cname(encode("WWW.Example.COM"), encode("global.prod.fastly.net")))
+			},
+			cname: "global.prod.fastly.net.",
+		},
+		{
+			name: "NXDOMAIN",
+			respond: func(q []byte) []byte {
+				return reply(q, 0, rcodeNXDom)
+			},
+			err: "no such host",
+		},
+		{
+			name: "server failure",
+			respond: func(q []byte) []byte {
+				return reply(q, 0, 2)
+			},
+			err: "server failure (rcode 2)",
+		},
+		{
+			name: "short header",
+			respond: func(q []byte) []byte {
+				return reply(q, 0, 0)[:8]
+			},
+			err: errMalformed.Error(),
+		},
+		{
+			name: "record past the end",
+			respond: func(q []byte) []byte {
+				answer := cname(pointer(12), encode("global.prod.fastly.net"))
+				return reply(q, 0, 0, answer[:len(answer)-4])
+			},
+			err: errMalformed.Error(),
+		},
+		{
+			name: "pointer loop",
+			respond: func(q []byte) []byte {
+				return reply(q, 0, 0, cname(pointer(33), encode("global.prod.fastly.net")))
+			},
+			err: errMalformed.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := stub(t, tt.respond)
+			defer conn.Close()
+
+			s := Server{Address: conn.LocalAddr().String(), Timeout: time.Second}
+			got, err := s.LookupCNAME(context.Background(), "www.example.com")
+
+			if tt.err != "" {
+				dnsErr, ok := err.(*net.DNSError)
+				if !ok || dnsErr.Err != tt.err {
+					t.Fatalf("got %q, %v, want the error %q", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.cname {
+				t.Errorf("got %q, want %q", got, tt.cname)
+			}
+		})
+	}
+}
+
+func TestServerLookupCNAMETruncated(t *testing.T) {
+	conn := stub(t, func(q []byte) []byte {
+		return reply(q, flagTC, 0)
+	})
+	defer conn.Close()
+
+	// the same port is listened on for the query over TCP
+	ln, err := net.Listen("tcp", conn.LocalAddr().String())
+	if err != nil {
+		t.Skipf("unable to listen over TCP on the port of the stub: %s", err)
+	}
+	defer ln.Close()
+
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		var length [2]byte
+		if _, err := io.ReadFull(c, length[:]); err != nil {
+			return
+		}
+		q := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(c, q); err != nil {
+			return
+		}
+
+		resp := reply(q, 0, 0, cname(pointer(12), encode("global.prod.fastly.net")))
+		binary.BigEndian.PutUint16(length[:], uint16(len(resp)))
+		c.Write(append(length[:], resp...))
+	}()
+
+	s := Server{Address: conn.LocalAddr().String(), Timeout: time.Second}
+	got, err := s.LookupCNAME(context.Background(), "www.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "global.prod.fastly.net."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseResponseTruncated(t *testing.T) {
+	query, err := buildQuery(1, "www.example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a truncated response over TCP can't be retried
+	_, err = parseResponse(reply(query, flagTC, 0), "www.example.com.")
+	if err != errTruncated {
+		t.Errorf("got %v, want %v", err, errTruncated)
+	}
+}
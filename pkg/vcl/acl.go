@@ -0,0 +1,812 @@
+package vcl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// ErrMissingACLName is returned when an operation requires an ACL name
+var ErrMissingACLName = errors.New("you must provide an ACL name")
+
+// ErrMissingACLEntry is returned when an operation requires an ACL entry
+var ErrMissingACLEntry = errors.New("you must provide an ip address or cidr block (e.g. 192.0.2.0/24)")
+
+// ACL is an access control list of a service version
+// the entries of an ACL aren't versioned
+type ACL struct {
+	Name string
+	ID   string
+}
+
+// ACLEntry is a single ip address, or a cidr block when Subnet is set
+// a negated entry excludes the addresses from a wider entry
+type ACLEntry struct {
+	ID      string
+	IP      string
+	Subnet  string
+	Negated bool
+	Comment string
+}
+
+// Address returns the ip address or cidr block of the entry
+func (e ACLEntry) Address() string {
+	if e.Subnet == "" {
+		return e.IP
+	}
+	return e.IP + "/" + e.Subnet
+}
+
+// String returns the address of the entry (prefixed with ! when negated)
+func (e ACLEntry) String() string {
+	if e.Negated {
+		return "!" + e.Address()
+	}
+	return e.Address()
+}
+
+// ACLsResult contains the ACLs of the remote service version
+type ACLsResult struct {
+	Service string
+	Version int
+	ACLs    []ACL
+}
+
+// ACLOptions defines the ACL to create or delete and the version to make the
+// change to
+type ACLOptions struct {
+	Target
+
+	Name string
+}
+
+// ACLResult describes the ACL that was (or would be) created or deleted,
+// along with the version it was changed in
+type ACLResult struct {
+	TargetVersion
+	ACL ACL
+}
+
+// EntryOptions identifies an ACL entry, the ACL is looked up by name in
+// Version (zero means the active version, as with dictionary items)
+type EntryOptions struct {
+	Service string
+	ACL     string
+	Version int
+
+	// Entry is an ip address or cidr block, optionally prefixed with !
+	Entry   string
+	Comment string
+}
+
+// EntriesResult contains the entries of an ACL
+type EntriesResult struct {
+	Service string
+	Version int
+	ACL     ACL
+	Entries []ACLEntry
+}
+
+// EntryResult contains a single ACL entry
+type EntryResult struct {
+	Service string
+	Version int
+	ACL     ACL
+	Entry   ACLEntry
+}
+
+// ACLSyncOptions defines the file of entries an ACL should contain
+type ACLSyncOptions struct {
+	Service string
+	ACL     string
+	Version int
+	File    string
+}
+
+// ACLSyncPlan describes the fewest changes needed for the ACL to contain
+// exactly the entries of the file, computing it makes no mutating API calls
+type ACLSyncPlan struct {
+	Service string
+	Version int
+	ACL     ACL
+
+	Add       []ACLEntry
+	Update    []ACLEntryUpdate
+	Remove    []ACLEntry
+	Unchanged int
+
+	// Warnings are the entries of the file that have no effect because of
+	// the other entries (see: overlaps), they don't prevent the sync
+	Warnings []Problem
+}
+
+// Empty reports whether the plan has no changes to apply
+func (p ACLSyncPlan) Empty() bool {
+	return len(p.Add) == 0 && len(p.Update) == 0 && len(p.Remove) == 0
+}
+
+// ACLEntryUpdate is an entry whose negation or comment differs from the file
+type ACLEntryUpdate struct {
+	Old ACLEntry
+	New ACLEntry
+}
+
+// ACLSyncResult contains the outcome of applying an ACLSyncPlan
+type ACLSyncResult struct {
+	Service string
+	ACL     ACL
+
+	// Batches is the number of batches applied out of Total
+	Batches int
+	Total   int
+
+	Added   int
+	Updated int
+	Removed int
+}
+
+// ListACLs returns every ACL in the remote service version
+func ListACLs(ctx context.Context, client api.Client, opts ListOptions) (*ACLsResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	acls, err := remoteACLs(opts.Service, selectedVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ACLsResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		ACLs:    acls,
+	}, nil
+}
+
+// PlanCreateACL describes which version CreateACL would create the ACL in
+// without making any changes to the service
+func PlanCreateACL(ctx context.Context, client api.Client, opts ACLOptions) (*ACLResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingACLName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := findACL(opts.Service, target.Version, opts.Name, client); err == nil {
+		return nil, fmt.Errorf("the ACL '%s' already exists in version %d", opts.Name, target.Version)
+	}
+
+	return &ACLResult{
+		TargetVersion: *target,
+		ACL:           ACL{Name: opts.Name},
+	}, nil
+}
+
+// CreateACL creates an empty ACL in the target version
+func CreateACL(ctx context.Context, client api.Client, opts ACLOptions) (*ACLResult, error) {
+	plan, err := PlanCreateACL(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	acl, err := client.CreateACL(&fastly.CreateACLInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the ACL '%s' in version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &ACLResult{
+		TargetVersion: *target,
+		ACL:           ACL{Name: acl.Name, ID: acl.ID},
+	}, nil
+}
+
+// PlanDeleteACL describes which version DeleteACL would delete the ACL from
+// without making any changes to the service
+func PlanDeleteACL(ctx context.Context, client api.Client, opts ACLOptions) (*ACLResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingACLName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	acl, err := findACL(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ACLResult{
+		TargetVersion: *target,
+		ACL:           *acl,
+	}, nil
+}
+
+// DeleteACL deletes the ACL (and so its entries) from the target version
+func DeleteACL(ctx context.Context, client api.Client, opts ACLOptions) (*ACLResult, error) {
+	plan, err := PlanDeleteACL(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteACL(&fastly.DeleteACLInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete the ACL '%s' from version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &ACLResult{
+		TargetVersion: *target,
+		ACL:           plan.ACL,
+	}, nil
+}
+
+// ACLEntries returns every entry of the ACL sorted by address
+func ACLEntries(ctx context.Context, client api.Client, opts EntryOptions) (*EntriesResult, error) {
+	selectedVersion, acl, err := lookupACL(ctx, opts, client)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := remoteEntries(opts.Service, acl, client)
+	if err != nil {
+		return nil, err
+	}
+
+	sortEntries(entries)
+
+	return &EntriesResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		ACL:     *acl,
+		Entries: entries,
+	}, nil
+}
+
+// PlanAddACLEntry validates the entry that AddACLEntry would add without
+// making any changes to the ACL
+func PlanAddACLEntry(ctx context.Context, client api.Client, opts EntryOptions) (*EntryResult, error) {
+	entry, err := ParseACLEntry(opts.Entry)
+	if err != nil {
+		return nil, err
+	}
+	entry.Comment = opts.Comment
+
+	result, existing, err := findEntry(ctx, opts, entry, client)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%s is already in the ACL '%s'", existing, result.ACL.Name)
+	}
+
+	return result, nil
+}
+
+// AddACLEntry adds the entry to the ACL
+// the change is live immediately (entries aren't versioned)
+func AddACLEntry(ctx context.Context, client api.Client, opts EntryOptions) (*EntryResult, error) {
+	result, err := PlanAddACLEntry(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := client.CreateACLEntry(&fastly.CreateACLEntryInput{
+		Service: opts.Service,
+		ACL:     result.ACL.ID,
+		IP:      result.Entry.IP,
+		Subnet:  result.Entry.Subnet,
+		Negated: fastly.Compatibool(result.Entry.Negated),
+		Comment: result.Entry.Comment,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to add %s to the ACL '%s': %s", result.Entry, result.ACL.Name, err)
+	}
+
+	result.Entry = aclEntry(entry)
+	return result, nil
+}
+
+// PlanRemoveACLEntry finds the entry that RemoveACLEntry would remove without
+// making any changes to the ACL
+func PlanRemoveACLEntry(ctx context.Context, client api.Client, opts EntryOptions) (*EntryResult, error) {
+	entry, err := ParseACLEntry(opts.Entry)
+	if err != nil {
+		return nil, err
+	}
+
+	result, existing, err := findEntry(ctx, opts, entry, client)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("%s isn't in the ACL '%s'", entry.Address(), result.ACL.Name)
+	}
+
+	result.Entry = *existing
+	return result, nil
+}
+
+// RemoveACLEntry removes the entry (matched by its address) from the ACL
+// the change is live immediately (entries aren't versioned)
+func RemoveACLEntry(ctx context.Context, client api.Client, opts EntryOptions) (*EntryResult, error) {
+	result, err := PlanRemoveACLEntry(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteACLEntry(&fastly.DeleteACLEntryInput{
+		Service: opts.Service,
+		ACL:     result.ACL.ID,
+		ID:      result.Entry.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to remove %s from the ACL '%s': %s", result.Entry, result.ACL.Name, err)
+	}
+
+	return result, nil
+}
+
+// PlanACLSync compares the entries of the file against the ACL, entries are
+// matched by address so only their negation or comment is ever updated, and
+// entries missing from the file are planned for removal
+func PlanACLSync(ctx context.Context, client api.Client, opts ACLSyncOptions) (*ACLSyncPlan, error) {
+	local, err := ReadACLEntries(opts.File)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedVersion, acl, err := lookupACL(ctx, EntryOptions{
+		Service: opts.Service,
+		ACL:     opts.ACL,
+		Version: opts.Version,
+	}, client)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := remoteEntries(opts.Service, acl, client)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ACLSyncPlan{
+		Service:  opts.Service,
+		Version:  selectedVersion,
+		ACL:      *acl,
+		Warnings: overlaps(local),
+	}
+
+	// any duplicate remote entries are removed
+	remote := map[string]ACLEntry{}
+	for _, entry := range entries {
+		if _, exists := remote[entry.Address()]; exists {
+			plan.Remove = append(plan.Remove, entry)
+			continue
+		}
+		remote[entry.Address()] = entry
+	}
+
+	wanted := map[string]bool{}
+	for _, entry := range local {
+		wanted[entry.Address()] = true
+
+		old, exists := remote[entry.Address()]
+		switch {
+		case !exists:
+			plan.Add = append(plan.Add, entry)
+		case old.Negated != entry.Negated || old.Comment != entry.Comment:
+			entry.ID = old.ID
+			plan.Update = append(plan.Update, ACLEntryUpdate{Old: old, New: entry})
+		default:
+			plan.Unchanged++
+		}
+	}
+
+	for _, entry := range entries {
+		if !wanted[entry.Address()] && remote[entry.Address()].ID == entry.ID {
+			plan.Remove = append(plan.Remove, entry)
+		}
+	}
+
+	sortEntries(plan.Remove)
+
+	return plan, nil
+}
+
+// ApplyACLSync makes the changes described by the plan in batches of up to
+// fastly.BatchModifyMaximumOperations entries
+//
+// each batch is applied atomically, when one fails the result describes the
+// batches that were already applied
+func ApplyACLSync(ctx context.Context, client api.Client, plan *ACLSyncPlan) (*ACLSyncResult, error) {
+	var ops []*fastly.BatchACLEntry
+
+	for _, entry := range plan.Add {
+		ops = append(ops, batchEntry(fastly.CreateBatchOperation, entry))
+	}
+	for _, update := range plan.Update {
+		ops = append(ops, batchEntry(fastly.UpdateBatchOperation, update.New))
+	}
+	for _, entry := range plan.Remove {
+		ops = append(ops, &fastly.BatchACLEntry{Operation: fastly.DeleteBatchOperation, ID: entry.ID})
+	}
+
+	result := &ACLSyncResult{
+		Service: plan.Service,
+		ACL:     plan.ACL,
+		Total:   (len(ops) + fastly.BatchModifyMaximumOperations - 1) / fastly.BatchModifyMaximumOperations,
+	}
+
+	for start := 0; start < len(ops); start += fastly.BatchModifyMaximumOperations {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		end := start + fastly.BatchModifyMaximumOperations
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		err := client.BatchModifyACLEntries(&fastly.BatchModifyACLEntriesInput{
+			Service: plan.Service,
+			ACL:     plan.ACL.ID,
+			Entries: ops[start:end],
+		})
+		if err != nil {
+			return result, fmt.Errorf("batch %d of %d failed (%d batch(es) were applied): %s", result.Batches+1, result.Total, result.Batches, err)
+		}
+
+		result.Batches++
+		for _, op := range ops[start:end] {
+			switch op.Operation {
+			case fastly.CreateBatchOperation:
+				result.Added++
+			case fastly.UpdateBatchOperation:
+				result.Updated++
+			case fastly.DeleteBatchOperation:
+				result.Removed++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ParseACLEntry parses an ip address or cidr block, optionally prefixed with
+// ! to negate it, into its normalised form: no subnet for a single address
+// (e.g. 192.0.2.7/32), a cidr block with host bits set (e.g. 192.0.2.7/24)
+// is rejected rather than silently widened to its network address
+func ParseACLEntry(s string) (ACLEntry, error) {
+	var entry ACLEntry
+
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "!") {
+		entry.Negated = true
+		s = strings.TrimSpace(s[1:])
+	}
+
+	if s == "" {
+		return entry, ErrMissingACLEntry
+	}
+
+	address := s
+	if !strings.Contains(address, "/") {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return entry, fmt.Errorf("'%s' isn't a valid ip address or cidr block", s)
+		}
+
+		address += "/128"
+		if ip.To4() != nil {
+			address = ip.To4().String() + "/32"
+		}
+	}
+
+	ip, network, err := net.ParseCIDR(address)
+	if err != nil {
+		return entry, fmt.Errorf("'%s' isn't a valid ip address or cidr block", s)
+	}
+	if !ip.Equal(network.IP) {
+		return entry, fmt.Errorf("'%s' has host bits set (did you mean %s?)", s, network)
+	}
+
+	ones, bits := network.Mask.Size()
+	entry.IP = network.IP.String()
+	if ones != bits {
+		entry.Subnet = strconv.Itoa(ones)
+	}
+
+	return entry, nil
+}
+
+// ReadACLEntries reads the ACL entries of a local file, which lists an ip
+// address or cidr block per line (prefixed with ! to negate it), anything
+// after a # is a comment, which is given to the entry on the same line
+func ReadACLEntries(path string) ([]ACLEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []ACLEntry
+	lines := map[string]int{}
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line, comment := scanner.Text(), ""
+		if i := strings.Index(line, "#"); i >= 0 {
+			line, comment = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		entry, err := ParseACLEntry(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+		entry.Comment = comment
+
+		if previous, exists := lines[entry.Address()]; exists {
+			return nil, fmt.Errorf("line %d: %s is already listed on line %d", n, entry.Address(), previous)
+		}
+		lines[entry.Address()] = n
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// lookupACL finds the ACL of the entries in the same version as the
+// dictionary items (see: liveVersion)
+func lookupACL(ctx context.Context, opts EntryOptions, client api.Client) (int, *ACL, error) {
+	if opts.ACL == "" {
+		return 0, nil, ErrMissingACLName
+	}
+
+	selectedVersion, err := liveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	acl, err := findACL(opts.Service, selectedVersion, opts.ACL, client)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return selectedVersion, acl, nil
+}
+
+// overlaps reports the entries that have no effect, an ip address matches the
+// most specific entry that contains it, so an entry is redundant when the
+// most specific other entry containing it has the same negation, and a
+// negated entry that no other entry contains excludes nothing
+func overlaps(entries []ACLEntry) []Problem {
+	networks := make([]*net.IPNet, len(entries))
+	for i, entry := range entries {
+		networks[i] = entry.network()
+	}
+
+	var problems []Problem
+	for i, entry := range entries {
+		closest := -1
+		for j := range entries {
+			if i == j || !contains(networks[j], networks[i]) {
+				continue
+			}
+			if closest == -1 || contains(networks[closest], networks[j]) {
+				closest = j
+			}
+		}
+
+		object := fmt.Sprintf("entry '%s'", entry)
+		switch {
+		case closest == -1 && entry.Negated:
+			problems = append(problems, Problem{Object: object, Message: "doesn't exclude addresses any other entry includes", Warning: true})
+		case closest != -1 && entries[closest].Negated == entry.Negated:
+			problems = append(problems, Problem{Object: object, Message: fmt.Sprintf("is already covered by the entry '%s'", entries[closest]), Warning: true})
+		}
+	}
+
+	return problems
+}
+
+// network returns the cidr block of the entry (a single address is a /32 or
+// a /128 block)
+func (e ACLEntry) network() *net.IPNet {
+	address := e.Address()
+	if e.Subnet == "" {
+		address += "/128"
+		if ip := net.ParseIP(e.IP); ip != nil && ip.To4() != nil {
+			address = ip.To4().String() + "/32"
+		}
+	}
+
+	_, network, err := net.ParseCIDR(address)
+	if err != nil {
+		return nil
+	}
+	return network
+}
+
+// contains reports whether the outer cidr block contains the inner one,
+// blocks of different ip versions never contain each other
+func contains(outer, inner *net.IPNet) bool {
+	if outer == nil || inner == nil || len(outer.IP) != len(inner.IP) {
+		return false
+	}
+
+	outerOnes, _ := outer.Mask.Size()
+	innerOnes, _ := inner.Mask.Size()
+	return outerOnes <= innerOnes && outer.Contains(inner.IP)
+}
+
+// findEntry returns the remote entry with the same address as the entry (nil
+// when there isn't one), along with a result describing the entry
+func findEntry(ctx context.Context, opts EntryOptions, entry ACLEntry, client api.Client) (*EntryResult, *ACLEntry, error) {
+	selectedVersion, acl, err := lookupACL(ctx, opts, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := remoteEntries(opts.Service, acl, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &EntryResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		ACL:     *acl,
+		Entry:   entry,
+	}
+
+	for _, remote := range entries {
+		if remote.Address() == entry.Address() {
+			return result, &remote, nil
+		}
+	}
+
+	return result, nil, nil
+}
+
+func findACL(service string, version int, name string, client api.Client) (*ACL, error) {
+	acls, err := remoteACLs(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, acl := range acls {
+		if acl.Name == name {
+			return &acl, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the ACL '%s' doesn't exist in version %d", name, version)
+}
+
+func remoteACLs(service string, version int, client api.Client) ([]ACL, error) {
+	list, err := client.ListACLs(&fastly.ListACLsInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve list of ACLs for version %d: %s", version, err)
+	}
+
+	acls := make([]ACL, 0, len(list))
+	for _, acl := range list {
+		acls = append(acls, ACL{Name: acl.Name, ID: acl.ID})
+	}
+
+	sort.Slice(acls, func(i, j int) bool {
+		return acls[i].Name < acls[j].Name
+	})
+
+	return acls, nil
+}
+
+// remoteEntries returns the entries of the ACL in their normalised form (see:
+// ParseACLEntry), an entry that can't be parsed is left as it is
+func remoteEntries(service string, acl *ACL, client api.Client) ([]ACLEntry, error) {
+	list, err := client.ListACLEntries(&fastly.ListACLEntriesInput{
+		Service: service,
+		ACL:     acl.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the entries of the ACL '%s': %s", acl.Name, err)
+	}
+
+	entries := make([]ACLEntry, 0, len(list))
+	for _, e := range list {
+		entries = append(entries, aclEntry(e))
+	}
+
+	return entries, nil
+}
+
+func aclEntry(e *fastly.ACLEntry) ACLEntry {
+	entry := ACLEntry{IP: e.IP, Subnet: e.Subnet}
+	if normalised, err := ParseACLEntry(entry.Address()); err == nil {
+		entry = normalised
+	}
+
+	entry.ID = e.ID
+	entry.Negated = e.Negated
+	entry.Comment = e.Comment
+
+	return entry
+}
+
+func batchEntry(op fastly.BatchOperation, entry ACLEntry) *fastly.BatchACLEntry {
+	return &fastly.BatchACLEntry{
+		Operation: op,
+		ID:        entry.ID,
+		IP:        entry.IP,
+		Subnet:    entry.Subnet,
+		Negated:   entry.Negated,
+		Comment:   entry.Comment,
+	}
+}
+
+// sortEntries sorts the entries by address (ipv4 before ipv6)
+func sortEntries(entries []ACLEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := net.ParseIP(entries[i].IP), net.ParseIP(entries[j].IP)
+		if a == nil || b == nil {
+			return entries[i].Address() < entries[j].Address()
+		}
+
+		if a4, b4 := a.To4() != nil, b.To4() != nil; a4 != b4 {
+			return a4
+		}
+
+		if c := bytes.Compare(a.To16(), b.To16()); c != 0 {
+			return c < 0
+		}
+
+		x, _ := strconv.Atoi(entries[i].Subnet)
+		y, _ := strconv.Atoi(entries[j].Subnet)
+		return x < y
+	})
+}
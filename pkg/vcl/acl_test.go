@@ -0,0 +1,249 @@
+package vcl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+func TestParseACLEntry(t *testing.T) {
+	tests := []struct {
+		in    string
+		entry ACLEntry
+		err   bool
+	}{
+		{in: "192.0.2.7", entry: ACLEntry{IP: "192.0.2.7"}},
+		{in: "192.0.2.7/32", entry: ACLEntry{IP: "192.0.2.7"}},
+		{in: "192.0.2.0/24", entry: ACLEntry{IP: "192.0.2.0", Subnet: "24"}},
+		{in: "192.0.2.7/24", err: true},
+		{in: " ! 192.0.2.0/24 ", entry: ACLEntry{IP: "192.0.2.0", Subnet: "24", Negated: true}},
+		{in: "::ffff:192.0.2.7", entry: ACLEntry{IP: "192.0.2.7"}},
+		{in: "2001:db8::1", entry: ACLEntry{IP: "2001:db8::1"}},
+		{in: "2001:db8::/48", entry: ACLEntry{IP: "2001:db8::", Subnet: "48"}},
+		{in: "2001:db8::1/48", err: true},
+		{in: "", err: true},
+		{in: "!", err: true},
+		{in: "192.0.2", err: true},
+		{in: "192.0.2.7/33", err: true},
+		{in: "example.com", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			entry, err := ParseACLEntry(tt.in)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", entry)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if entry != tt.entry {
+				t.Errorf("got %+v, want %+v", entry, tt.entry)
+			}
+		})
+	}
+}
+
+func TestReadACLEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		entries []ACLEntry
+		err     string
+	}{
+		{
+			name:    "entries and comments",
+			content: "# the blocklist\n\n192.0.2.7 # scraper\n!198.51.100.0/24\n  203.0.113.0/24  #  the office  \n",
+			entries: []ACLEntry{
+				{IP: "192.0.2.7", Comment: "scraper"},
+				{IP: "198.51.100.0", Subnet: "24", Negated: true},
+				{IP: "203.0.113.0", Subnet: "24", Comment: "the office"},
+			},
+		},
+		{
+			name:    "empty file",
+			content: "# nothing yet\n",
+		},
+		{
+			name:    "invalid entry",
+			content: "192.0.2.7\nnot-an-ip\n",
+			err:     "line 2: 'not-an-ip' isn't a valid ip address or cidr block",
+		},
+		{
+			name:    "host bits set",
+			content: "192.0.2.0/24\n203.0.113.9/24\n",
+			err:     "line 2: '203.0.113.9/24' has host bits set (did you mean 203.0.113.0/24?)",
+		},
+		{
+			name:    "duplicate after normalising",
+			content: "192.0.2.7\n!192.0.2.7/32\n",
+			err:     "line 2: 192.0.2.7 is already listed on line 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeDir(t, map[string]string{"entries.txt": tt.content})
+			defer os.RemoveAll(dir)
+
+			entries, err := ReadACLEntries(filepath.Join(dir, "entries.txt"))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(entries, tt.entries) {
+				t.Errorf("got %+v, want %+v", entries, tt.entries)
+			}
+		})
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []string
+		problems []Problem
+	}{
+		{
+			name:    "unrelated entries",
+			entries: []string{"192.0.2.0/24", "198.51.100.7", "2001:db8::/32"},
+		},
+		{
+			name:    "exclusion from a wider entry",
+			entries: []string{"192.0.2.0/24", "!192.0.2.7"},
+		},
+		{
+			name:    "inclusion within an exclusion",
+			entries: []string{"10.0.0.0/8", "!10.1.0.0/16", "10.1.2.0/24"},
+		},
+		{
+			name:    "contained by an entry with the same negation",
+			entries: []string{"192.0.2.0/24", "192.0.2.128/25", "192.0.2.7"},
+			problems: []Problem{
+				{Object: "entry '192.0.2.128/25'", Message: "is already covered by the entry '192.0.2.0/24'", Warning: true},
+				{Object: "entry '192.0.2.7'", Message: "is already covered by the entry '192.0.2.0/24'", Warning: true},
+			},
+		},
+		{
+			name:    "covered by the most specific entry",
+			entries: []string{"10.0.0.0/8", "!10.1.0.0/16", "!10.1.2.0/24"},
+			problems: []Problem{
+				{Object: "entry '!10.1.2.0/24'", Message: "is already covered by the entry '!10.1.0.0/16'", Warning: true},
+			},
+		},
+		{
+			name:    "exclusion without a wider entry",
+			entries: []string{"192.0.2.0/24", "!198.51.100.7"},
+			problems: []Problem{
+				{Object: "entry '!198.51.100.7'", Message: "doesn't exclude addresses any other entry includes", Warning: true},
+			},
+		},
+		{
+			name:    "ip versions don't overlap",
+			entries: []string{"::/0", "!192.0.2.7"},
+			problems: []Problem{
+				{Object: "entry '!192.0.2.7'", Message: "doesn't exclude addresses any other entry includes", Warning: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []ACLEntry
+			for _, s := range tt.entries {
+				entry, err := ParseACLEntry(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				entries = append(entries, entry)
+			}
+
+			if got := overlaps(entries); !reflect.DeepEqual(got, tt.problems) {
+				t.Errorf("got problems %+v, want %+v", got, tt.problems)
+			}
+		})
+	}
+}
+
+func TestPlanACLSync(t *testing.T) {
+	f := newService(t, nil)
+
+	// the ACL only exists in the active version, not the latest version
+	if _, err := f.ActivateVersion(&fastly.ActivateVersionInput{Service: "svc", Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.CloneVersion(&fastly.CloneVersionInput{Service: "svc", Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := f.SetACL("svc", 1, "blocklist", []fastly.ACLEntry{
+		{IP: "192.0.2.0", Subnet: "24"},
+		{IP: "198.51.100.7", Comment: "scraper"},
+		{IP: "203.0.113.7"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := writeDir(t, map[string]string{
+		"entries.txt": "192.0.2.0/24\n192.0.2.128/25\n!198.51.100.7 # scraper\n",
+	})
+	defer os.RemoveAll(dir)
+
+	plan, err := PlanACLSync(context.Background(), f, ACLSyncOptions{
+		Service: "svc",
+		ACL:     "blocklist",
+		File:    filepath.Join(dir, "entries.txt"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.Version != 1 {
+		t.Errorf("got version %d, want the active version 1", plan.Version)
+	}
+
+	var added, updated, removed []string
+	for _, entry := range plan.Add {
+		added = append(added, entry.String())
+	}
+	for _, update := range plan.Update {
+		updated = append(updated, update.New.String())
+	}
+	for _, entry := range plan.Remove {
+		removed = append(removed, entry.String())
+	}
+	if want := []string{"192.0.2.128/25"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("got added %v, want %v", added, want)
+	}
+	if want := []string{"!198.51.100.7"}; !reflect.DeepEqual(updated, want) {
+		t.Errorf("got updated %v, want %v", updated, want)
+	}
+	if want := []string{"203.0.113.7"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("got removed %v, want %v", removed, want)
+	}
+	if plan.Unchanged != 1 {
+		t.Errorf("got %d unchanged, want 1", plan.Unchanged)
+	}
+
+	problems := []Problem{
+		{Object: "entry '192.0.2.128/25'", Message: "is already covered by the entry '192.0.2.0/24'", Warning: true},
+		{Object: "entry '!198.51.100.7'", Message: "doesn't exclude addresses any other entry includes", Warning: true},
+	}
+	if !reflect.DeepEqual(plan.Warnings, problems) {
+		t.Errorf("got warnings %+v, want %+v", plan.Warnings, problems)
+	}
+}
@@ -0,0 +1,751 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// ErrMissingBackendName is returned when an operation requires a backend name
+var ErrMissingBackendName = errors.New("you must provide a backend name")
+
+// ErrMissingBackendAddress is returned when a backend is created without an
+// address to connect to
+var ErrMissingBackendAddress = errors.New("you must provide the address (hostname or ip) of the backend")
+
+// ErrMissingFromVersion is reported when the backends are compared without
+// the version to compare from
+var ErrMissingFromVersion = errors.New("you must provide the version to compare the backends from")
+
+// TLSVersions are the TLS versions a backend connection can be limited to
+var TLSVersions = []string{"1.0", "1.1", "1.2", "1.3"}
+
+// Backend is an origin server of a service version
+type Backend struct {
+	Name    string
+	Comment string
+
+	Address      string
+	Port         uint
+	OverrideHost string
+
+	UseSSL          bool
+	SSLCheckCert    bool
+	SSLCertHostname string
+	SSLSNIHostname  string
+	MinTLSVersion   string
+	MaxTLSVersion   string
+
+	ConnectTimeout      uint
+	FirstByteTimeout    uint
+	BetweenBytesTimeout uint
+	MaxConn             uint
+
+	Weight           uint
+	AutoLoadbalance  bool
+	HealthCheck      string
+	RequestCondition string
+	Shield           string
+}
+
+// BackendField is a single setting of a backend rendered as text
+type BackendField struct {
+	Name  string
+	Value string
+}
+
+// Fields returns the settings of the backend (other than its name) in the
+// order they're displayed and compared
+func (b Backend) Fields() []BackendField {
+	return []BackendField{
+		{"address", b.Address},
+		{"port", formatUint(b.Port)},
+		{"override_host", b.OverrideHost},
+		{"use_ssl", strconv.FormatBool(b.UseSSL)},
+		{"ssl_check_cert", strconv.FormatBool(b.SSLCheckCert)},
+		{"ssl_cert_hostname", b.SSLCertHostname},
+		{"ssl_sni_hostname", b.SSLSNIHostname},
+		{"min_tls_version", b.MinTLSVersion},
+		{"max_tls_version", b.MaxTLSVersion},
+		{"connect_timeout", formatUint(b.ConnectTimeout)},
+		{"first_byte_timeout", formatUint(b.FirstByteTimeout)},
+		{"between_bytes_timeout", formatUint(b.BetweenBytesTimeout)},
+		{"max_conn", formatUint(b.MaxConn)},
+		{"weight", formatUint(b.Weight)},
+		{"auto_loadbalance", strconv.FormatBool(b.AutoLoadbalance)},
+		{"healthcheck", b.HealthCheck},
+		{"request_condition", b.RequestCondition},
+		{"shield", b.Shield},
+		{"comment", b.Comment},
+	}
+}
+
+// FieldChange is a backend setting whose value differs, From or To is empty
+// when the backend doesn't exist on that side (or the setting is unset)
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// String describes the change (e.g. port: 80 -> 443)
+func (fc FieldChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", fc.Field, quoteEmpty(fc.From), quoteEmpty(fc.To))
+}
+
+// BackendSettings are the backend settings to create or update with, only
+// the settings that aren't nil are changed
+//
+// note: as with the Fastly API an update can't clear a setting, e.g. setting
+// the healthcheck or request condition to an empty string leaves it unchanged
+type BackendSettings struct {
+	Address      *string
+	Port         *uint
+	OverrideHost *string
+
+	UseSSL          *bool
+	SSLCheckCert    *bool
+	SSLCertHostname *string
+	SSLSNIHostname  *string
+	MinTLSVersion   *string
+	MaxTLSVersion   *string
+
+	ConnectTimeout      *uint
+	FirstByteTimeout    *uint
+	BetweenBytesTimeout *uint
+	MaxConn             *uint
+
+	Weight           *uint
+	AutoLoadbalance  *bool
+	HealthCheck      *string
+	RequestCondition *string
+	Shield           *string
+}
+
+// BackendsResult contains the backends of the remote service version
+type BackendsResult struct {
+	Service  string
+	Version  int
+	Backends []Backend
+}
+
+// BackendShowOptions identifies a backend in Version (zero means the latest
+// version, as with List)
+type BackendShowOptions struct {
+	Service string
+	Version int
+	Name    string
+}
+
+// BackendShowResult contains a single backend of the remote service version
+type BackendShowResult struct {
+	Service string
+	Version int
+	Backend Backend
+}
+
+// BackendOptions defines the backend to create, update or delete and the
+// version to make the change to
+type BackendOptions struct {
+	Target
+
+	Name     string
+	Settings BackendSettings
+}
+
+// BackendResult describes the backend that was (or would be) created,
+// updated or deleted, along with the version it was changed in
+//
+// Changes describes the settings an update changes, when it's empty the
+// update has nothing to do and no version is cloned, and Warnings are the
+// problems with the references the change introduces (see: References)
+type BackendResult struct {
+	TargetVersion
+	Backend  Backend
+	Changes  []FieldChange
+	Warnings []Problem
+}
+
+// BackendDiffOptions defines the remote service versions to compare the
+// backends of (zero means the latest version)
+type BackendDiffOptions struct {
+	Service string
+	From    int
+	To      int
+}
+
+// BackendDiffResult contains the backends that differ between two versions
+type BackendDiffResult struct {
+	Service  string
+	From     int
+	To       int
+	Backends []BackendDiff
+}
+
+// Differences returns the number of backends that differ
+func (r BackendDiffResult) Differences() int {
+	return len(r.Backends)
+}
+
+// BackendDiff is the comparison of the backend of a name between two
+// versions, From is nil when the backend was added and To is nil when it was
+// removed
+type BackendDiff struct {
+	Name    string
+	From    *Backend
+	To      *Backend
+	Changes []FieldChange
+}
+
+// ListBackends returns every backend in the remote service version
+func ListBackends(ctx context.Context, client api.Client, opts ListOptions) (*BackendsResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	backends, err := remoteBackends(opts.Service, selectedVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BackendsResult{
+		Service:  opts.Service,
+		Version:  selectedVersion,
+		Backends: backends,
+	}, nil
+}
+
+// GetBackend returns the backend of the remote service version
+func GetBackend(ctx context.Context, client api.Client, opts BackendShowOptions) (*BackendShowResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingBackendName
+	}
+
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := findBackend(opts.Service, selectedVersion, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BackendShowResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Backend: *found,
+	}, nil
+}
+
+// PlanCreateBackend validates the backend (and the healthcheck and request
+// condition it references) that CreateBackend would create, and which
+// version it would be created in, without making any changes to the service
+func PlanCreateBackend(ctx context.Context, client api.Client, opts BackendOptions) (*BackendResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingBackendName
+	}
+	if opts.Settings.Address == nil || *opts.Settings.Address == "" {
+		return nil, ErrMissingBackendAddress
+	}
+
+	planned := Backend{Name: opts.Name}
+	opts.Settings.apply(&planned)
+	problems, err := validateBackend(planned, opts.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := findBackend(opts.Service, target.Version, opts.Name, client); err == nil {
+		return nil, fmt.Errorf("the backend '%s' already exists in version %d", opts.Name, target.Version)
+	}
+
+	warnings, err := checkReferences(opts.Service, target.Version, client, func(r *References) {
+		r.setBackend(planned)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BackendResult{
+		TargetVersion: *target,
+		Backend:       planned,
+		Changes:       fieldChanges(nil, &planned),
+		Warnings:      append(problems, warnings...),
+	}, nil
+}
+
+// CreateBackend creates the backend in the target version
+func CreateBackend(ctx context.Context, client api.Client, opts BackendOptions) (*BackendResult, error) {
+	plan, err := PlanCreateBackend(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	s := opts.Settings
+	b := plan.Backend
+	created, err := client.CreateBackend(&fastly.CreateBackendInput{
+		Service:             opts.Service,
+		Version:             target.Version,
+		Name:                opts.Name,
+		Address:             b.Address,
+		Port:                b.Port,
+		OverrideHost:        b.OverrideHost,
+		UseSSL:              compatibool(s.UseSSL),
+		SSLCheckCert:        compatibool(s.SSLCheckCert),
+		SSLCertHostname:     b.SSLCertHostname,
+		SSLSNIHostname:      b.SSLSNIHostname,
+		MinTLSVersion:       b.MinTLSVersion,
+		MaxTLSVersion:       b.MaxTLSVersion,
+		ConnectTimeout:      b.ConnectTimeout,
+		FirstByteTimeout:    b.FirstByteTimeout,
+		BetweenBytesTimeout: b.BetweenBytesTimeout,
+		MaxConn:             b.MaxConn,
+		Weight:              b.Weight,
+		AutoLoadbalance:     compatibool(s.AutoLoadbalance),
+		HealthCheck:         b.HealthCheck,
+		RequestCondition:    b.RequestCondition,
+		Shield:              b.Shield,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the backend '%s' in version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &BackendResult{
+		TargetVersion: *target,
+		Backend:       backend(created),
+		Changes:       plan.Changes,
+		Warnings:      plan.Warnings,
+	}, nil
+}
+
+// PlanUpdateBackend describes the settings UpdateBackend would change, and
+// which version it would change them in, without making any changes to the
+// service
+func PlanUpdateBackend(ctx context.Context, client api.Client, opts BackendOptions) (*BackendResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingBackendName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findBackend(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *current
+	opts.Settings.apply(&updated)
+	problems, err := validateBackend(updated, opts.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings, err := checkReferences(opts.Service, target.Version, client, func(r *References) {
+		r.setBackend(updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BackendResult{
+		TargetVersion: *target,
+		Backend:       updated,
+		Changes:       fieldChanges(current, &updated),
+		Warnings:      append(problems, warnings...),
+	}, nil
+}
+
+// UpdateBackend changes the settings of the backend in the target version
+// when none of the settings differ the service is left as it is
+func UpdateBackend(ctx context.Context, client api.Client, opts BackendOptions) (*BackendResult, error) {
+	plan, err := PlanUpdateBackend(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plan.Changes) == 0 {
+		return &BackendResult{
+			TargetVersion: TargetVersion{Service: plan.Service, Version: plan.Version},
+			Backend:       plan.Backend,
+		}, nil
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	s := opts.Settings
+	updated, err := client.UpdateBackend(&fastly.UpdateBackendInput{
+		Service:             opts.Service,
+		Version:             target.Version,
+		Name:                opts.Name,
+		Address:             stringValue(s.Address),
+		Port:                uintValue(s.Port),
+		OverrideHost:        stringValue(s.OverrideHost),
+		UseSSL:              compatibool(s.UseSSL),
+		SSLCheckCert:        compatibool(s.SSLCheckCert),
+		SSLCertHostname:     stringValue(s.SSLCertHostname),
+		SSLSNIHostname:      stringValue(s.SSLSNIHostname),
+		MinTLSVersion:       stringValue(s.MinTLSVersion),
+		MaxTLSVersion:       stringValue(s.MaxTLSVersion),
+		ConnectTimeout:      uintValue(s.ConnectTimeout),
+		FirstByteTimeout:    uintValue(s.FirstByteTimeout),
+		BetweenBytesTimeout: uintValue(s.BetweenBytesTimeout),
+		MaxConn:             uintValue(s.MaxConn),
+		Weight:              uintValue(s.Weight),
+		AutoLoadbalance:     compatibool(s.AutoLoadbalance),
+		HealthCheck:         stringValue(s.HealthCheck),
+		RequestCondition:    stringValue(s.RequestCondition),
+		Shield:              stringValue(s.Shield),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to update the backend '%s' in version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &BackendResult{
+		TargetVersion: *target,
+		Backend:       backend(updated),
+		Changes:       plan.Changes,
+		Warnings:      plan.Warnings,
+	}, nil
+}
+
+// PlanDeleteBackend checks no director references the backend that
+// DeleteBackend would delete, and which version it would be deleted from,
+// without making any changes to the service
+func PlanDeleteBackend(ctx context.Context, client api.Client, opts BackendOptions) (*BackendResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingBackendName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findBackend(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings, err := checkReferences(opts.Service, target.Version, client, func(r *References) {
+		r.removeBackend(opts.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BackendResult{
+		TargetVersion: *target,
+		Backend:       *current,
+		Warnings:      warnings,
+	}, nil
+}
+
+// DeleteBackend deletes the backend from the target version
+func DeleteBackend(ctx context.Context, client api.Client, opts BackendOptions) (*BackendResult, error) {
+	plan, err := PlanDeleteBackend(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteBackend(&fastly.DeleteBackendInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete the backend '%s' from version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &BackendResult{
+		TargetVersion: *target,
+		Backend:       plan.Backend,
+		Warnings:      plan.Warnings,
+	}, nil
+}
+
+// DiffBackends compares the backends of two remote service versions field by
+// field, only the backends that differ are returned (sorted by name)
+func DiffBackends(ctx context.Context, client api.Client, opts BackendDiffOptions) (*BackendDiffResult, error) {
+	fromVersion, err := resolveVersion(ctx, opts.Service, opts.From, client)
+	if err != nil {
+		return nil, err
+	}
+
+	toVersion, err := resolveVersion(ctx, opts.Service, opts.To, client)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := remoteBackends(opts.Service, fromVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := remoteBackends(opts.Service, toVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := map[string]*BackendDiff{}
+	for i := range from {
+		diffs[from[i].Name] = &BackendDiff{Name: from[i].Name, From: &from[i]}
+	}
+	for i := range to {
+		bd, ok := diffs[to[i].Name]
+		if !ok {
+			bd = &BackendDiff{Name: to[i].Name}
+			diffs[to[i].Name] = bd
+		}
+		bd.To = &to[i]
+	}
+
+	result := &BackendDiffResult{
+		Service: opts.Service,
+		From:    fromVersion,
+		To:      toVersion,
+	}
+	for _, bd := range diffs {
+		bd.Changes = fieldChanges(bd.From, bd.To)
+		if bd.From != nil && bd.To != nil && len(bd.Changes) == 0 {
+			continue
+		}
+		result.Backends = append(result.Backends, *bd)
+	}
+
+	sort.Slice(result.Backends, func(i, j int) bool {
+		return result.Backends[i].Name < result.Backends[j].Name
+	})
+
+	return result, nil
+}
+
+// apply sets the settings that aren't nil on the backend
+func (s BackendSettings) apply(b *Backend) {
+	applyString(&b.Address, s.Address)
+	applyUint(&b.Port, s.Port)
+	applyString(&b.OverrideHost, s.OverrideHost)
+	applyBool(&b.UseSSL, s.UseSSL)
+	applyBool(&b.SSLCheckCert, s.SSLCheckCert)
+	applyString(&b.SSLCertHostname, s.SSLCertHostname)
+	applyString(&b.SSLSNIHostname, s.SSLSNIHostname)
+	applyString(&b.MinTLSVersion, s.MinTLSVersion)
+	applyString(&b.MaxTLSVersion, s.MaxTLSVersion)
+	applyUint(&b.ConnectTimeout, s.ConnectTimeout)
+	applyUint(&b.FirstByteTimeout, s.FirstByteTimeout)
+	applyUint(&b.BetweenBytesTimeout, s.BetweenBytesTimeout)
+	applyUint(&b.MaxConn, s.MaxConn)
+	applyUint(&b.Weight, s.Weight)
+	applyBool(&b.AutoLoadbalance, s.AutoLoadbalance)
+	applyString(&b.HealthCheck, s.HealthCheck)
+	applyString(&b.RequestCondition, s.RequestCondition)
+	applyString(&b.Shield, s.Shield)
+}
+
+// validateBackend checks the settings the API would reject, so an invalid
+// backend is reported before a version is cloned, settings the API accepts
+// but that have no effect are returned as warnings
+func validateBackend(b Backend, s BackendSettings) ([]Problem, error) {
+	if (s.Port != nil && *s.Port == 0) || b.Port > 65535 {
+		return nil, fmt.Errorf("the port %d of the backend '%s' must be between 1 and 65535", b.Port, b.Name)
+	}
+
+	min, max := -1, len(TLSVersions)
+	for i, v := range TLSVersions {
+		if b.MinTLSVersion == v {
+			min = i
+		}
+		if b.MaxTLSVersion == v {
+			max = i
+		}
+	}
+	if b.MinTLSVersion != "" && min == -1 {
+		return nil, fmt.Errorf("unknown minimum TLS version '%s' (try: %v)", b.MinTLSVersion, TLSVersions)
+	}
+	if b.MaxTLSVersion != "" && max == len(TLSVersions) {
+		return nil, fmt.Errorf("unknown maximum TLS version '%s' (try: %v)", b.MaxTLSVersion, TLSVersions)
+	}
+	if min > max {
+		return nil, fmt.Errorf("the minimum TLS version (%s) of the backend '%s' is above its maximum TLS version (%s)", b.MinTLSVersion, b.Name, b.MaxTLSVersion)
+	}
+
+	var problems []Problem
+	if (b.SSLCheckCert || b.SSLCertHostname != "" || b.SSLSNIHostname != "") && !b.UseSSL {
+		problems = append(problems, Problem{
+			Object:  fmt.Sprintf("backend '%s'", b.Name),
+			Message: "has TLS settings but doesn't use TLS (see: -use-ssl)",
+			Warning: true,
+		})
+	}
+
+	return problems, nil
+}
+
+// fieldChanges returns the settings that differ between the backends, either
+// of which can be nil (i.e. the backend doesn't exist)
+func fieldChanges(from, to *Backend) []FieldChange {
+	var fromFields, toFields []BackendField
+	if from != nil {
+		fromFields = from.Fields()
+	}
+	if to != nil {
+		toFields = to.Fields()
+	}
+
+	var changes []FieldChange
+	for i, field := range (Backend{}).Fields() {
+		fc := FieldChange{Field: field.Name}
+		if fromFields != nil {
+			fc.From = fromFields[i].Value
+		}
+		if toFields != nil {
+			fc.To = toFields[i].Value
+		}
+		if fc.From != fc.To {
+			changes = append(changes, fc)
+		}
+	}
+
+	return changes
+}
+
+func findBackend(service string, version int, name string, client api.Client) (*Backend, error) {
+	backends, err := remoteBackends(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range backends {
+		if b.Name == name {
+			return &b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the backend '%s' doesn't exist in version %d", name, version)
+}
+
+func remoteBackends(service string, version int, client api.Client) ([]Backend, error) {
+	list, err := client.ListBackends(&fastly.ListBackendsInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve list of backends for version %d: %s", version, err)
+	}
+
+	backends := make([]Backend, 0, len(list))
+	for _, b := range list {
+		backends = append(backends, backend(b))
+	}
+
+	sort.Slice(backends, func(i, j int) bool {
+		return backends[i].Name < backends[j].Name
+	})
+
+	return backends, nil
+}
+
+func backend(b *fastly.Backend) Backend {
+	return Backend{
+		Name:                b.Name,
+		Comment:             b.Comment,
+		Address:             b.Address,
+		Port:                b.Port,
+		OverrideHost:        b.OverrideHost,
+		UseSSL:              b.UseSSL,
+		SSLCheckCert:        b.SSLCheckCert,
+		SSLCertHostname:     b.SSLCertHostname,
+		SSLSNIHostname:      b.SSLSNIHostname,
+		MinTLSVersion:       b.MinTLSVersion,
+		MaxTLSVersion:       b.MaxTLSVersion,
+		ConnectTimeout:      b.ConnectTimeout,
+		FirstByteTimeout:    b.FirstByteTimeout,
+		BetweenBytesTimeout: b.BetweenBytesTimeout,
+		MaxConn:             b.MaxConn,
+		Weight:              b.Weight,
+		AutoLoadbalance:     b.AutoLoadbalance,
+		HealthCheck:         b.HealthCheck,
+		RequestCondition:    b.RequestCondition,
+		Shield:              b.Shield,
+	}
+}
+
+// applyString, applyUint and applyBool set a field to a setting that isn't nil
+
+func applyString(field *string, value *string) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+func applyUint(field *uint, value *uint) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+func applyBool(field *bool, value *bool) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+func compatibool(b *bool) *fastly.Compatibool {
+	if b == nil {
+		return nil
+	}
+	c := fastly.Compatibool(*b)
+	return &c
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func uintValue(u *uint) uint {
+	if u == nil {
+		return 0
+	}
+	return *u
+}
+
+func formatUint(u uint) string {
+	return strconv.FormatUint(uint64(u), 10)
+}
+
+func quoteEmpty(s string) string {
+	if s == "" {
+		return `""`
+	}
+	return s
+}
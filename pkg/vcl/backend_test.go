@@ -0,0 +1,239 @@
+package vcl
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+func TestPlanCreateBackend(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(u uint) *uint { return &u }
+	yes := func() *bool { b := true; return &b }
+
+	tests := []struct {
+		name     string
+		settings BackendSettings
+		changes  []FieldChange
+		warnings []Problem
+		err      string
+	}{
+		{
+			name:     "address and port",
+			settings: BackendSettings{Address: str("origin.example.com"), Port: num(443)},
+			changes: []FieldChange{
+				{Field: "address", To: "origin.example.com"},
+				{Field: "port", To: "443"},
+			},
+		},
+		{
+			name:     "port zero",
+			settings: BackendSettings{Address: str("origin.example.com"), Port: num(0)},
+			err:      "must be between 1 and 65535",
+		},
+		{
+			name:     "port above 65535",
+			settings: BackendSettings{Address: str("origin.example.com"), Port: num(65536)},
+			err:      "must be between 1 and 65535",
+		},
+		{
+			name:     "unknown TLS version",
+			settings: BackendSettings{Address: str("origin.example.com"), MinTLSVersion: str("1.4")},
+			err:      "unknown minimum TLS version '1.4'",
+		},
+		{
+			name:     "minimum TLS version above the maximum",
+			settings: BackendSettings{Address: str("origin.example.com"), MinTLSVersion: str("1.3"), MaxTLSVersion: str("1.2")},
+			err:      "is above its maximum TLS version",
+		},
+		{
+			name:     "TLS settings without TLS",
+			settings: BackendSettings{Address: str("origin.example.com"), SSLCertHostname: str("origin.example.com")},
+			changes: []FieldChange{
+				{Field: "address", To: "origin.example.com"},
+				{Field: "ssl_cert_hostname", To: "origin.example.com"},
+			},
+			warnings: []Problem{
+				{Object: "backend 'origin'", Message: "has TLS settings but doesn't use TLS (see: -use-ssl)", Warning: true},
+			},
+		},
+		{
+			name:     "TLS settings with TLS",
+			settings: BackendSettings{Address: str("origin.example.com"), UseSSL: yes(), SSLCertHostname: str("origin.example.com")},
+			changes: []FieldChange{
+				{Field: "address", To: "origin.example.com"},
+				{Field: "use_ssl", To: "true"},
+				{Field: "ssl_cert_hostname", To: "origin.example.com"},
+			},
+		},
+		{
+			name:     "missing healthcheck",
+			settings: BackendSettings{Address: str("origin.example.com"), HealthCheck: str("missing")},
+			err:      "healthcheck 'missing'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newService(t, nil)
+
+			result, err := PlanCreateBackend(context.Background(), f, BackendOptions{
+				Target:   Target{Service: "svc"},
+				Name:     "origin",
+				Settings: tt.settings,
+			})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// a new backend changes every field from nothing, including the
+			// zero values (e.g. use_ssl: "" -> false)
+			for _, want := range tt.changes {
+				if !containsChange(result.Changes, want) {
+					t.Errorf("got changes %+v, want them to include %+v", result.Changes, want)
+				}
+			}
+			if !reflect.DeepEqual(result.Warnings, tt.warnings) {
+				t.Errorf("got warnings %+v, want %+v", result.Warnings, tt.warnings)
+			}
+
+			// planning makes no changes to the service
+			if calls := f.Calls("CloneVersion") + f.Calls("CreateBackend"); calls != 0 {
+				t.Errorf("made %d changes, want none", calls)
+			}
+		})
+	}
+}
+
+func TestUpdateBackend(t *testing.T) {
+	num := func(u uint) *uint { return &u }
+
+	tests := []struct {
+		name     string
+		settings BackendSettings
+		changes  []FieldChange
+		port     uint
+		version  int
+	}{
+		{
+			name:     "changed port",
+			settings: BackendSettings{Port: num(443)},
+			changes:  []FieldChange{{Field: "port", From: "80", To: "443"}},
+			port:     443,
+			version:  2,
+		},
+		{
+			name:     "unchanged port",
+			settings: BackendSettings{Port: num(80)},
+			port:     80,
+			version:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newService(t, nil)
+			if err := f.SetBackend("svc", 1, fastly.Backend{Name: "origin", Address: "origin.example.com", Port: 80}); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := UpdateBackend(context.Background(), f, BackendOptions{
+				Target:   Target{Service: "svc"},
+				Name:     "origin",
+				Settings: tt.settings,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(result.Changes, tt.changes) {
+				t.Errorf("got changes %+v, want %+v", result.Changes, tt.changes)
+			}
+			if result.Version != tt.version {
+				t.Errorf("got version %d, want %d", result.Version, tt.version)
+			}
+
+			// an update with nothing to change doesn't clone a version
+			if tt.changes == nil {
+				if calls := f.Calls("CloneVersion"); calls != 0 {
+					t.Errorf("cloned %d versions, want none", calls)
+				}
+			}
+
+			found, err := findBackend("svc", tt.version, "origin", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found.Port != tt.port {
+				t.Errorf("got port %d, want %d", found.Port, tt.port)
+			}
+		})
+	}
+}
+
+func TestDiffBackends(t *testing.T) {
+	f := newService(t, nil)
+	if _, err := f.CloneVersion(&fastly.CloneVersionInput{Service: "svc", Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	set := func(version int, b fastly.Backend) {
+		if err := f.SetBackend("svc", version, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set(1, fastly.Backend{Name: "same", Address: "same.example.com", Port: 80})
+	set(2, fastly.Backend{Name: "same", Address: "same.example.com", Port: 80})
+	set(1, fastly.Backend{Name: "changed", Address: "old.example.com", Port: 80, UseSSL: false})
+	set(2, fastly.Backend{Name: "changed", Address: "new.example.com", Port: 443, UseSSL: true})
+	set(1, fastly.Backend{Name: "removed", Address: "removed.example.com"})
+	set(2, fastly.Backend{Name: "added", Address: "added.example.com"})
+
+	result, err := DiffBackends(context.Background(), f, BackendDiffOptions{Service: "svc", From: 1, To: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, bd := range result.Backends {
+		names = append(names, bd.Name)
+	}
+	if want := []string{"added", "changed", "removed"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got backends %v, want %v", names, want)
+	}
+
+	added, changed, removed := result.Backends[0], result.Backends[1], result.Backends[2]
+	if added.From != nil || added.To == nil {
+		t.Errorf("got added backend %+v, want only a To backend", added)
+	}
+	if removed.From == nil || removed.To != nil {
+		t.Errorf("got removed backend %+v, want only a From backend", removed)
+	}
+
+	want := []FieldChange{
+		{Field: "address", From: "old.example.com", To: "new.example.com"},
+		{Field: "port", From: "80", To: "443"},
+		{Field: "use_ssl", From: "false", To: "true"},
+	}
+	if !reflect.DeepEqual(changed.Changes, want) {
+		t.Errorf("got changes %+v, want %+v", changed.Changes, want)
+	}
+}
+
+func containsChange(changes []FieldChange, want FieldChange) bool {
+	for _, fc := range changes {
+		if fc == want {
+			return true
+		}
+	}
+	return false
+}
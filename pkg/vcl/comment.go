@@ -0,0 +1,96 @@
+package vcl
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultCommentTemplate is the comment given to the versions created by
+// upload, sync and deploy (the git details are left out when the directory
+// isn't within a git repository)
+const DefaultCommentTemplate = "Uploaded by {{.User}} at {{.Time}}{{if .SHA}} from {{.Branch}}@{{.ShortSHA}}{{if .Dirty}} (dirty){{end}}{{end}}"
+
+// Source describes where the local VCL files came from, it's the data
+// available to a comment template
+type Source struct {
+	// SHA is the commit checked out in the directory (empty outside of git)
+	SHA      string
+	ShortSHA string
+	Branch   string
+
+	// Dirty is true when there are uncommitted changes
+	Dirty bool
+
+	Dir  string
+	User string
+	Time string
+}
+
+// ReadSource collects the git details of the directory along with the
+// current user and time, the git details are empty when they can't be read
+func ReadSource(dir string) Source {
+	src := Source{
+		Dir:  dir,
+		User: currentUser(),
+		Time: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	sha, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		logger.WithField("err", err).Debug("unable to read the git commit")
+		return src
+	}
+
+	src.SHA = sha
+	src.ShortSHA = sha
+	if len(sha) > 7 {
+		src.ShortSHA = sha[:7]
+	}
+
+	src.Branch, _ = git(dir, "rev-parse", "--abbrev-ref", "HEAD")
+
+	status, _ := git(dir, "status", "--porcelain")
+	src.Dirty = status != ""
+
+	return src
+}
+
+// FormatComment executes the comment template (see: Source) with the source
+func FormatComment(tmpl string, src Source) (string, error) {
+	t, err := template.New("comment").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("Unable to parse the comment template:\n\t%s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, src); err != nil {
+		return "", fmt.Errorf("Unable to render the comment template:\n\t%s", err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
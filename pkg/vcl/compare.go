@@ -0,0 +1,79 @@
+package vcl
+
+import (
+	"context"
+	"sort"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/diff"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// VersionDiff is the comparison of a single VCL file between two remote
+// versions, the file may only exist in one of them
+type VersionDiff struct {
+	Name   string
+	Result diff.Result
+}
+
+// CompareVersions compares the VCL files of two remote service versions
+// only the files that differ are returned (sorted by name)
+func CompareVersions(ctx context.Context, client api.Client, service string, from, to int) ([]VersionDiff, error) {
+	fromFiles, err := remoteContent(ctx, client, service, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toFiles, err := remoteContent(ctx, client, service, to)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for name := range fromFiles {
+		names[name] = true
+	}
+	for name := range toFiles {
+		names[name] = true
+	}
+
+	var diffs []VersionDiff
+	for name := range names {
+		if fromFiles[name] == toFiles[name] {
+			continue
+		}
+
+		diffs = append(diffs, VersionDiff{
+			Name:   name,
+			Result: diff.Strings(fromFiles[name], toFiles[name], planDiffOptions),
+		})
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+
+	return diffs, nil
+}
+
+// remoteContent returns the content of each VCL file in the version keyed by
+// the file name
+func remoteContent(ctx context.Context, client api.Client, service string, version int) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vclFiles, err := client.ListVCLs(&fastly.ListVCLsInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content := map[string]string{}
+	for _, vcl := range vclFiles {
+		content[vcl.Name] = vcl.Content
+	}
+
+	return content, nil
+}
@@ -0,0 +1,381 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// ErrMissingConditionName is returned when an operation requires a condition
+// name
+var ErrMissingConditionName = errors.New("you must provide a condition name")
+
+// ErrMissingConditionStatement is returned when a condition is created
+// without the VCL statement it evaluates
+var ErrMissingConditionStatement = errors.New("you must provide the statement of the condition (e.g. req.url ~ \"^/api/\")")
+
+// ConditionRequest is the type of the conditions that select a backend
+const ConditionRequest = "REQUEST"
+
+// ConditionTypes are the types of condition, which is where in the request
+// the condition is evaluated
+var ConditionTypes = []string{ConditionRequest, "CACHE", "RESPONSE", "PREFETCH"}
+
+// Condition is a VCL statement that selects when the objects referencing it
+// apply (e.g. the backend a request is sent to)
+type Condition struct {
+	Name    string
+	Comment string
+
+	// Type is one of ConditionTypes
+	Type      string
+	Statement string
+	Priority  int
+}
+
+// ConditionSettings are the condition settings to create or update with,
+// only the settings that aren't nil are changed
+type ConditionSettings struct {
+	Type      *string
+	Statement *string
+	Priority  *int
+}
+
+// ConditionsResult contains the conditions of the remote service version
+type ConditionsResult struct {
+	Service    string
+	Version    int
+	Conditions []Condition
+}
+
+// ConditionOptions defines the condition to create, update or delete and the
+// version to make the change to
+type ConditionOptions struct {
+	Target
+
+	Name     string
+	Settings ConditionSettings
+}
+
+// ConditionResult describes the condition that was (or would be) created,
+// updated or deleted, along with the version it was changed in
+//
+// Unchanged reports an update with nothing to do (no version is cloned), and
+// Warnings are the problems with the references the change introduces
+type ConditionResult struct {
+	TargetVersion
+	Condition Condition
+	Unchanged bool
+	Warnings  []Problem
+}
+
+// ListConditions returns every condition in the remote service version
+func ListConditions(ctx context.Context, client api.Client, opts ListOptions) (*ConditionsResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions, err := remoteConditions(opts.Service, selectedVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConditionsResult{
+		Service:    opts.Service,
+		Version:    selectedVersion,
+		Conditions: conditions,
+	}, nil
+}
+
+// PlanCreateCondition validates the condition that CreateCondition would
+// create, and which version it would be created in, without making any
+// changes to the service
+func PlanCreateCondition(ctx context.Context, client api.Client, opts ConditionOptions) (*ConditionResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingConditionName
+	}
+	if opts.Settings.Statement == nil || *opts.Settings.Statement == "" {
+		return nil, ErrMissingConditionStatement
+	}
+
+	planned := Condition{Name: opts.Name, Type: ConditionRequest}
+	opts.Settings.apply(&planned)
+	if err := validateCondition(planned); err != nil {
+		return nil, err
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := findCondition(opts.Service, target.Version, opts.Name, client); err == nil {
+		return nil, fmt.Errorf("the condition '%s' already exists in version %d", opts.Name, target.Version)
+	}
+
+	warnings, err := checkReferences(opts.Service, target.Version, client, func(r *References) {
+		r.setCondition(planned)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConditionResult{
+		TargetVersion: *target,
+		Condition:     planned,
+		Warnings:      warnings,
+	}, nil
+}
+
+// CreateCondition creates the condition in the target version
+func CreateCondition(ctx context.Context, client api.Client, opts ConditionOptions) (*ConditionResult, error) {
+	plan, err := PlanCreateCondition(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	c := plan.Condition
+	created, err := client.CreateCondition(&fastly.CreateConditionInput{
+		Service:   opts.Service,
+		Version:   target.Version,
+		Name:      opts.Name,
+		Type:      c.Type,
+		Statement: c.Statement,
+		Priority:  c.Priority,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the condition '%s' in version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &ConditionResult{
+		TargetVersion: *target,
+		Condition:     condition(created),
+		Warnings:      plan.Warnings,
+	}, nil
+}
+
+// PlanUpdateCondition validates the settings UpdateCondition would change
+// (e.g. a backend still referencing a condition whose type changes), and
+// which version it would change them in, without making any changes to the
+// service
+func PlanUpdateCondition(ctx context.Context, client api.Client, opts ConditionOptions) (*ConditionResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingConditionName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findCondition(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *current
+	opts.Settings.apply(&updated)
+	if err := validateCondition(updated); err != nil {
+		return nil, err
+	}
+
+	warnings, err := checkReferences(opts.Service, target.Version, client, func(r *References) {
+		r.setCondition(updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConditionResult{
+		TargetVersion: *target,
+		Condition:     updated,
+		Unchanged:     reflect.DeepEqual(*current, updated),
+		Warnings:      warnings,
+	}, nil
+}
+
+// UpdateCondition changes the settings of the condition in the target
+// version, when none of the settings differ the service is left as it is
+func UpdateCondition(ctx context.Context, client api.Client, opts ConditionOptions) (*ConditionResult, error) {
+	plan, err := PlanUpdateCondition(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Unchanged {
+		plan.TargetVersion = TargetVersion{Service: plan.Service, Version: plan.Version}
+		return plan, nil
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &fastly.UpdateConditionInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    opts.Name,
+	}
+	if opts.Settings.Type != nil {
+		input.Type = plan.Condition.Type
+	}
+	if opts.Settings.Statement != nil {
+		input.Statement = plan.Condition.Statement
+	}
+	if opts.Settings.Priority != nil {
+		input.Priority = plan.Condition.Priority
+	}
+
+	updated, err := client.UpdateCondition(input)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update the condition '%s' in version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &ConditionResult{
+		TargetVersion: *target,
+		Condition:     condition(updated),
+		Warnings:      plan.Warnings,
+	}, nil
+}
+
+// PlanDeleteCondition checks no backend references the condition that
+// DeleteCondition would delete, and which version it would be deleted from,
+// without making any changes to the service
+func PlanDeleteCondition(ctx context.Context, client api.Client, opts ConditionOptions) (*ConditionResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingConditionName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findCondition(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings, err := checkReferences(opts.Service, target.Version, client, func(r *References) {
+		r.removeCondition(opts.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConditionResult{
+		TargetVersion: *target,
+		Condition:     *current,
+		Warnings:      warnings,
+	}, nil
+}
+
+// DeleteCondition deletes the condition from the target version
+func DeleteCondition(ctx context.Context, client api.Client, opts ConditionOptions) (*ConditionResult, error) {
+	plan, err := PlanDeleteCondition(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteCondition(&fastly.DeleteConditionInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete the condition '%s' from version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &ConditionResult{
+		TargetVersion: *target,
+		Condition:     plan.Condition,
+		Warnings:      plan.Warnings,
+	}, nil
+}
+
+// apply sets the settings that aren't nil on the condition, the type is
+// upper cased (as the API expects)
+func (s ConditionSettings) apply(c *Condition) {
+	if s.Type != nil {
+		c.Type = strings.ToUpper(*s.Type)
+	}
+	applyString(&c.Statement, s.Statement)
+	if s.Priority != nil {
+		c.Priority = *s.Priority
+	}
+}
+
+// validateCondition checks the settings the API would reject, so an invalid
+// condition is reported before a version is cloned
+func validateCondition(c Condition) error {
+	for _, t := range ConditionTypes {
+		if c.Type == t {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown condition type '%s' (try: %s)", c.Type, strings.Join(ConditionTypes, ", "))
+}
+
+func findCondition(service string, version int, name string, client api.Client) (*Condition, error) {
+	conditions, err := remoteConditions(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range conditions {
+		if c.Name == name {
+			return &c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the condition '%s' doesn't exist in version %d", name, version)
+}
+
+func remoteConditions(service string, version int, client api.Client) ([]Condition, error) {
+	list, err := client.ListConditions(&fastly.ListConditionsInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve list of conditions for version %d: %s", version, err)
+	}
+
+	conditions := make([]Condition, 0, len(list))
+	for _, c := range list {
+		conditions = append(conditions, condition(c))
+	}
+
+	sort.Slice(conditions, func(i, j int) bool {
+		return conditions[i].Name < conditions[j].Name
+	})
+
+	return conditions, nil
+}
+
+func condition(c *fastly.Condition) Condition {
+	return Condition{
+		Name:      c.Name,
+		Comment:   c.Comment,
+		Type:      c.Type,
+		Statement: c.Statement,
+		Priority:  c.Priority,
+	}
+}
@@ -0,0 +1,52 @@
+package vcl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// DeleteOptions defines the settings for deleting a remote VCL file
+type DeleteOptions struct {
+	Service string
+	Name    string
+
+	// Version to delete the file from (zero means the latest version)
+	Version int
+}
+
+// DeleteResult describes the VCL file that was deleted
+type DeleteResult struct {
+	Service string
+	Version int
+	Name    string
+}
+
+// Delete specified VCL file in the remote service version
+func Delete(ctx context.Context, client api.Client, opts DeleteOptions) (*DeleteResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingName
+	}
+
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteVCL(&fastly.DeleteVCLInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete the specified VCL file from version %d: %s", selectedVersion, err)
+	}
+
+	return &DeleteResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Name:    opts.Name,
+	}, nil
+}
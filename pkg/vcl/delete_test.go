@@ -0,0 +1,94 @@
+package vcl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api/apitest"
+)
+
+func TestDelete(t *testing.T) {
+	remote := map[string]string{
+		"main":  "sub vcl_recv {\n}\n",
+		"other": "sub vcl_fetch {\n}\n",
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(*apitest.Fake)
+		opts    DeleteOptions
+		version int
+		files   map[string]string
+		err     bool
+	}{
+		{
+			name:    "deletes from the latest version",
+			opts:    DeleteOptions{Name: "other"},
+			version: 1,
+			files:   map[string]string{"main": remote["main"]},
+		},
+		{
+			name: "deletes from the specified version",
+			setup: func(f *apitest.Fake) {
+				f.CloneVersion(&fastly.CloneVersionInput{Service: "svc", Version: 1})
+			},
+			opts:    DeleteOptions{Name: "other", Version: 1},
+			version: 1,
+			files:   map[string]string{"main": remote["main"]},
+		},
+		{
+			name: "unknown file",
+			opts: DeleteOptions{Name: "missing"},
+			err:  true,
+		},
+		{
+			name: "locked version",
+			setup: func(f *apitest.Fake) {
+				f.LockVersion(&fastly.LockVersionInput{Service: "svc", Version: 1})
+			},
+			opts: DeleteOptions{Name: "other"},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newService(t, remote)
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			opts := tt.opts
+			opts.Service = "svc"
+
+			result, err := Delete(context.Background(), f, opts)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				equalFiles(t, f, 1, remote)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Version != tt.version || result.Name != opts.Name {
+				t.Errorf("deleted %s from version %d, want %s from %d", result.Name, result.Version, opts.Name, tt.version)
+			}
+			equalFiles(t, f, tt.version, tt.files)
+		})
+	}
+}
+
+func TestDeleteMissingName(t *testing.T) {
+	f := newService(t, nil)
+
+	if _, err := Delete(context.Background(), f, DeleteOptions{Service: "svc"}); err != ErrMissingName {
+		t.Errorf("got error %v, want %v", err, ErrMissingName)
+	}
+	if calls := f.Calls("ListVersions"); calls != 0 {
+		t.Errorf("made %d calls to the API", calls)
+	}
+}
@@ -0,0 +1,99 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// ErrUploadFailed is returned when a deploy is aborted because one or more
+// files failed to upload (or the main VCL couldn't be designated)
+var ErrUploadFailed = errors.New("one or more files failed to upload, the version was not activated")
+
+// ErrInvalidVersion is returned when a deploy (or rollback) is aborted because
+// the version failed validation
+var ErrInvalidVersion = errors.New("the version failed validation, it was not activated")
+
+// DeployOptions defines the settings for uploading, validating and then
+// activating a version, the version is selected in the same way as
+// UploadOptions
+type DeployOptions UploadOptions
+
+// DeployResult contains the outcome of each step of a deploy
+// steps that weren't reached are left as nil
+type DeployResult struct {
+	Upload   *UploadResult
+	Validate *ValidateResult
+	Activate *ActivateResult
+}
+
+// Version returns the version that was deployed to (zero if the upload step
+// didn't get as far as acquiring a version)
+func (r DeployResult) Version() int {
+	if r.Upload == nil {
+		return 0
+	}
+	return r.Upload.Version
+}
+
+// Deploy uploads the local VCL files, validates the resulting version and,
+// only if every file uploaded, the version is valid and confirm accepts the
+// activation plan (a nil confirm accepts every plan), activates it
+//
+// When the deploy is aborted the result of the completed steps is returned
+// along with the error
+func Deploy(ctx context.Context, client api.Client, opts DeployOptions, confirm ConfirmActivation) (*DeployResult, error) {
+	result := &DeployResult{}
+
+	upload, err := Upload(ctx, client, UploadOptions(opts))
+	if err != nil {
+		return result, err
+	}
+	result.Upload = upload
+
+	if upload.Err() != nil {
+		return result, ErrUploadFailed
+	}
+
+	plan, err := PlanActivate(ctx, client, VersionOptions{
+		Service: opts.Service,
+		Version: upload.Version,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Validate = &ValidateResult{
+		Service: plan.Service,
+		Version: plan.Version,
+		Valid:   plan.Valid,
+		Message: plan.Message,
+	}
+
+	activate, err := activatePlan(ctx, client, plan, confirm)
+	if err != nil {
+		return result, err
+	}
+	result.Activate = activate
+
+	return result, nil
+}
+
+// activatePlan activates the planned version once it's valid and confirm
+// accepts the plan
+func activatePlan(ctx context.Context, client api.Client, plan *ActivatePlan, confirm ConfirmActivation) (*ActivateResult, error) {
+	if !plan.Valid {
+		return nil, ErrInvalidVersion
+	}
+
+	if confirm != nil {
+		if err := confirm(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	return Activate(ctx, client, VersionOptions{
+		Service: plan.Service,
+		Version: plan.Version,
+	})
+}
@@ -0,0 +1,126 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+func TestDeploy(t *testing.T) {
+	tests := []struct {
+		name    string
+		remote  map[string]string
+		main    string
+		fail    string
+		confirm error
+
+		err       string
+		uploaded  bool
+		validated bool
+		confirmed bool
+		activated bool
+	}{
+		{
+			name:      "activates the valid version",
+			remote:    map[string]string{"main": "old\n"},
+			main:      "main",
+			uploaded:  true,
+			validated: true,
+			confirmed: true,
+			activated: true,
+		},
+		{
+			name:   "aborts when the version can't be cloned",
+			remote: map[string]string{"main": "old\n"},
+			main:   "main",
+			fail:   "CloneVersion",
+			err:    "unavailable",
+		},
+		{
+			name:     "aborts when a file fails to upload",
+			remote:   map[string]string{"main": "old\n"},
+			main:     "main",
+			fail:     "CreateVCL",
+			err:      ErrUploadFailed.Error(),
+			uploaded: true,
+		},
+		{
+			name:      "aborts when the version fails validation",
+			err:       ErrInvalidVersion.Error(),
+			uploaded:  true,
+			validated: true,
+		},
+		{
+			name:      "aborts when the activation isn't confirmed",
+			remote:    map[string]string{"main": "old\n"},
+			main:      "main",
+			confirm:   errors.New("declined"),
+			err:       "declined",
+			uploaded:  true,
+			validated: true,
+			confirmed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeDir(t, localFiles)
+			defer os.RemoveAll(dir)
+
+			// without a main VCL the version fails validation
+			f := newService(t, tt.remote)
+			if tt.fail != "" {
+				f.FailOn(tt.fail, errors.New("unavailable"))
+			}
+
+			confirmed := false
+			confirm := func(plan *ActivatePlan) error {
+				confirmed = true
+				if plan.Version != 2 || !plan.Valid {
+					t.Errorf("asked to confirm version %d (valid: %t), want the valid version 2", plan.Version, plan.Valid)
+				}
+				return tt.confirm
+			}
+
+			result, err := Deploy(context.Background(), f, DeployOptions{
+				Service:   "svc",
+				Directory: dir,
+				Main:      tt.main,
+			}, confirm)
+			if tt.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+
+			if (result.Upload != nil) != tt.uploaded {
+				t.Errorf("got upload result %+v, want uploaded: %t", result.Upload, tt.uploaded)
+			}
+			if (result.Validate != nil) != tt.validated {
+				t.Errorf("got validate result %+v, want validated: %t", result.Validate, tt.validated)
+			}
+			if (result.Activate != nil) != tt.activated {
+				t.Errorf("got activate result %+v, want activated: %t", result.Activate, tt.activated)
+			}
+
+			// an invalid version is never offered for confirmation, and the
+			// version is only activated once confirmed
+			if confirmed != tt.confirmed {
+				t.Errorf("got confirmed %t, want %t", confirmed, tt.confirmed)
+			}
+
+			active, err := api.GetActiveVersion("svc", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (active == 2) != tt.activated {
+				t.Errorf("version %d is active, want version 2 activated: %t", active, tt.activated)
+			}
+		})
+	}
+}
@@ -0,0 +1,657 @@
+package vcl
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// ErrMissingDictionaryName is returned when an operation requires a
+// dictionary name
+var ErrMissingDictionaryName = errors.New("you must provide a dictionary name")
+
+// ErrMissingItemKey is returned when an operation requires an item key
+var ErrMissingItemKey = errors.New("you must provide a dictionary item key")
+
+// ErrUnknownItemsFormat is returned when the format of an items file can't be
+// determined from its extension
+var ErrUnknownItemsFormat = errors.New("unknown items file format (try: json or csv)")
+
+// Dictionary is an edge dictionary of a service version
+// the items of a dictionary aren't versioned
+type Dictionary struct {
+	Name      string
+	ID        string
+	WriteOnly bool
+}
+
+// DictionaryItem is a single item of a dictionary
+type DictionaryItem struct {
+	Key   string
+	Value string
+}
+
+// DictionariesResult contains the dictionaries of the remote service version
+type DictionariesResult struct {
+	Service      string
+	Version      int
+	Dictionaries []Dictionary
+}
+
+// DictionaryOptions defines the dictionary to create or delete and the
+// version to make the change to
+type DictionaryOptions struct {
+	Target
+
+	Name string
+
+	// WriteOnly prevents the items from being read (create only)
+	WriteOnly bool
+}
+
+// DictionaryResult describes the dictionary that was (or would be) created
+// or deleted, along with the version it was changed in
+type DictionaryResult struct {
+	TargetVersion
+	Dictionary Dictionary
+}
+
+// ItemOptions identifies a dictionary item, the dictionary is looked up by
+// name in Version (zero means the active version)
+type ItemOptions struct {
+	Service    string
+	Dictionary string
+	Version    int
+
+	Key   string
+	Value string
+}
+
+// ItemsResult contains the items of a dictionary sorted by key
+type ItemsResult struct {
+	Service    string
+	Dictionary Dictionary
+	Items      []DictionaryItem
+}
+
+// ItemResult contains a single dictionary item
+type ItemResult struct {
+	Service    string
+	Dictionary Dictionary
+	DictionaryItem
+}
+
+// ImportOptions defines the file of items a dictionary should contain
+// Format is json or csv (empty means the format matches the file extension)
+type ImportOptions struct {
+	Service    string
+	Dictionary string
+	Version    int
+
+	File   string
+	Format string
+}
+
+// ImportPlan describes the changes needed for the dictionary to contain
+// exactly the items of the file, computing it makes no mutating API calls
+type ImportPlan struct {
+	Service    string
+	Dictionary Dictionary
+
+	Add       []ItemChange
+	Change    []ItemChange
+	Remove    []ItemChange
+	Unchanged int
+}
+
+// Empty reports whether the plan has no changes to apply
+func (p ImportPlan) Empty() bool {
+	return len(p.Add) == 0 && len(p.Change) == 0 && len(p.Remove) == 0
+}
+
+// ItemChange is a single item within an ImportPlan
+// Old is empty for an added item and New is empty for a removed item
+type ItemChange struct {
+	Key string
+	Old string
+	New string
+}
+
+// ImportResult contains the outcome of applying an ImportPlan
+type ImportResult struct {
+	Service    string
+	Dictionary Dictionary
+
+	// Batches is the number of batches applied out of Total
+	Batches int
+	Total   int
+
+	Added   int
+	Changed int
+	Removed int
+}
+
+// ListDictionaries returns every dictionary in the remote service version
+func ListDictionaries(ctx context.Context, client api.Client, opts ListOptions) (*DictionariesResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	dicts, err := remoteDictionaries(opts.Service, selectedVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DictionariesResult{
+		Service:      opts.Service,
+		Version:      selectedVersion,
+		Dictionaries: dicts,
+	}, nil
+}
+
+// PlanCreateDictionary describes which version CreateDictionary would create
+// the dictionary in without making any changes to the service
+func PlanCreateDictionary(ctx context.Context, client api.Client, opts DictionaryOptions) (*DictionaryResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingDictionaryName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := findDictionary(opts.Service, target.Version, opts.Name, client); err == nil {
+		return nil, fmt.Errorf("the dictionary '%s' already exists in version %d", opts.Name, target.Version)
+	}
+
+	return &DictionaryResult{
+		TargetVersion: *target,
+		Dictionary:    Dictionary{Name: opts.Name, WriteOnly: opts.WriteOnly},
+	}, nil
+}
+
+// CreateDictionary creates an empty dictionary in the target version
+func CreateDictionary(ctx context.Context, client api.Client, opts DictionaryOptions) (*DictionaryResult, error) {
+	plan, err := PlanCreateDictionary(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := client.CreateDictionary(&fastly.CreateDictionaryInput{
+		Service:   opts.Service,
+		Version:   target.Version,
+		Name:      opts.Name,
+		WriteOnly: fastly.Compatibool(opts.WriteOnly),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the dictionary '%s' in version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &DictionaryResult{
+		TargetVersion: *target,
+		Dictionary:    dictionary(d),
+	}, nil
+}
+
+// PlanDeleteDictionary describes which version DeleteDictionary would delete
+// the dictionary from without making any changes to the service
+func PlanDeleteDictionary(ctx context.Context, client api.Client, opts DictionaryOptions) (*DictionaryResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingDictionaryName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	dict, err := findDictionary(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DictionaryResult{
+		TargetVersion: *target,
+		Dictionary:    *dict,
+	}, nil
+}
+
+// DeleteDictionary deletes the dictionary (and so its items) from the target
+// version
+func DeleteDictionary(ctx context.Context, client api.Client, opts DictionaryOptions) (*DictionaryResult, error) {
+	plan, err := PlanDeleteDictionary(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteDictionary(&fastly.DeleteDictionaryInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete the dictionary '%s' from version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &DictionaryResult{
+		TargetVersion: *target,
+		Dictionary:    plan.Dictionary,
+	}, nil
+}
+
+// LookupDictionary finds the dictionary of the items, which is looked up in
+// the specified version, or the active version (falling back to the latest
+// version when none is active)
+func LookupDictionary(ctx context.Context, client api.Client, opts ItemOptions) (*Dictionary, error) {
+	if opts.Dictionary == "" {
+		return nil, ErrMissingDictionaryName
+	}
+
+	selectedVersion, err := liveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return findDictionary(opts.Service, selectedVersion, opts.Dictionary, client)
+}
+
+// DictionaryItems returns every item of the dictionary sorted by key
+func DictionaryItems(ctx context.Context, client api.Client, opts ItemOptions) (*ItemsResult, error) {
+	dict, err := LookupDictionary(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := remoteItems(opts.Service, dict, client)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ItemsResult{
+		Service:    opts.Service,
+		Dictionary: *dict,
+	}
+
+	for _, key := range sortedKeys(items) {
+		result.Items = append(result.Items, DictionaryItem{Key: key, Value: items[key]})
+	}
+
+	return result, nil
+}
+
+// GetDictionaryItem returns a single item of the dictionary
+func GetDictionaryItem(ctx context.Context, client api.Client, opts ItemOptions) (*ItemResult, error) {
+	if opts.Key == "" {
+		return nil, ErrMissingItemKey
+	}
+
+	dict, err := LookupDictionary(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := client.GetDictionaryItem(&fastly.GetDictionaryItemInput{
+		Service:    opts.Service,
+		Dictionary: dict.ID,
+		ItemKey:    opts.Key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the item '%s' in the dictionary '%s': %s", opts.Key, dict.Name, err)
+	}
+
+	return &ItemResult{
+		Service:        opts.Service,
+		Dictionary:     *dict,
+		DictionaryItem: DictionaryItem{Key: item.ItemKey, Value: item.ItemValue},
+	}, nil
+}
+
+// SetDictionaryItem creates the item, or replaces its value when it exists
+// the change is live immediately (items aren't versioned)
+func SetDictionaryItem(ctx context.Context, client api.Client, opts ItemOptions) (*ItemResult, error) {
+	if opts.Key == "" {
+		return nil, ErrMissingItemKey
+	}
+
+	dict, err := LookupDictionary(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// go-fastly has no single item upsert, so it's made as a batch of one
+	err = client.BatchModifyDictionaryItems(&fastly.BatchModifyDictionaryItemsInput{
+		Service:    opts.Service,
+		Dictionary: dict.ID,
+		Items: []*fastly.BatchDictionaryItem{{
+			Operation: fastly.UpsertBatchOperation,
+			ItemKey:   opts.Key,
+			ItemValue: opts.Value,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to set the item '%s' in the dictionary '%s': %s", opts.Key, dict.Name, err)
+	}
+
+	return &ItemResult{
+		Service:        opts.Service,
+		Dictionary:     *dict,
+		DictionaryItem: DictionaryItem{Key: opts.Key, Value: opts.Value},
+	}, nil
+}
+
+// DeleteDictionaryItem removes the item from the dictionary
+// the change is live immediately (items aren't versioned)
+func DeleteDictionaryItem(ctx context.Context, client api.Client, opts ItemOptions) (*ItemResult, error) {
+	if opts.Key == "" {
+		return nil, ErrMissingItemKey
+	}
+
+	dict, err := LookupDictionary(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteDictionaryItem(&fastly.DeleteDictionaryItemInput{
+		Service:    opts.Service,
+		Dictionary: dict.ID,
+		ItemKey:    opts.Key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete the item '%s' from the dictionary '%s': %s", opts.Key, dict.Name, err)
+	}
+
+	return &ItemResult{
+		Service:        opts.Service,
+		Dictionary:     *dict,
+		DictionaryItem: DictionaryItem{Key: opts.Key},
+	}, nil
+}
+
+// PlanImport compares the items of the file against the dictionary, items
+// missing from the file are planned for removal
+func PlanImport(ctx context.Context, client api.Client, opts ImportOptions) (*ImportPlan, error) {
+	local, err := ReadItems(opts.File, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	dict, err := LookupDictionary(ctx, client, ItemOptions{
+		Service:    opts.Service,
+		Dictionary: opts.Dictionary,
+		Version:    opts.Version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if dict.WriteOnly {
+		return nil, fmt.Errorf("the dictionary '%s' is write-only, so its items can't be compared against the file", dict.Name)
+	}
+
+	remote, err := remoteItems(opts.Service, dict, client)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ImportPlan{
+		Service:    opts.Service,
+		Dictionary: *dict,
+	}
+
+	for _, key := range sortedKeys(local) {
+		old, exists := remote[key]
+
+		switch {
+		case !exists:
+			plan.Add = append(plan.Add, ItemChange{Key: key, New: local[key]})
+		case old != local[key]:
+			plan.Change = append(plan.Change, ItemChange{Key: key, Old: old, New: local[key]})
+		default:
+			plan.Unchanged++
+		}
+	}
+
+	for _, key := range sortedKeys(remote) {
+		if _, exists := local[key]; !exists {
+			plan.Remove = append(plan.Remove, ItemChange{Key: key, Old: remote[key]})
+		}
+	}
+
+	return plan, nil
+}
+
+// ApplyImport makes the changes described by the plan in batches of up to
+// fastly.BatchModifyMaximumOperations items
+//
+// each batch is applied atomically, when one fails the result describes the
+// batches that were already applied
+func ApplyImport(ctx context.Context, client api.Client, plan *ImportPlan) (*ImportResult, error) {
+	var ops []*fastly.BatchDictionaryItem
+
+	add := func(changes []ItemChange, op fastly.BatchOperation) {
+		for _, change := range changes {
+			ops = append(ops, &fastly.BatchDictionaryItem{
+				Operation: op,
+				ItemKey:   change.Key,
+				ItemValue: change.New,
+			})
+		}
+	}
+
+	add(plan.Add, fastly.CreateBatchOperation)
+	add(plan.Change, fastly.UpdateBatchOperation)
+	add(plan.Remove, fastly.DeleteBatchOperation)
+
+	result := &ImportResult{
+		Service:    plan.Service,
+		Dictionary: plan.Dictionary,
+		Total:      (len(ops) + fastly.BatchModifyMaximumOperations - 1) / fastly.BatchModifyMaximumOperations,
+	}
+
+	for start := 0; start < len(ops); start += fastly.BatchModifyMaximumOperations {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		end := start + fastly.BatchModifyMaximumOperations
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		err := client.BatchModifyDictionaryItems(&fastly.BatchModifyDictionaryItemsInput{
+			Service:    plan.Service,
+			Dictionary: plan.Dictionary.ID,
+			Items:      ops[start:end],
+		})
+		if err != nil {
+			return result, fmt.Errorf("batch %d of %d failed (%d batch(es) were applied): %s", result.Batches+1, result.Total, result.Batches, err)
+		}
+
+		result.Batches++
+		for _, op := range ops[start:end] {
+			switch op.Operation {
+			case fastly.CreateBatchOperation:
+				result.Added++
+			case fastly.UpdateBatchOperation:
+				result.Changed++
+			case fastly.DeleteBatchOperation:
+				result.Removed++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ReadItems reads the dictionary items of a local file, either a JSON object
+// of keys to values, or a CSV file of key,value rows (with an optional
+// key,value header)
+func ReadItems(path, format string) (map[string]string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(format) {
+	case "json":
+		return readJSONItems(file)
+	case "csv":
+		return readCSVItems(file)
+	}
+
+	return nil, ErrUnknownItemsFormat
+}
+
+// readJSONItems reads an object of keys to values, a number is kept exactly
+// as written (rather than going through a float64, which loses precision)
+func readJSONItems(r io.Reader) (map[string]string, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("unable to parse the items file (expected an object of keys to values): %s", err)
+	}
+
+	items := map[string]string{}
+	for key, value := range values {
+		switch v := value.(type) {
+		case string:
+			items[key] = v
+		case json.Number:
+			items[key] = v.String()
+		case bool:
+			items[key] = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("the value of the item '%s' must be a string, number or boolean", key)
+		}
+	}
+
+	return items, nil
+}
+
+func readCSVItems(r io.Reader) (map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the items file (expected key,value rows): %s", err)
+	}
+
+	if len(records) > 0 && strings.EqualFold(records[0][0], "key") && strings.EqualFold(records[0][1], "value") {
+		records = records[1:]
+	}
+
+	items := map[string]string{}
+	for i, record := range records {
+		key := record[0]
+		if key == "" {
+			return nil, fmt.Errorf("row %d of the items file has no key", i+1)
+		}
+		if _, exists := items[key]; exists {
+			return nil, fmt.Errorf("the item '%s' is in the items file more than once", key)
+		}
+		items[key] = record[1]
+	}
+
+	return items, nil
+}
+
+func findDictionary(service string, version int, name string, client api.Client) (*Dictionary, error) {
+	dicts, err := remoteDictionaries(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dict := range dicts {
+		if dict.Name == name {
+			return &dict, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the dictionary '%s' doesn't exist in version %d", name, version)
+}
+
+func remoteDictionaries(service string, version int, client api.Client) ([]Dictionary, error) {
+	list, err := client.ListDictionaries(&fastly.ListDictionariesInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve list of dictionaries for version %d: %s", version, err)
+	}
+
+	dicts := make([]Dictionary, 0, len(list))
+	for _, d := range list {
+		dicts = append(dicts, dictionary(d))
+	}
+
+	sort.Slice(dicts, func(i, j int) bool {
+		return dicts[i].Name < dicts[j].Name
+	})
+
+	return dicts, nil
+}
+
+func remoteItems(service string, dict *Dictionary, client api.Client) (map[string]string, error) {
+	list, err := client.ListDictionaryItems(&fastly.ListDictionaryItemsInput{
+		Service:    service,
+		Dictionary: dict.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the items of the dictionary '%s': %s", dict.Name, err)
+	}
+
+	items := map[string]string{}
+	for _, item := range list {
+		items[item.ItemKey] = item.ItemValue
+	}
+
+	return items, nil
+}
+
+func dictionary(d *fastly.Dictionary) Dictionary {
+	return Dictionary{
+		Name:      d.Name,
+		ID:        d.ID,
+		WriteOnly: d.WriteOnly,
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
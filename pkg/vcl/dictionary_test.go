@@ -0,0 +1,130 @@
+package vcl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/integralist/go-fastly-cli/pkg/api/apitest"
+)
+
+func TestReadItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		format  string
+		items   map[string]string
+		err     bool
+	}{
+		{
+			name:    "json",
+			file:    "routes.json",
+			content: `{"/old": "/new", "enabled": true, "weight": 1.5}`,
+			items:   map[string]string{"/old": "/new", "enabled": "true", "weight": "1.5"},
+		},
+		{
+			name:    "json numbers are kept exactly",
+			file:    "ids.json",
+			content: `{"id": 12345678901234567890, "small": 0.1, "exponent": 1e3}`,
+			items:   map[string]string{"id": "12345678901234567890", "small": "0.1", "exponent": "1e3"},
+		},
+		{
+			name:    "json null value",
+			file:    "routes.json",
+			content: `{"/old": null}`,
+			err:     true,
+		},
+		{
+			name:    "json array",
+			file:    "routes.json",
+			content: `["/old", "/new"]`,
+			err:     true,
+		},
+		{
+			name:    "csv with a header",
+			file:    "routes.csv",
+			content: "key,value\n/old,/new\n/a,\"b,c\"\n",
+			items:   map[string]string{"/old": "/new", "/a": "b,c"},
+		},
+		{
+			name:    "csv without a header",
+			file:    "routes.csv",
+			content: "/old,/new\n",
+			items:   map[string]string{"/old": "/new"},
+		},
+		{
+			name:    "csv duplicate key",
+			file:    "routes.csv",
+			content: "/old,/new\n/old,/newer\n",
+			err:     true,
+		},
+		{
+			name:    "csv missing key",
+			file:    "routes.csv",
+			content: ",/new\n",
+			err:     true,
+		},
+		{
+			name:    "csv extra column",
+			file:    "routes.csv",
+			content: "/old,/new,/newer\n",
+			err:     true,
+		},
+		{
+			name:    "format overrides the extension",
+			file:    "routes.txt",
+			content: "/old,/new\n",
+			format:  "csv",
+			items:   map[string]string{"/old": "/new"},
+		},
+		{
+			name:    "unknown format",
+			file:    "routes.txt",
+			content: "/old /new\n",
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeDir(t, map[string]string{tt.file: tt.content})
+			defer os.RemoveAll(dir)
+
+			items, err := ReadItems(filepath.Join(dir, tt.file), tt.format)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected an error, got items %v", items)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(items, tt.items) {
+				t.Errorf("got items %v, want %v", items, tt.items)
+			}
+		})
+	}
+}
+
+func TestDictionaryItems(t *testing.T) {
+	f := apitest.NewFake()
+	f.AddService("svc")
+	if _, err := f.SetDictionary("svc", 1, "routes", map[string]string{"/c": "3", "/a": "1", "/b": "2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := DictionaryItems(context.Background(), f, ItemOptions{Service: "svc", Dictionary: "routes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []DictionaryItem{{Key: "/a", Value: "1"}, {Key: "/b", Value: "2"}, {Key: "/c", Value: "3"}}
+	if !reflect.DeepEqual(result.Items, want) {
+		t.Errorf("got items %v, want %v", result.Items, want)
+	}
+}
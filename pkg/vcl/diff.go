@@ -0,0 +1,197 @@
+package vcl
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/diff"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/sirupsen/logrus"
+)
+
+// DiffOptions defines the settings for comparing local VCL files against a
+// remote service version
+type DiffOptions struct {
+	Service   string
+	Directory string
+	Match     string
+	Skip      string
+
+	// Version to compare against (zero means the latest version)
+	Version int
+
+	// Context is the number of unchanged lines rendered around each change
+	Context int
+
+	// Concurrency is the number of files compared at the same time
+	// (zero means DefaultConcurrency)
+	Concurrency int
+}
+
+// DiffResult contains the comparison of each local VCL file
+type DiffResult struct {
+	Service string
+	Version int
+	Files   []FileDiff
+
+	// Failed summarises the files that couldn't be compared
+	Failed FileErrors
+}
+
+// Differences returns the number of files that differ from their remote copy
+func (r DiffResult) Differences() int {
+	count := 0
+	for _, fd := range r.Files {
+		if fd.Err == nil && !fd.Result.Equal() {
+			count++
+		}
+	}
+	return count
+}
+
+// FileDiff is the comparison between a single local file and its remote copy
+type FileDiff struct {
+	Name   string
+	Path   string
+	Result diff.Result
+	Err    error
+}
+
+// Unified renders the file comparison in the unified diff format
+func (fd FileDiff) Unified(version int) string {
+	return fd.Result.Unified(fmt.Sprintf("%s (version %d)", fd.Name, version), fd.Path)
+}
+
+// Diff compares local VCL to the specified remote service vcl version
+func Diff(ctx context.Context, client api.Client, opts DiffOptions) (*DiffResult, error) {
+	if opts.Context < 0 {
+		return nil, diff.ErrNegativeContext
+	}
+
+	r, err := newRun(opts.Service, opts.Match, opts.Skip, opts.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, failed, err := r.processFiles(ctx, selectedVersion, opts.Directory, getVCL, client)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Failed:  failed,
+	}
+
+	for _, vr := range responses {
+		fd := processDiff(vr, opts.Context)
+
+		// the remote failures were already collected by processFiles
+		if fd.Err != nil && vr.Err == nil {
+			result.Failed = append(result.Failed, FileError{Name: fd.Name, Path: fd.Path, Err: fd.Err})
+		}
+
+		result.Files = append(result.Files, fd)
+	}
+
+	return result, nil
+}
+
+func getVCL(ctx context.Context, r *run, selectedVersion int, path string, client api.Client, ch chan vclResponse) {
+	defer r.wg.Done()
+
+	logger.WithFields(logrus.Fields{
+		"channel":  fmt.Sprintf("%p", ch),
+		"length":   len(ch),
+		"capacity": cap(ch),
+	}).Debug("channel view - start of getVCL")
+
+	name := extractName(path)
+
+	logger.WithFields(logrus.Fields{
+		"path": path,
+		"name": name,
+	}).Debug("file processor")
+
+	if err := ctx.Err(); err != nil {
+		ch <- vclResponse{Path: path, Name: name, Err: err}
+		return
+	}
+
+	vclFile, err := client.GetVCL(&fastly.GetVCLInput{
+		Service: r.service,
+		Version: selectedVersion,
+		Name:    name,
+	})
+
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"name":  name,
+			"error": err,
+		}).Debug("error retrieving vcl file from fastly")
+
+		ch <- vclResponse{
+			Path: path,
+			Name: name,
+			Err:  err,
+		}
+
+		logger.WithFields(logrus.Fields{
+			"channel":  fmt.Sprintf("%p", ch),
+			"length":   len(ch),
+			"capacity": cap(ch),
+		}).Debug("channel view - failed retrieval")
+	} else {
+		logger.WithField("name", name).Debug("successfully retrieved vcl file from fastly")
+
+		ch <- vclResponse{
+			Path:    path,
+			Name:    name,
+			Content: vclFile.Content,
+		}
+
+		logger.WithFields(logrus.Fields{
+			"channel":  fmt.Sprintf("%p", ch),
+			"length":   len(ch),
+			"capacity": cap(ch),
+		}).Debug("channel view - successful retrieval")
+	}
+}
+
+func processDiff(vr vclResponse, context int) FileDiff {
+	fd := FileDiff{
+		Name: vr.Name,
+		Path: vr.Path,
+	}
+
+	if vr.Err != nil {
+		fd.Err = vr.Err
+		return fd
+	}
+
+	local, err := getLocalVCL(vr.Path)
+	if err != nil {
+		fd.Err = err
+		return fd
+	}
+
+	fd.Result = diff.Strings(vr.Content, local, diff.VCLOptions(context))
+
+	return fd
+}
+
+func getLocalVCL(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
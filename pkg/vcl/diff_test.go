@@ -0,0 +1,98 @@
+package vcl
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/integralist/go-fastly-cli/diff"
+)
+
+func TestDiff(t *testing.T) {
+	dir := writeDir(t, localFiles)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name        string
+		remote      map[string]string
+		opts        DiffOptions
+		differences int
+		failed      []string
+		err         error
+	}{
+		{
+			name: "equal",
+			remote: map[string]string{
+				"main":  localFiles["main.vcl"],
+				"other": localFiles["other.vcl"],
+			},
+		},
+		{
+			name: "ignores whitespace and comments",
+			remote: map[string]string{
+				"main":  "sub vcl_recv {\n\n    # the version\n  set  req.http.X-Version = \"2\";\n}\n",
+				"other": localFiles["other.vcl"],
+			},
+		},
+		{
+			name: "changed file",
+			remote: map[string]string{
+				"main":  "sub vcl_recv {\n  set req.http.X-Version = \"1\";\n}\n",
+				"other": localFiles["other.vcl"],
+			},
+			differences: 1,
+		},
+		{
+			name:        "missing remote file",
+			remote:      map[string]string{"main": "sub vcl_recv {\n}\n"},
+			differences: 1,
+			failed:      []string{"other"},
+		},
+		{
+			name:   "only compares the matching files",
+			remote: map[string]string{"main": localFiles["main.vcl"]},
+			opts:   DiffOptions{Match: `main\.vcl$`},
+		},
+		{
+			name:   "unknown version",
+			remote: map[string]string{},
+			opts:   DiffOptions{Version: 5},
+			failed: []string{"main", "other"},
+		},
+		{
+			name: "negative context",
+			opts: DiffOptions{Context: -1},
+			err:  diff.ErrNegativeContext,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newService(t, tt.remote)
+
+			opts := tt.opts
+			opts.Service = "svc"
+			opts.Directory = dir
+
+			result, err := Diff(context.Background(), f, opts)
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := result.Differences(); got != tt.differences {
+				t.Errorf("got %d differences, want %d", got, tt.differences)
+			}
+
+			var failed []string
+			for _, fe := range result.Failed {
+				failed = append(failed, fe.Name)
+			}
+			if !equalNames(failed, tt.failed) {
+				t.Errorf("got failures %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
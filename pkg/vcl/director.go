@@ -0,0 +1,450 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// ErrMissingDirectorName is returned when an operation requires a director
+// name
+var ErrMissingDirectorName = errors.New("you must provide a director name")
+
+// DirectorTypes are the names of the ways a director selects a backend
+var DirectorTypes = []string{"random", "round-robin", "hash", "client"}
+
+// directorTypes maps the names of DirectorTypes to the API's values
+var directorTypes = map[string]fastly.DirectorType{
+	"random":      fastly.DirectorTypeRandom,
+	"round-robin": fastly.DirectorTypeRoundRobin,
+	"hash":        fastly.DirectorTypeHash,
+	"client":      fastly.DirectorTypeClient,
+}
+
+// Director is a group of backends that requests are balanced across
+type Director struct {
+	Name    string
+	Comment string
+
+	// Type is one of DirectorTypes
+	Type string
+
+	// Quorum is the percentage of the backends that must be healthy for the
+	// director to be healthy, and Retries the number of backends tried
+	Quorum  uint
+	Retries uint
+
+	// Backends are the names of the director's backends (sorted)
+	Backends []string
+}
+
+// DirectorSettings are the director settings to create or update with, only
+// the settings that aren't nil are changed (Backends replaces the backends)
+type DirectorSettings struct {
+	Type     *string
+	Quorum   *uint
+	Retries  *uint
+	Backends *[]string
+}
+
+// DirectorsResult contains the directors of the remote service version
+type DirectorsResult struct {
+	Service   string
+	Version   int
+	Directors []Director
+}
+
+// DirectorOptions defines the director to create, update or delete and the
+// version to make the change to
+type DirectorOptions struct {
+	Target
+
+	Name     string
+	Settings DirectorSettings
+}
+
+// DirectorResult describes the director that was (or would be) created,
+// updated or deleted, along with the version it was changed in
+//
+// Unchanged reports an update with nothing to do (no version is cloned), and
+// Warnings are the problems with the references the change introduces
+type DirectorResult struct {
+	TargetVersion
+	Director  Director
+	Unchanged bool
+	Warnings  []Problem
+}
+
+// ListDirectors returns every director (along with its backends) in the
+// remote service version
+func ListDirectors(ctx context.Context, client api.Client, opts ListOptions) (*DirectorsResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	directors, err := remoteDirectors(opts.Service, selectedVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DirectorsResult{
+		Service:   opts.Service,
+		Version:   selectedVersion,
+		Directors: directors,
+	}, nil
+}
+
+// PlanCreateDirector validates the director (and the backends it references)
+// that CreateDirector would create, and which version it would be created
+// in, without making any changes to the service
+func PlanCreateDirector(ctx context.Context, client api.Client, opts DirectorOptions) (*DirectorResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingDirectorName
+	}
+
+	planned := Director{Name: opts.Name, Type: "random"}
+	opts.Settings.apply(&planned)
+	if err := validateDirector(planned); err != nil {
+		return nil, err
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := findDirector(opts.Service, target.Version, opts.Name, client); err == nil {
+		return nil, fmt.Errorf("the director '%s' already exists in version %d", opts.Name, target.Version)
+	}
+
+	warnings, err := checkReferences(opts.Service, target.Version, client, func(r *References) {
+		r.setDirector(planned)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DirectorResult{
+		TargetVersion: *target,
+		Director:      planned,
+		Warnings:      warnings,
+	}, nil
+}
+
+// CreateDirector creates the director in the target version and then adds
+// its backends to it
+func CreateDirector(ctx context.Context, client api.Client, opts DirectorOptions) (*DirectorResult, error) {
+	plan, err := PlanCreateDirector(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	d := plan.Director
+	_, err = client.CreateDirector(&fastly.CreateDirectorInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    opts.Name,
+		Type:    directorTypes[d.Type],
+		Quorum:  d.Quorum,
+		Retries: d.Retries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the director '%s' in version %d: %s", opts.Name, target.Version, err)
+	}
+
+	if err := updateMembers(opts.Service, target.Version, opts.Name, nil, d.Backends, client); err != nil {
+		return nil, err
+	}
+
+	return &DirectorResult{
+		TargetVersion: *target,
+		Director:      d,
+		Warnings:      plan.Warnings,
+	}, nil
+}
+
+// PlanUpdateDirector validates the settings (and the backends) that
+// UpdateDirector would change, and which version it would change them in,
+// without making any changes to the service
+func PlanUpdateDirector(ctx context.Context, client api.Client, opts DirectorOptions) (*DirectorResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingDirectorName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findDirector(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *current
+	opts.Settings.apply(&updated)
+	if err := validateDirector(updated); err != nil {
+		return nil, err
+	}
+
+	warnings, err := checkReferences(opts.Service, target.Version, client, func(r *References) {
+		r.setDirector(updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DirectorResult{
+		TargetVersion: *target,
+		Director:      updated,
+		Unchanged:     reflect.DeepEqual(*current, updated),
+		Warnings:      warnings,
+	}, nil
+}
+
+// UpdateDirector changes the settings of the director in the target version
+// and adds (or removes) the backends that differ, when nothing differs the
+// service is left as it is
+func UpdateDirector(ctx context.Context, client api.Client, opts DirectorOptions) (*DirectorResult, error) {
+	plan, err := PlanUpdateDirector(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Unchanged {
+		plan.TargetVersion = TargetVersion{Service: plan.Service, Version: plan.Version}
+		return plan, nil
+	}
+
+	current, err := findDirector(opts.Service, plan.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	s := opts.Settings
+	if s.Type != nil || s.Quorum != nil || s.Retries != nil {
+		_, err = client.UpdateDirector(&fastly.UpdateDirectorInput{
+			Service: opts.Service,
+			Version: target.Version,
+			Name:    opts.Name,
+			Type:    directorTypes[stringValue(s.Type)],
+			Quorum:  uintValue(s.Quorum),
+			Retries: uintValue(s.Retries),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to update the director '%s' in version %d: %s", opts.Name, target.Version, err)
+		}
+	}
+
+	if err := updateMembers(opts.Service, target.Version, opts.Name, current.Backends, plan.Director.Backends, client); err != nil {
+		return nil, err
+	}
+
+	return &DirectorResult{
+		TargetVersion: *target,
+		Director:      plan.Director,
+		Warnings:      plan.Warnings,
+	}, nil
+}
+
+// PlanDeleteDirector describes which version DeleteDirector would delete the
+// director from without making any changes to the service
+func PlanDeleteDirector(ctx context.Context, client api.Client, opts DirectorOptions) (*DirectorResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingDirectorName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findDirector(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DirectorResult{
+		TargetVersion: *target,
+		Director:      *current,
+	}, nil
+}
+
+// DeleteDirector deletes the director from the target version, its backends
+// aren't deleted
+func DeleteDirector(ctx context.Context, client api.Client, opts DirectorOptions) (*DirectorResult, error) {
+	plan, err := PlanDeleteDirector(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteDirector(&fastly.DeleteDirectorInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete the director '%s' from version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &DirectorResult{
+		TargetVersion: *target,
+		Director:      plan.Director,
+	}, nil
+}
+
+// apply sets the settings that aren't nil on the director
+func (s DirectorSettings) apply(d *Director) {
+	applyString(&d.Type, s.Type)
+	applyUint(&d.Quorum, s.Quorum)
+	applyUint(&d.Retries, s.Retries)
+
+	if s.Backends != nil {
+		d.Backends = uniqueSorted(*s.Backends)
+	}
+}
+
+// validateDirector checks the settings the API would reject, so an invalid
+// director is reported before a version is cloned
+func validateDirector(d Director) error {
+	if _, ok := directorTypes[d.Type]; !ok {
+		return fmt.Errorf("unknown director type '%s' (try: %s)", d.Type, strings.Join(DirectorTypes, ", "))
+	}
+	if d.Quorum > 100 {
+		return fmt.Errorf("the quorum of the director '%s' is a percentage, %d is above 100", d.Name, d.Quorum)
+	}
+	return nil
+}
+
+// updateMembers adds the backends of the director that aren't in current and
+// removes the ones that are no longer wanted
+func updateMembers(service string, version int, director string, current, wanted []string, client api.Client) error {
+	members := map[string]bool{}
+	for _, backend := range current {
+		members[backend] = true
+	}
+
+	for _, backend := range wanted {
+		if members[backend] {
+			delete(members, backend)
+			continue
+		}
+
+		_, err := client.CreateDirectorBackend(&fastly.CreateDirectorBackendInput{
+			Service:  service,
+			Version:  version,
+			Director: director,
+			Backend:  backend,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to add the backend '%s' to the director '%s' in version %d: %s", backend, director, version, err)
+		}
+	}
+
+	for _, backend := range current {
+		if !members[backend] {
+			continue
+		}
+
+		err := client.DeleteDirectorBackend(&fastly.DeleteDirectorBackendInput{
+			Service:  service,
+			Version:  version,
+			Director: director,
+			Backend:  backend,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to remove the backend '%s' from the director '%s' in version %d: %s", backend, director, version, err)
+		}
+	}
+
+	return nil
+}
+
+func findDirector(service string, version int, name string, client api.Client) (*Director, error) {
+	directors, err := remoteDirectors(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range directors {
+		if d.Name == name {
+			return &d, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the director '%s' doesn't exist in version %d", name, version)
+}
+
+// remoteDirectors returns the directors of the version along with the names
+// of their backends (which may not exist)
+func remoteDirectors(service string, version int, client api.Client) ([]Director, error) {
+	list, err := api.Directors(client, service, version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve list of directors for version %d: %s", version, err)
+	}
+
+	directors := make([]Director, 0, len(list))
+	for _, d := range list {
+		director := Director{
+			Name:     d.Name,
+			Comment:  d.Comment,
+			Type:     directorType(d.Type),
+			Quorum:   d.Quorum,
+			Retries:  d.Retries,
+			Backends: d.Backends,
+		}
+		sort.Strings(director.Backends)
+
+		directors = append(directors, director)
+	}
+
+	sort.Slice(directors, func(i, j int) bool {
+		return directors[i].Name < directors[j].Name
+	})
+
+	return directors, nil
+}
+
+func directorType(t fastly.DirectorType) string {
+	for name, value := range directorTypes {
+		if value == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("unknown (%d)", t)
+}
+
+func uniqueSorted(names []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
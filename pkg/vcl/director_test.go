@@ -0,0 +1,61 @@
+package vcl
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+func TestListDirectors(t *testing.T) {
+	f := newService(t, nil)
+	if err := f.SetBackend("svc", 1, fastly.Backend{Name: "origin", Address: "origin.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	directors := map[string][]string{
+		"pool":  {"origin", "missing"},
+		"empty": nil,
+	}
+	for name, backends := range directors {
+		if err := f.SetDirector("svc", 1, fastly.Director{Name: name, Type: fastly.DirectorTypeRandom, Quorum: 75, Retries: 5}, backends); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := ListDirectors(context.Background(), f, ListOptions{Service: "svc", Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Director{
+		{Name: "empty", Type: "random", Quorum: 75, Retries: 5},
+		{Name: "pool", Type: "random", Quorum: 75, Retries: 5, Backends: []string{"missing", "origin"}},
+	}
+	if len(result.Directors) != len(want) {
+		t.Fatalf("got directors %+v, want %+v", result.Directors, want)
+	}
+	for i, d := range result.Directors {
+		if d.Name != want[i].Name || d.Type != want[i].Type || d.Quorum != want[i].Quorum || d.Retries != want[i].Retries || !equalNames(d.Backends, want[i].Backends) {
+			t.Errorf("got director %+v, want %+v", d, want[i])
+		}
+	}
+
+	// every director, along with its backends, is read in a single request
+	if calls := f.Calls("Get"); calls != 1 {
+		t.Errorf("made %d requests for the directors, want 1", calls)
+	}
+
+	refs, err := remoteReferences("svc", 1, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems := []Problem{
+		{Object: "director 'empty'", Message: "has no backends", Warning: true},
+		{Object: "director 'pool'", Message: "references the backend 'missing', which doesn't exist"},
+	}
+	if got := refs.Check(); !reflect.DeepEqual(got, problems) {
+		t.Errorf("got problems %+v, want %+v", got, problems)
+	}
+}
@@ -0,0 +1,372 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/dns"
+)
+
+// ErrMissingDomainName is returned when an operation requires a domain name
+var ErrMissingDomainName = errors.New("you must provide a domain name (e.g. www.example.com)")
+
+// the status of a domain's DNS as resolved locally
+const (
+	// DomainOK is a domain that CNAMEs to Fastly
+	DomainOK = "ok"
+
+	// DomainNotFastly is a domain that CNAMEs to somewhere other than Fastly
+	DomainNotFastly = "not-fastly"
+
+	// DomainNoCNAME is a domain without a CNAME record (e.g. an apex domain
+	// using A records)
+	DomainNoCNAME = "no-cname"
+
+	// DomainUnresolved is a domain that couldn't be resolved
+	DomainUnresolved = "unresolved"
+
+	// DomainWildcard is a wildcard domain (e.g. *.example.com), which can't be
+	// resolved so is only checked by Fastly
+	DomainWildcard = "wildcard"
+)
+
+// fastlyZone is the zone every name Fastly serves a domain from belongs to
+// (e.g. global.prod.fastly.net or dualstack.example.map.fastly.net)
+const fastlyZone = "fastly.net"
+
+// Domain is a domain name the service version responds to
+type Domain struct {
+	Name    string
+	Comment string
+}
+
+// DomainsResult contains the domains of the remote service version
+type DomainsResult struct {
+	Service string
+	Version int
+	Domains []Domain
+}
+
+// DomainOptions defines the domain to add or remove and the version to make
+// the change to
+type DomainOptions struct {
+	Target
+
+	Name string
+}
+
+// DomainResult describes the domain that was (or would be) added or removed,
+// along with the version it was changed in
+type DomainResult struct {
+	TargetVersion
+	Domain Domain
+}
+
+// DomainCheckOptions defines the service version whose domains are checked
+// and the resolver used to resolve them locally (nil means dns.System)
+type DomainCheckOptions struct {
+	Service string
+
+	// Version to check the domains of (zero means the latest version)
+	Version  int
+	Resolver dns.Resolver
+}
+
+// DomainCheckResult contains the DNS of each domain of the service version
+type DomainCheckResult struct {
+	Service string
+	Version int
+	Domains []DomainStatus
+}
+
+// Failures returns the number of domains that don't CNAME correctly to Fastly
+func (r DomainCheckResult) Failures() int {
+	failures := 0
+	for _, d := range r.Domains {
+		if !d.Correct() {
+			failures++
+		}
+	}
+	return failures
+}
+
+// DomainStatus is the DNS of a domain as seen by Fastly and resolved locally
+type DomainStatus struct {
+	Name string
+
+	// FastlyCNAME is the name Fastly found the domain pointing at, and
+	// FastlyValid whether Fastly considers it to point at Fastly
+	FastlyCNAME string
+	FastlyValid bool
+
+	// LocalCNAME is the canonical name the local resolver found (the end of
+	// the chain of CNAME records), Status is one of the Domain constants and
+	// Error describes why a domain is DomainUnresolved
+	LocalCNAME string
+	Status     string
+	Error      string
+}
+
+// Correct reports whether both Fastly and the local resolver find the domain
+// pointing at Fastly
+func (d DomainStatus) Correct() bool {
+	return d.FastlyValid && (d.Status == DomainOK || d.Status == DomainWildcard)
+}
+
+// ListDomains returns every domain in the remote service version
+func ListDomains(ctx context.Context, client api.Client, opts ListOptions) (*DomainsResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	domains, err := remoteDomains(opts.Service, selectedVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DomainsResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Domains: domains,
+	}, nil
+}
+
+// PlanAddDomain validates the domain name and describes which version
+// AddDomain would add it to without making any changes to the service
+func PlanAddDomain(ctx context.Context, client api.Client, opts DomainOptions) (*DomainResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingDomainName
+	}
+	if err := validateDomain(opts.Name); err != nil {
+		return nil, err
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := findDomain(opts.Service, target.Version, opts.Name, client); err == nil {
+		return nil, fmt.Errorf("the domain '%s' already exists in version %d", opts.Name, target.Version)
+	}
+
+	return &DomainResult{
+		TargetVersion: *target,
+		Domain:        Domain{Name: opts.Name},
+	}, nil
+}
+
+// AddDomain adds the domain to the target version
+func AddDomain(ctx context.Context, client api.Client, opts DomainOptions) (*DomainResult, error) {
+	plan, err := PlanAddDomain(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	domain, err := client.CreateDomain(&fastly.CreateDomainInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to add the domain '%s' to version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &DomainResult{
+		TargetVersion: *target,
+		Domain:        Domain{Name: domain.Name, Comment: domain.Comment},
+	}, nil
+}
+
+// PlanRemoveDomain describes which version RemoveDomain would remove the
+// domain from without making any changes to the service
+func PlanRemoveDomain(ctx context.Context, client api.Client, opts DomainOptions) (*DomainResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingDomainName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	domain, err := findDomain(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DomainResult{
+		TargetVersion: *target,
+		Domain:        *domain,
+	}, nil
+}
+
+// RemoveDomain removes the domain from the target version
+func RemoveDomain(ctx context.Context, client api.Client, opts DomainOptions) (*DomainResult, error) {
+	plan, err := PlanRemoveDomain(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteDomain(&fastly.DeleteDomainInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    plan.Domain.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to remove the domain '%s' from version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &DomainResult{
+		TargetVersion: *target,
+		Domain:        plan.Domain,
+	}, nil
+}
+
+// CheckDomains asks Fastly to check the DNS of every domain of the service
+// version, and resolves each of them locally to confirm they CNAME to Fastly
+// (the local result shows DNS changes Fastly hasn't seen yet)
+//
+// The requester makes the check call, which go-fastly doesn't provide
+func CheckDomains(ctx context.Context, client api.Client, requester api.Requester, opts DomainCheckOptions) (*DomainCheckResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	checks, err := api.CheckDomains(requester, opts.Service, selectedVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check the domains of version %d: %s", selectedVersion, err)
+	}
+
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = dns.System
+	}
+
+	domains := make([]DomainStatus, 0, len(checks))
+	for _, check := range checks {
+		status := resolveDomain(ctx, resolver, check.Name)
+		status.FastlyCNAME = check.CNAME
+		status.FastlyValid = check.Valid
+		domains = append(domains, status)
+	}
+
+	sort.Slice(domains, func(i, j int) bool {
+		return domains[i].Name < domains[j].Name
+	})
+
+	return &DomainCheckResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Domains: domains,
+	}, nil
+}
+
+// resolveDomain follows the CNAME records of the domain with the resolver
+func resolveDomain(ctx context.Context, resolver dns.Resolver, name string) DomainStatus {
+	status := DomainStatus{Name: name}
+
+	if strings.HasPrefix(name, "*.") {
+		status.Status = DomainWildcard
+		return status
+	}
+
+	canonical, err := resolver.LookupCNAME(ctx, name)
+	if err != nil {
+		status.Status = DomainUnresolved
+		status.Error = err.Error()
+		return status
+	}
+
+	canonical = strings.ToLower(strings.TrimSuffix(canonical, "."))
+	switch {
+	case canonical == strings.ToLower(strings.TrimSuffix(name, ".")):
+		status.Status = DomainNoCNAME
+	case canonical == fastlyZone || strings.HasSuffix(canonical, "."+fastlyZone):
+		status.LocalCNAME = canonical
+		status.Status = DomainOK
+	default:
+		status.LocalCNAME = canonical
+		status.Status = DomainNotFastly
+	}
+
+	return status
+}
+
+// validateDomain checks the name is a hostname (optionally a wildcard), so an
+// invalid domain is reported before a version is cloned
+func validateDomain(name string) error {
+	invalid := fmt.Errorf("invalid domain '%s' (expected e.g. www.example.com)", name)
+
+	host := strings.TrimPrefix(name, "*.")
+	labels := strings.Split(host, ".")
+	if len(host) > 253 || len(labels) < 2 {
+		return invalid
+	}
+
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return invalid
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return invalid
+			}
+		}
+	}
+
+	return nil
+}
+
+func findDomain(service string, version int, name string, client api.Client) (*Domain, error) {
+	domains, err := remoteDomains(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range domains {
+		if strings.EqualFold(d.Name, name) {
+			return &d, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the domain '%s' doesn't exist in version %d", name, version)
+}
+
+func remoteDomains(service string, version int, client api.Client) ([]Domain, error) {
+	list, err := client.ListDomains(&fastly.ListDomainsInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve list of domains for version %d: %s", version, err)
+	}
+
+	domains := make([]Domain, 0, len(list))
+	for _, d := range list {
+		domains = append(domains, Domain{Name: d.Name, Comment: d.Comment})
+	}
+
+	sort.Slice(domains, func(i, j int) bool {
+		return domains[i].Name < domains[j].Name
+	})
+
+	return domains, nil
+}
@@ -0,0 +1,38 @@
+package vcl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FileError is the failure of an operation on a single VCL file
+type FileError struct {
+	Name string
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name, e.Err)
+}
+
+// FileErrors collects the failures of every file processed by an operation
+// so partial failures can be reported as a single summary
+type FileErrors []FileError
+
+func (e FileErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, fe := range e {
+		lines[i] = fe.Error()
+	}
+
+	return fmt.Sprintf("%d file(s) failed:\n\t%s", len(e), strings.Join(lines, "\n\t"))
+}
+
+// Err returns the collected failures as an error (nil when nothing failed)
+func (e FileErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
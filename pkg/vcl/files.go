@@ -0,0 +1,188 @@
+package vcl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/sirupsen/logrus"
+)
+
+// data structure for Fastly API response
+type vclResponse struct {
+	Path    string
+	Name    string
+	Content string
+	Created bool
+	Err     error
+}
+
+// DefaultConcurrency is the number of files processed at the same time when
+// no concurrency is specified
+const DefaultConcurrency = 10
+
+// run carries the state of a single operation (the service, the user
+// specific filtering, the files found and the goroutines processing them)
+// so separate operations can safely happen at the same time
+type run struct {
+	service string
+
+	// regex used to define user specific filtering
+	// a nil skip regex means nothing is skipped
+	match *regexp.Regexp
+	skip  *regexp.Regexp
+
+	// the number of files processed at the same time
+	concurrency int
+
+	// the WaitGroup is used when processing files with multiple goroutines
+	wg sync.WaitGroup
+
+	// list of VCL files to process, found within the root directory
+	root  string
+	files []string
+}
+
+type fileProcessor func(context.Context, *run, int, string, api.Client, chan vclResponse)
+
+// newRun compiles the match/skip regexes for an operation on the service
+func newRun(service, match, skip string, concurrency int) (*run, error) {
+	logger.WithFields(logrus.Fields{
+		"service": service,
+		"skip":    skip,
+		"match":   match,
+	}).Debug("compile skip/match regexes")
+
+	r := &run{
+		service:     service,
+		concurrency: concurrency,
+	}
+
+	var err error
+
+	// an empty skip regex would otherwise match (and so skip) every file
+	if skip != "" {
+		r.skip, err = regexp.Compile(skip)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skip regex: %s", err)
+		}
+	}
+
+	r.match, err = regexp.Compile(match)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match regex: %s", err)
+	}
+
+	return r, nil
+}
+
+// function called by filepath.Walk
+// the snippets aren't whole VCL files, so their directory is skipped
+func (r *run) aggregate(path string, f os.FileInfo, err error) error {
+	if f != nil && f.IsDir() && path == filepath.Join(r.root, SnippetsDir) {
+		return filepath.SkipDir
+	}
+
+	if validPathDefaults(path) && r.validPathUserDefined(path) && !r.invalidPathUserDefined(path) {
+		r.files = append(r.files, path)
+	}
+
+	return nil
+}
+
+func validPathDefaults(path string) bool {
+	return !strings.Contains(path, ".git") && strings.Contains(path, ".vcl")
+}
+
+func (r *run) validPathUserDefined(path string) bool {
+	return r.match == nil || r.match.MatchString(path)
+}
+
+func (r *run) invalidPathUserDefined(path string) bool {
+	return r.skip != nil && r.skip.MatchString(path)
+}
+
+func extractName(path string) string {
+	_, file := filepath.Split(path)
+	return strings.Split(file, ".")[0]
+}
+
+// aggregateFiles returns all available local VCL files within the specified
+// directory that satisfy the run's match/skip regexes
+func (r *run) aggregateFiles(dir string) ([]string, error) {
+	// reset slice so no data shared between calls
+	r.root = dir
+	r.files = []string{}
+
+	walkError := filepath.Walk(dir, r.aggregate)
+	if walkError != nil {
+		return nil, fmt.Errorf("filepath.Walk() returned an error: %v", walkError)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"files":  r.files,
+		"length": len(r.files),
+	}).Debug("aggregated files")
+
+	return r.files, nil
+}
+
+// processFiles first aggregates all available local VCL files within the
+// specified directory, then hands them over to processPaths
+//
+// the returned error is only for failures that prevented any processing,
+// failures of individual files are collected in the FileErrors
+func (r *run) processFiles(ctx context.Context, selectedVersion int, dir string, fp fileProcessor, client api.Client) ([]vclResponse, FileErrors, error) {
+	paths, err := r.aggregateFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses, failed := r.processPaths(ctx, selectedVersion, paths, fp, client)
+	return responses, failed, nil
+}
+
+// processPaths spins up a goroutine for each VCL file, with no more than
+// the run's concurrency (or DefaultConcurrency when zero) at the same time
+// the goroutine behaviour is provided by the caller
+// finally, it drains the buffered channel and returns the responses along
+// with the errors collected from each goroutine
+func (r *run) processPaths(ctx context.Context, selectedVersion int, paths []string, fp fileProcessor, client api.Client) ([]vclResponse, FileErrors) {
+	concurrency := r.concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	ch := make(chan vclResponse, len(paths))
+	workers := make(chan struct{}, concurrency)
+
+	for _, vclPath := range paths {
+		r.wg.Add(1)
+		workers <- struct{}{}
+
+		go func(vclPath string) {
+			defer func() { <-workers }()
+			fp(ctx, r, selectedVersion, vclPath, client, ch)
+		}(vclPath)
+	}
+	r.wg.Wait()
+
+	close(ch)
+
+	var failed FileErrors
+	responses := make([]vclResponse, 0, len(paths))
+	for vclFile := range ch {
+		responses = append(responses, vclFile)
+
+		if vclFile.Err != nil {
+			failed = append(failed, FileError{Name: vclFile.Name, Path: vclFile.Path, Err: vclFile.Err})
+		}
+	}
+
+	return responses, failed
+}
@@ -0,0 +1,399 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// ErrMissingHealthCheckName is returned when an operation requires a
+// healthcheck name
+var ErrMissingHealthCheckName = errors.New("you must provide a healthcheck name")
+
+// HealthCheck is a check of the health of the backends that reference it
+type HealthCheck struct {
+	Name    string
+	Comment string
+
+	Method      string
+	Host        string
+	Path        string
+	HTTPVersion string
+
+	// ExpectedResponse is the status code of a healthy response
+	ExpectedResponse uint
+
+	// Timeout and CheckInterval are in milliseconds
+	Timeout       uint
+	CheckInterval uint
+
+	// Threshold is the number of the last Window checks that must pass for
+	// the backend to be healthy, and Initial the number assumed to have
+	// passed when the version is activated
+	Window    uint
+	Threshold uint
+	Initial   uint
+}
+
+// HealthCheckSettings are the healthcheck settings to create or update
+// with, only the settings that aren't nil are changed
+type HealthCheckSettings struct {
+	Method      *string
+	Host        *string
+	Path        *string
+	HTTPVersion *string
+
+	ExpectedResponse *uint
+	Timeout          *uint
+	CheckInterval    *uint
+	Window           *uint
+	Threshold        *uint
+	Initial          *uint
+}
+
+// HealthChecksResult contains the healthchecks of the remote service version
+type HealthChecksResult struct {
+	Service      string
+	Version      int
+	HealthChecks []HealthCheck
+}
+
+// HealthCheckOptions defines the healthcheck to create, update or delete and
+// the version to make the change to
+type HealthCheckOptions struct {
+	Target
+
+	Name     string
+	Settings HealthCheckSettings
+}
+
+// HealthCheckResult describes the healthcheck that was (or would be)
+// created, updated or deleted, along with the version it was changed in
+//
+// Unchanged reports an update with nothing to do (no version is cloned), and
+// Warnings are the problems with the references the change introduces
+type HealthCheckResult struct {
+	TargetVersion
+	HealthCheck HealthCheck
+	Unchanged   bool
+	Warnings    []Problem
+}
+
+// ListHealthChecks returns every healthcheck in the remote service version
+func ListHealthChecks(ctx context.Context, client api.Client, opts ListOptions) (*HealthChecksResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	healthChecks, err := remoteHealthChecks(opts.Service, selectedVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HealthChecksResult{
+		Service:      opts.Service,
+		Version:      selectedVersion,
+		HealthChecks: healthChecks,
+	}, nil
+}
+
+// PlanCreateHealthCheck validates the healthcheck that CreateHealthCheck
+// would create, and which version it would be created in, without making any
+// changes to the service
+func PlanCreateHealthCheck(ctx context.Context, client api.Client, opts HealthCheckOptions) (*HealthCheckResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingHealthCheckName
+	}
+
+	planned := HealthCheck{Name: opts.Name}
+	opts.Settings.apply(&planned)
+	if err := validateHealthCheck(planned); err != nil {
+		return nil, err
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := findHealthCheck(opts.Service, target.Version, opts.Name, client); err == nil {
+		return nil, fmt.Errorf("the healthcheck '%s' already exists in version %d", opts.Name, target.Version)
+	}
+
+	warnings, err := checkReferences(opts.Service, target.Version, client, func(r *References) {
+		r.setHealthCheck(planned)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &HealthCheckResult{
+		TargetVersion: *target,
+		HealthCheck:   planned,
+		Warnings:      warnings,
+	}, nil
+}
+
+// CreateHealthCheck creates the healthcheck in the target version
+func CreateHealthCheck(ctx context.Context, client api.Client, opts HealthCheckOptions) (*HealthCheckResult, error) {
+	plan, err := PlanCreateHealthCheck(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	hc := plan.HealthCheck
+	created, err := client.CreateHealthCheck(&fastly.CreateHealthCheckInput{
+		Service:          opts.Service,
+		Version:          target.Version,
+		Name:             opts.Name,
+		Method:           hc.Method,
+		Host:             hc.Host,
+		Path:             hc.Path,
+		HTTPVersion:      hc.HTTPVersion,
+		ExpectedResponse: hc.ExpectedResponse,
+		Timeout:          hc.Timeout,
+		CheckInterval:    hc.CheckInterval,
+		Window:           hc.Window,
+		Threshold:        hc.Threshold,
+		Initial:          hc.Initial,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the healthcheck '%s' in version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &HealthCheckResult{
+		TargetVersion: *target,
+		HealthCheck:   healthCheck(created),
+		Warnings:      plan.Warnings,
+	}, nil
+}
+
+// PlanUpdateHealthCheck validates the settings UpdateHealthCheck would
+// change, and which version it would change them in, without making any
+// changes to the service
+func PlanUpdateHealthCheck(ctx context.Context, client api.Client, opts HealthCheckOptions) (*HealthCheckResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingHealthCheckName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findHealthCheck(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *current
+	opts.Settings.apply(&updated)
+	if err := validateHealthCheck(updated); err != nil {
+		return nil, err
+	}
+
+	return &HealthCheckResult{
+		TargetVersion: *target,
+		HealthCheck:   updated,
+		Unchanged:     reflect.DeepEqual(*current, updated),
+	}, nil
+}
+
+// UpdateHealthCheck changes the settings of the healthcheck in the target
+// version, when none of the settings differ the service is left as it is
+func UpdateHealthCheck(ctx context.Context, client api.Client, opts HealthCheckOptions) (*HealthCheckResult, error) {
+	plan, err := PlanUpdateHealthCheck(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Unchanged {
+		plan.TargetVersion = TargetVersion{Service: plan.Service, Version: plan.Version}
+		return plan, nil
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	s := opts.Settings
+	updated, err := client.UpdateHealthCheck(&fastly.UpdateHealthCheckInput{
+		Service:          opts.Service,
+		Version:          target.Version,
+		Name:             opts.Name,
+		Method:           stringValue(s.Method),
+		Host:             stringValue(s.Host),
+		Path:             stringValue(s.Path),
+		HTTPVersion:      stringValue(s.HTTPVersion),
+		ExpectedResponse: uintValue(s.ExpectedResponse),
+		Timeout:          uintValue(s.Timeout),
+		CheckInterval:    uintValue(s.CheckInterval),
+		Window:           uintValue(s.Window),
+		Threshold:        uintValue(s.Threshold),
+		Initial:          uintValue(s.Initial),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to update the healthcheck '%s' in version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &HealthCheckResult{
+		TargetVersion: *target,
+		HealthCheck:   healthCheck(updated),
+	}, nil
+}
+
+// PlanDeleteHealthCheck checks no backend references the healthcheck that
+// DeleteHealthCheck would delete, and which version it would be deleted
+// from, without making any changes to the service
+func PlanDeleteHealthCheck(ctx context.Context, client api.Client, opts HealthCheckOptions) (*HealthCheckResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingHealthCheckName
+	}
+
+	target, err := PlanTarget(ctx, client, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findHealthCheck(opts.Service, target.Version, opts.Name, client)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings, err := checkReferences(opts.Service, target.Version, client, func(r *References) {
+		r.removeHealthCheck(opts.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &HealthCheckResult{
+		TargetVersion: *target,
+		HealthCheck:   *current,
+		Warnings:      warnings,
+	}, nil
+}
+
+// DeleteHealthCheck deletes the healthcheck from the target version
+func DeleteHealthCheck(ctx context.Context, client api.Client, opts HealthCheckOptions) (*HealthCheckResult, error) {
+	plan, err := PlanDeleteHealthCheck(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, plan.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteHealthCheck(&fastly.DeleteHealthCheckInput{
+		Service: opts.Service,
+		Version: target.Version,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete the healthcheck '%s' from version %d: %s", opts.Name, target.Version, err)
+	}
+
+	return &HealthCheckResult{
+		TargetVersion: *target,
+		HealthCheck:   plan.HealthCheck,
+		Warnings:      plan.Warnings,
+	}, nil
+}
+
+// apply sets the settings that aren't nil on the healthcheck
+func (s HealthCheckSettings) apply(hc *HealthCheck) {
+	applyString(&hc.Method, s.Method)
+	applyString(&hc.Host, s.Host)
+	applyString(&hc.Path, s.Path)
+	applyString(&hc.HTTPVersion, s.HTTPVersion)
+	applyUint(&hc.ExpectedResponse, s.ExpectedResponse)
+	applyUint(&hc.Timeout, s.Timeout)
+	applyUint(&hc.CheckInterval, s.CheckInterval)
+	applyUint(&hc.Window, s.Window)
+	applyUint(&hc.Threshold, s.Threshold)
+	applyUint(&hc.Initial, s.Initial)
+}
+
+// validateHealthCheck checks the settings the API would reject, so an invalid
+// healthcheck is reported before a version is cloned
+func validateHealthCheck(hc HealthCheck) error {
+	if hc.Window == 0 {
+		return nil
+	}
+
+	if hc.Threshold > hc.Window {
+		return fmt.Errorf("the threshold (%d) of the healthcheck '%s' is above its window (%d)", hc.Threshold, hc.Name, hc.Window)
+	}
+	if hc.Initial > hc.Window {
+		return fmt.Errorf("the initial (%d) of the healthcheck '%s' is above its window (%d)", hc.Initial, hc.Name, hc.Window)
+	}
+
+	return nil
+}
+
+func findHealthCheck(service string, version int, name string, client api.Client) (*HealthCheck, error) {
+	healthChecks, err := remoteHealthChecks(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hc := range healthChecks {
+		if hc.Name == name {
+			return &hc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the healthcheck '%s' doesn't exist in version %d", name, version)
+}
+
+func remoteHealthChecks(service string, version int, client api.Client) ([]HealthCheck, error) {
+	list, err := client.ListHealthChecks(&fastly.ListHealthChecksInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve list of healthchecks for version %d: %s", version, err)
+	}
+
+	healthChecks := make([]HealthCheck, 0, len(list))
+	for _, hc := range list {
+		healthChecks = append(healthChecks, healthCheck(hc))
+	}
+
+	sort.Slice(healthChecks, func(i, j int) bool {
+		return healthChecks[i].Name < healthChecks[j].Name
+	})
+
+	return healthChecks, nil
+}
+
+func healthCheck(hc *fastly.HealthCheck) HealthCheck {
+	return HealthCheck{
+		Name:             hc.Name,
+		Comment:          hc.Comment,
+		Method:           hc.Method,
+		Host:             hc.Host,
+		Path:             hc.Path,
+		HTTPVersion:      hc.HTTPVersion,
+		ExpectedResponse: hc.ExpectedResponse,
+		Timeout:          hc.Timeout,
+		CheckInterval:    hc.CheckInterval,
+		Window:           hc.Window,
+		Threshold:        hc.Threshold,
+		Initial:          hc.Initial,
+	}
+}
@@ -0,0 +1,60 @@
+package vcl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// ListOptions defines the settings for listing remote VCL files
+type ListOptions struct {
+	Service string
+
+	// Version to list files from (zero means the latest version)
+	Version int
+}
+
+// ListResult contains the VCL files found in the remote service version
+type ListResult struct {
+	Service string
+	Version int
+	Files   []RemoteFile
+}
+
+// RemoteFile is a VCL file found in the remote service version
+type RemoteFile struct {
+	Name string
+	Main bool
+}
+
+// List all VCL files found in the remote service version
+func List(ctx context.Context, client api.Client, opts ListOptions) (*ListResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	vclFiles, err := client.ListVCLs(&fastly.ListVCLsInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve list of VCL files for version %d: %s", selectedVersion, err)
+	}
+
+	result := &ListResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+	}
+
+	for _, f := range vclFiles {
+		result.Files = append(result.Files, RemoteFile{
+			Name: f.Name,
+			Main: f.Main,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package vcl
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// MainMarker is the name of the file (at the root of the VCL directory) whose
+// content names the VCL file to be designated as the main VCL
+const MainMarker = ".fastly-main"
+
+// ReadMainMarker returns the main VCL name recorded in the VCL directory
+// an empty string is returned when there is no marker file
+func ReadMainMarker(dir string) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, MainMarker))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return extractName(strings.TrimSpace(string(content))), nil
+}
+
+// resolveMain returns the main VCL name from the options, falling back to the
+// marker file within the VCL directory
+func resolveMain(opts UploadOptions) (string, error) {
+	if opts.Main != "" {
+		return opts.Main, nil
+	}
+	return ReadMainMarker(opts.Directory)
+}
+
+// setMain designates the named VCL file as the main VCL for the version
+func setMain(ctx context.Context, service string, version int, name string, client api.Client) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err := client.ActivateVCL(&fastly.ActivateVCLInput{
+		Service: service,
+		Version: version,
+		Name:    name,
+	})
+	return err
+}
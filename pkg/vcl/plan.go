@@ -0,0 +1,291 @@
+package vcl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/diff"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// Plan describes the changes an operation would make to a remote version
+// computing a plan never makes any mutating API calls
+type Plan struct {
+	Service string
+
+	// Version is the version the plan was computed against
+	// when Clone is true the changes are applied to a clone of it, which is
+	// given the Comment
+	Version int
+	Clone   bool
+	Comment string
+
+	Create    []PlannedFile
+	Update    []PlannedFile
+	Delete    []PlannedFile
+	Unchanged []PlannedFile
+
+	// Main is the VCL file that will be designated as the main VCL
+	// it is empty when the main VCL doesn't need to change
+	Main string
+
+	// Concurrency is the number of files uploaded at the same time when the
+	// plan is applied (zero means DefaultConcurrency)
+	Concurrency int
+}
+
+// target is the version the plan is applied to
+func (p Plan) target() TargetVersion {
+	return TargetVersion{
+		Service: p.Service,
+		Version: p.Version,
+		Clone:   p.Clone,
+		Comment: p.Comment,
+	}
+}
+
+// PlannedFile is a single file within a Plan
+// Path is empty for remote files that don't exist locally
+type PlannedFile struct {
+	Name string
+	Path string
+
+	// Diff is the change from the remote content to the local content
+	Diff diff.Result
+}
+
+// Empty reports whether the plan has no changes to apply
+func (p Plan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Update) == 0 && len(p.Delete) == 0 && p.Main == ""
+}
+
+// ActivatePlan describes what would happen when activating a version
+type ActivatePlan struct {
+	Service string
+	Version int
+
+	// Active is the currently active version (zero if there isn't one)
+	Active int
+
+	// Comment and Locked describe the version that would be activated
+	Comment string
+	Locked  bool
+
+	// Valid and Message are the outcome of validating the version
+	Valid   bool
+	Message string
+
+	// Files are the VCL differences from the active version
+	Files []VersionDiff
+}
+
+// ConfirmActivation decides whether the planned activation goes ahead, the
+// version isn't activated when it returns an error (which is returned)
+type ConfirmActivation func(plan *ActivatePlan) error
+
+// PlanUpload describes which files Upload would create or update, and which
+// version would be cloned, without making any changes to the service
+func PlanUpload(ctx context.Context, client api.Client, opts UploadOptions) (*Plan, error) {
+	return planFiles(ctx, client, opts, nil)
+}
+
+// PlanDelete describes which file Delete would remove without making any
+// changes to the service
+func PlanDelete(ctx context.Context, client api.Client, opts DeleteOptions) (*Plan, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingName
+	}
+
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	vclFile, err := client.GetVCL(&fastly.GetVCLInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the specified VCL file in version %d: %s", selectedVersion, err)
+	}
+
+	return &Plan{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Delete: []PlannedFile{{
+			Name: opts.Name,
+			Diff: diff.Strings(vclFile.Content, "", planDiffOptions),
+		}},
+	}, nil
+}
+
+// PlanActivate describes which version Activate would activate (and which
+// version it would replace) without making any changes to the service
+// the version is validated and its VCL compared against the active version
+func PlanActivate(ctx context.Context, client api.Client, opts VersionOptions) (*ActivatePlan, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := api.GetActiveVersion(opts.Service, client)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := client.GetVersion(&fastly.GetVersionInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem getting version %d: %s", selectedVersion, err)
+	}
+
+	valid, msg, err := client.ValidateVersion(&fastly.ValidateVersionInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem validating version %d: %s", selectedVersion, err)
+	}
+
+	plan := &ActivatePlan{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Active:  active,
+		Comment: target.Comment,
+		Locked:  target.Locked,
+		Valid:   valid,
+		Message: msg,
+	}
+
+	// there's nothing to compare against without an (other) active version
+	if active == 0 || active == selectedVersion {
+		return plan, nil
+	}
+
+	plan.Files, err = CompareVersions(ctx, client, opts.Service, active, selectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// plans show exact differences, as any change will be uploaded
+var planDiffOptions = diff.Options{Context: diff.DefaultContext}
+
+// planFiles compares the local VCL directory against the selected remote
+// version, remote files missing locally are only planned for deletion when
+// deletable reports they can be (a nil deletable deletes nothing)
+func planFiles(ctx context.Context, client api.Client, opts UploadOptions, deletable func(name string) bool) (*Plan, error) {
+	if opts.Clone != 0 && opts.Version != 0 {
+		return nil, ErrConflictingVersions
+	}
+
+	r, err := newRun(opts.Service, opts.Match, opts.Skip, opts.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	main, err := resolveMain(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := PlanTarget(ctx, client, opts.target())
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := r.aggregateFiles(opts.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteFiles, err := client.ListVCLs(&fastly.ListVCLsInput{
+		Service: opts.Service,
+		Version: target.Version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	remote := map[string]string{}
+	for _, vcl := range remoteFiles {
+		remote[vcl.Name] = vcl.Content
+
+		// nothing to change if the requested main VCL is already the main VCL
+		if vcl.Main && vcl.Name == main {
+			main = ""
+		}
+	}
+
+	plan := &Plan{
+		Service:     opts.Service,
+		Version:     target.Version,
+		Clone:       target.Clone,
+		Comment:     target.Comment,
+		Main:        main,
+		Concurrency: opts.Concurrency,
+	}
+
+	local := map[string]bool{}
+	for _, path := range paths {
+		name := extractName(path)
+		local[name] = true
+
+		content, err := getLocalVCL(path)
+		if err != nil {
+			return nil, err
+		}
+
+		remoteContent, exists := remote[name]
+
+		file := PlannedFile{
+			Name: name,
+			Path: path,
+			Diff: diff.Strings(remoteContent, content, planDiffOptions),
+		}
+
+		switch {
+		case !exists:
+			plan.Create = append(plan.Create, file)
+		case remoteContent != content:
+			plan.Update = append(plan.Update, file)
+		default:
+			plan.Unchanged = append(plan.Unchanged, file)
+		}
+	}
+
+	if deletable == nil {
+		return plan, nil
+	}
+
+	// a file the filters excluded is still local (so isn't deleted)
+	unfiltered := &run{service: opts.Service}
+	paths, err = unfiltered.aggregateFiles(opts.Directory)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		local[extractName(path)] = true
+	}
+
+	for name, content := range remote {
+		if !local[name] && deletable(name) {
+			plan.Delete = append(plan.Delete, PlannedFile{
+				Name: name,
+				Diff: diff.Strings(content, "", planDiffOptions),
+			})
+		}
+	}
+	sort.Slice(plan.Delete, func(i, j int) bool {
+		return plan.Delete[i].Name < plan.Delete[j].Name
+	})
+
+	return plan, nil
+}
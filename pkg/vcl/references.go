@@ -0,0 +1,287 @@
+package vcl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// Problem is a broken (or unused) reference between the backends,
+// healthchecks, directors and conditions of a version
+type Problem struct {
+	// Object is the object with the problem, e.g. director 'pool'
+	Object  string
+	Message string
+
+	// Warning problems are reported without preventing the change
+	Warning bool
+}
+
+// String describes the problem
+func (p Problem) String() string {
+	return p.Object + " " + p.Message
+}
+
+// ReferenceError is returned when a change would break a reference between
+// the objects of a version, the change isn't made
+type ReferenceError struct {
+	Version  int
+	Problems []Problem
+}
+
+func (e ReferenceError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		lines = append(lines, "\t"+p.String())
+	}
+	return fmt.Sprintf("the change would break the references in version %d:\n%s", e.Version, strings.Join(lines, "\n"))
+}
+
+// References are the objects of a version that reference each other by name
+//
+//	a backend references a healthcheck and a request condition
+//	a director references its backends
+type References struct {
+	Backends     []Backend
+	HealthChecks []HealthCheck
+	Directors    []Director
+	Conditions   []Condition
+}
+
+// Check returns every problem with the references, a reference to an object
+// that doesn't exist is an error while an object that isn't referenced (e.g.
+// a healthcheck no backend uses) is a warning
+func (r References) Check() []Problem {
+	healthChecks := map[string]bool{}
+	for _, hc := range r.HealthChecks {
+		healthChecks[hc.Name] = true
+	}
+
+	conditions := map[string]string{}
+	for _, c := range r.Conditions {
+		conditions[c.Name] = c.Type
+	}
+
+	backends := map[string]bool{}
+	for _, b := range r.Backends {
+		backends[b.Name] = true
+	}
+
+	var problems []Problem
+	usedHealthChecks := map[string]bool{}
+	usedConditions := map[string]bool{}
+
+	for _, b := range r.Backends {
+		object := fmt.Sprintf("backend '%s'", b.Name)
+
+		if b.HealthCheck != "" {
+			usedHealthChecks[b.HealthCheck] = true
+			if !healthChecks[b.HealthCheck] {
+				problems = append(problems, Problem{Object: object, Message: fmt.Sprintf("references the healthcheck '%s', which doesn't exist", b.HealthCheck)})
+			}
+		}
+
+		if b.RequestCondition != "" {
+			usedConditions[b.RequestCondition] = true
+			conditionType, ok := conditions[b.RequestCondition]
+			switch {
+			case !ok:
+				problems = append(problems, Problem{Object: object, Message: fmt.Sprintf("references the request condition '%s', which doesn't exist", b.RequestCondition)})
+			case conditionType != ConditionRequest:
+				problems = append(problems, Problem{Object: object, Message: fmt.Sprintf("references the condition '%s' as a request condition, but it's a %s condition", b.RequestCondition, conditionType)})
+			}
+		}
+	}
+
+	for _, d := range r.Directors {
+		object := fmt.Sprintf("director '%s'", d.Name)
+
+		if len(d.Backends) == 0 {
+			problems = append(problems, Problem{Object: object, Message: "has no backends", Warning: true})
+		}
+		for _, backend := range d.Backends {
+			if !backends[backend] {
+				problems = append(problems, Problem{Object: object, Message: fmt.Sprintf("references the backend '%s', which doesn't exist", backend)})
+			}
+		}
+	}
+
+	for _, hc := range r.HealthChecks {
+		if !usedHealthChecks[hc.Name] {
+			problems = append(problems, Problem{Object: fmt.Sprintf("healthcheck '%s'", hc.Name), Message: "isn't referenced by any backend", Warning: true})
+		}
+	}
+
+	// conditions are referenced by other objects too (e.g. headers), only the
+	// request conditions are expected to be referenced by a backend
+	for _, c := range r.Conditions {
+		if c.Type == ConditionRequest && !usedConditions[c.Name] {
+			problems = append(problems, Problem{Object: fmt.Sprintf("request condition '%s'", c.Name), Message: "isn't referenced by any backend", Warning: true})
+		}
+	}
+
+	return problems
+}
+
+// checkReferences loads the references of the version and checks the change
+// made to them before it's made to the service, a ReferenceError is returned
+// when the change introduces an error, otherwise any new warnings are
+// returned (the problems the version already had are ignored)
+func checkReferences(service string, version int, client api.Client, change func(*References)) ([]Problem, error) {
+	before, err := remoteReferences(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	after := before.copy()
+	change(&after)
+
+	existing := map[string]bool{}
+	for _, p := range before.Check() {
+		existing[p.String()] = true
+	}
+
+	var errs, warnings []Problem
+	for _, p := range after.Check() {
+		switch {
+		case existing[p.String()]:
+		case p.Warning:
+			warnings = append(warnings, p)
+		default:
+			errs = append(errs, p)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, ReferenceError{Version: version, Problems: errs}
+	}
+
+	return warnings, nil
+}
+
+func remoteReferences(service string, version int, client api.Client) (*References, error) {
+	backends, err := remoteBackends(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	healthChecks, err := remoteHealthChecks(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	directors, err := remoteDirectors(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions, err := remoteConditions(service, version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &References{
+		Backends:     backends,
+		HealthChecks: healthChecks,
+		Directors:    directors,
+		Conditions:   conditions,
+	}, nil
+}
+
+// copy returns references that can be changed without changing r
+func (r References) copy() References {
+	c := References{
+		Backends:     append([]Backend(nil), r.Backends...),
+		HealthChecks: append([]HealthCheck(nil), r.HealthChecks...),
+		Conditions:   append([]Condition(nil), r.Conditions...),
+	}
+	for _, d := range r.Directors {
+		d.Backends = append([]string(nil), d.Backends...)
+		c.Directors = append(c.Directors, d)
+	}
+	return c
+}
+
+// the set methods add the object or replace the object of the same name, and
+// the remove methods remove the object of the name (if any)
+
+func (r *References) setBackend(b Backend) {
+	for i := range r.Backends {
+		if r.Backends[i].Name == b.Name {
+			r.Backends[i] = b
+			return
+		}
+	}
+	r.Backends = append(r.Backends, b)
+}
+
+func (r *References) removeBackend(name string) {
+	kept := r.Backends[:0]
+	for _, b := range r.Backends {
+		if b.Name != name {
+			kept = append(kept, b)
+		}
+	}
+	r.Backends = kept
+}
+
+func (r *References) setHealthCheck(hc HealthCheck) {
+	for i := range r.HealthChecks {
+		if r.HealthChecks[i].Name == hc.Name {
+			r.HealthChecks[i] = hc
+			return
+		}
+	}
+	r.HealthChecks = append(r.HealthChecks, hc)
+}
+
+func (r *References) removeHealthCheck(name string) {
+	kept := r.HealthChecks[:0]
+	for _, hc := range r.HealthChecks {
+		if hc.Name != name {
+			kept = append(kept, hc)
+		}
+	}
+	r.HealthChecks = kept
+}
+
+func (r *References) setDirector(d Director) {
+	for i := range r.Directors {
+		if r.Directors[i].Name == d.Name {
+			r.Directors[i] = d
+			return
+		}
+	}
+	r.Directors = append(r.Directors, d)
+}
+
+func (r *References) removeDirector(name string) {
+	kept := r.Directors[:0]
+	for _, d := range r.Directors {
+		if d.Name != name {
+			kept = append(kept, d)
+		}
+	}
+	r.Directors = kept
+}
+
+func (r *References) setCondition(c Condition) {
+	for i := range r.Conditions {
+		if r.Conditions[i].Name == c.Name {
+			r.Conditions[i] = c
+			return
+		}
+	}
+	r.Conditions = append(r.Conditions, c)
+}
+
+func (r *References) removeCondition(name string) {
+	kept := r.Conditions[:0]
+	for _, c := range r.Conditions {
+		if c.Name != name {
+			kept = append(kept, c)
+		}
+	}
+	r.Conditions = kept
+}
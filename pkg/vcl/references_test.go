@@ -0,0 +1,162 @@
+package vcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+func TestReferencesCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		refs     References
+		problems []Problem
+	}{
+		{
+			name: "every reference exists",
+			refs: References{
+				Backends:     []Backend{{Name: "origin", HealthCheck: "check", RequestCondition: "api"}},
+				HealthChecks: []HealthCheck{{Name: "check"}},
+				Directors:    []Director{{Name: "pool", Backends: []string{"origin"}}},
+				Conditions:   []Condition{{Name: "api", Type: ConditionRequest}},
+			},
+		},
+		{
+			name: "missing healthcheck",
+			refs: References{
+				Backends: []Backend{{Name: "origin", HealthCheck: "check"}},
+			},
+			problems: []Problem{
+				{Object: "backend 'origin'", Message: "references the healthcheck 'check', which doesn't exist"},
+			},
+		},
+		{
+			name: "unreferenced healthcheck",
+			refs: References{
+				Backends:     []Backend{{Name: "origin"}},
+				HealthChecks: []HealthCheck{{Name: "check"}},
+			},
+			problems: []Problem{
+				{Object: "healthcheck 'check'", Message: "isn't referenced by any backend", Warning: true},
+			},
+		},
+		{
+			name: "missing request condition",
+			refs: References{
+				Backends: []Backend{{Name: "origin", RequestCondition: "api"}},
+			},
+			problems: []Problem{
+				{Object: "backend 'origin'", Message: "references the request condition 'api', which doesn't exist"},
+			},
+		},
+		{
+			name: "condition of the wrong type",
+			refs: References{
+				Backends:   []Backend{{Name: "origin", RequestCondition: "api"}},
+				Conditions: []Condition{{Name: "api", Type: "CACHE"}},
+			},
+			problems: []Problem{
+				{Object: "backend 'origin'", Message: "references the condition 'api' as a request condition, but it's a CACHE condition"},
+			},
+		},
+		{
+			name: "unreferenced request condition",
+			refs: References{
+				Backends:   []Backend{{Name: "origin"}},
+				Conditions: []Condition{{Name: "api", Type: ConditionRequest}},
+			},
+			problems: []Problem{
+				{Object: "request condition 'api'", Message: "isn't referenced by any backend", Warning: true},
+			},
+		},
+		{
+			name: "other condition types aren't referenced by backends",
+			refs: References{
+				Conditions: []Condition{{Name: "cacheable", Type: "CACHE"}, {Name: "errors", Type: "RESPONSE"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.refs.Check(); !reflect.DeepEqual(got, tt.problems) {
+				t.Errorf("got problems %+v, want %+v", got, tt.problems)
+			}
+		})
+	}
+}
+
+func TestCheckReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(*References)
+		warnings []Problem
+		errs     []Problem
+	}{
+		{
+			name: "the problems the version already had are ignored",
+			change: func(r *References) {
+				r.setBackend(Backend{Name: "origin", Address: "new.example.com", HealthCheck: "check"})
+			},
+		},
+		{
+			name: "new error",
+			change: func(r *References) {
+				r.setBackend(Backend{Name: "api", HealthCheck: "missing"})
+			},
+			errs: []Problem{
+				{Object: "backend 'api'", Message: "references the healthcheck 'missing', which doesn't exist"},
+			},
+		},
+		{
+			name: "new warning",
+			change: func(r *References) {
+				r.removeBackend("origin")
+			},
+			warnings: []Problem{
+				{Object: "healthcheck 'check'", Message: "isn't referenced by any backend", Warning: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newService(t, nil)
+
+			// the version already has an error (a director referencing a
+			// backend that doesn't exist) and a warning (an unused healthcheck)
+			err := f.SetBackend("svc", 1, fastly.Backend{Name: "origin", Address: "origin.example.com", HealthCheck: "check"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"check", "unused"} {
+				if err := f.SetHealthCheck("svc", 1, fastly.HealthCheck{Name: name}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := f.SetDirector("svc", 1, fastly.Director{Name: "pool", Type: fastly.DirectorTypeRandom}, []string{"gone"}); err != nil {
+				t.Fatal(err)
+			}
+
+			warnings, err := checkReferences("svc", 1, f, tt.change)
+			if tt.errs != nil {
+				refErr, ok := err.(ReferenceError)
+				if !ok {
+					t.Fatalf("got error %v, want a ReferenceError", err)
+				}
+				if !reflect.DeepEqual(refErr.Problems, tt.errs) {
+					t.Errorf("got errors %+v, want %+v", refErr.Problems, tt.errs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("got warnings %+v, want %+v", warnings, tt.warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// ErrNoActiveVersion is returned when the service has no active version to
+// roll back from
+var ErrNoActiveVersion = errors.New("the service has no active version")
+
+// ErrNoPreviousVersion is returned when no previously active version could be
+// found to roll back to, so the version has to be specified
+var ErrNoPreviousVersion = errors.New("no previously deployed version was found")
+
+// RollbackOptions defines the settings for re-activating a previous version
+type RollbackOptions struct {
+	Service string
+
+	// To is the version to roll back to
+	// when zero the most recent previously active version is used
+	To int
+}
+
+// RollbackPlan describes the version that would be re-activated
+type RollbackPlan struct {
+	Service string
+	Active  int
+	Target  int
+
+	// Files are the VCL differences going from the active version to the
+	// target version
+	Files []VersionDiff
+}
+
+// PlanRollback finds the currently active version and the version to roll
+// back to, without making any changes to the service
+//
+// The previously active version is the most recent (i.e. highest numbered)
+// deployed version below the active one, a version that was only locked was
+// never activated so isn't rolled back to
+func PlanRollback(ctx context.Context, client api.Client, opts RollbackOptions) (*RollbackPlan, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	versions, err := api.GetSortedVersions(opts.Service, client)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RollbackPlan{
+		Service: opts.Service,
+		Target:  opts.To,
+	}
+
+	for _, v := range versions {
+		if v.Active {
+			plan.Active = v.Number
+		}
+	}
+
+	if plan.Active == 0 {
+		return nil, ErrNoActiveVersion
+	}
+
+	if plan.Target == 0 {
+		for _, v := range versions {
+			if v.Deployed && v.Number < plan.Active {
+				plan.Target = v.Number
+			}
+		}
+	}
+
+	if plan.Target == 0 {
+		return nil, ErrNoPreviousVersion
+	}
+
+	if plan.Target == plan.Active {
+		return nil, fmt.Errorf("version %d is already active", plan.Target)
+	}
+
+	plan.Files, err = CompareVersions(ctx, client, opts.Service, plan.Active, plan.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// Rollback activates the target version of the plan, in the same way as
+// Deploy the version has to be valid and confirm has to accept the plan of
+// its activation (a nil confirm accepts every plan)
+func Rollback(ctx context.Context, client api.Client, plan *RollbackPlan, confirm ConfirmActivation) (*ActivateResult, error) {
+	activate, err := PlanActivate(ctx, client, VersionOptions{
+		Service: plan.Service,
+		Version: plan.Target,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return activatePlan(ctx, client, activate, confirm)
+}
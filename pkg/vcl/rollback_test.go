@@ -0,0 +1,173 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/api/apitest"
+)
+
+func TestPlanRollback(t *testing.T) {
+	activate := func(f *apitest.Fake, version int) {
+		if _, err := f.ActivateVersion(&fastly.ActivateVersionInput{Service: "svc", Version: version}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lock := func(f *apitest.Fake, version int) {
+		if _, err := f.LockVersion(&fastly.LockVersionInput{Service: "svc", Version: version}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clone := func(f *apitest.Fake) {
+		if _, err := f.CloneVersion(&fastly.CloneVersionInput{Service: "svc", Version: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		setup  func(*apitest.Fake)
+		to     int
+		active int
+		target int
+		err    error
+	}{
+		{
+			name: "previously deployed version",
+			setup: func(f *apitest.Fake) {
+				activate(f, 1)
+				clone(f)
+				activate(f, 2)
+			},
+			active: 2,
+			target: 1,
+		},
+		{
+			name: "skips the versions that were only locked",
+			setup: func(f *apitest.Fake) {
+				activate(f, 1)
+				clone(f)
+				lock(f, 2)
+				clone(f)
+				activate(f, 3)
+			},
+			active: 3,
+			target: 1,
+		},
+		{
+			name: "no previously deployed version",
+			setup: func(f *apitest.Fake) {
+				lock(f, 1)
+				clone(f)
+				activate(f, 2)
+			},
+			err: ErrNoPreviousVersion,
+		},
+		{
+			name: "specified version",
+			setup: func(f *apitest.Fake) {
+				lock(f, 1)
+				clone(f)
+				activate(f, 2)
+			},
+			to:     1,
+			active: 2,
+			target: 1,
+		},
+		{
+			name: "no active version",
+			err:  ErrNoActiveVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newService(t, map[string]string{"main": "sub vcl_recv {\n}\n"})
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			plan, err := PlanRollback(context.Background(), f, RollbackOptions{Service: "svc", To: tt.to})
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if plan.Active != tt.active || plan.Target != tt.target {
+				t.Errorf("rolls back from %d to %d, want %d to %d", plan.Active, plan.Target, tt.active, tt.target)
+			}
+		})
+	}
+}
+
+func TestRollback(t *testing.T) {
+	errDeclined := errors.New("declined")
+
+	tests := []struct {
+		name    string
+		invalid bool
+		confirm error
+		active  int
+		err     error
+	}{
+		{name: "confirmed", active: 1},
+		{name: "not confirmed", confirm: errDeclined, active: 2, err: errDeclined},
+		{name: "invalid version", invalid: true, active: 2, err: ErrInvalidVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newService(t, map[string]string{"main": "sub vcl_recv {\n}\n"})
+			if _, err := f.ActivateVersion(&fastly.ActivateVersionInput{Service: "svc", Version: 1}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.CloneVersion(&fastly.CloneVersionInput{Service: "svc", Version: 1}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.ActivateVersion(&fastly.ActivateVersionInput{Service: "svc", Version: 2}); err != nil {
+				t.Fatal(err)
+			}
+
+			// a second main VCL fails validation
+			if tt.invalid {
+				if err := f.SetVCL("svc", 1, "other", "sub vcl_fetch {\n}\n", true); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			plan, err := PlanRollback(context.Background(), f, RollbackOptions{Service: "svc"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var confirmed *ActivatePlan
+			_, err = Rollback(context.Background(), f, plan, func(p *ActivatePlan) error {
+				confirmed = p
+				return tt.confirm
+			})
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+
+			switch {
+			case tt.invalid && confirmed != nil:
+				t.Error("an invalid version was planned for confirmation")
+			case !tt.invalid && (confirmed == nil || confirmed.Active != 2 || confirmed.Version != 1):
+				t.Errorf("confirmed the plan %+v, want the activation of version 1 in place of 2", confirmed)
+			}
+
+			active, err := api.GetActiveVersion("svc", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if active != tt.active {
+				t.Errorf("version %d is active, want %d", active, tt.active)
+			}
+		})
+	}
+}
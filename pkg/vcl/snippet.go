@@ -0,0 +1,746 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/diff"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// SnippetsDir is the directory (within the VCL directory) holding the local
+// snippets, each one is stored as <type>/<priority>_<name>.vcl or, for a
+// dynamic snippet, as dynamic/<type>/<priority>_<name>.vcl
+//
+// the files within it are never treated as whole VCL files
+const SnippetsDir = "snippets"
+
+// DefaultSnippetPriority is used when a snippet file name has no priority
+const DefaultSnippetPriority = 100
+
+// dynamicSnippetsDir is the directory (within SnippetsDir) holding the local
+// dynamic snippets
+const dynamicSnippetsDir = "dynamic"
+
+// SnippetTypes are the locations a snippet can be placed in, either a VCL
+// subroutine or none (for a snippet that is included manually)
+var SnippetTypes = []string{"init", "recv", "hash", "hit", "miss", "pass", "fetch", "error", "deliver", "log", "none"}
+
+// ErrMissingSnippetName is returned when an operation requires a snippet name
+var ErrMissingSnippetName = errors.New("you must provide a snippet name")
+
+// ErrSnippetLayout is returned for a file in the SnippetsDir that doesn't
+// follow its layout
+var ErrSnippetLayout = errors.New("snippets must be stored as <type>/<priority>_<name>.vcl or dynamic/<type>/<priority>_<name>.vcl")
+
+// ErrDynamicVersion is returned when a dynamic upload is asked to clone a
+// version (dynamic snippets are updated without a new version)
+var ErrDynamicVersion = errors.New("dynamic snippets are updated in place, so please do not provide a clone version or the latest flag")
+
+// Snippet is a VCL snippet found either locally (Path is set) or in a remote
+// service version (ID is set)
+type Snippet struct {
+	Name     string
+	Type     string
+	Priority int
+	Dynamic  bool
+	Content  string
+
+	ID   string
+	Path string
+}
+
+// SnippetOptions identifies a snippet within a remote service version
+type SnippetOptions struct {
+	Service string
+	Name    string
+
+	// Version the snippet is in (zero means the latest version)
+	Version int
+}
+
+// SnippetsResult contains the snippets found in the remote service version
+type SnippetsResult struct {
+	Service  string
+	Version  int
+	Snippets []Snippet
+}
+
+// SnippetResult contains a single snippet of the remote service version
+type SnippetResult struct {
+	Service string
+	Version int
+	Snippet
+}
+
+// SnippetDiffOptions defines the settings for comparing the local snippets
+// against a remote service version
+type SnippetDiffOptions struct {
+	Service   string
+	Directory string
+
+	// Version to compare against (zero means the latest version)
+	Version int
+
+	// Context is the number of unchanged lines rendered around each change
+	Context int
+}
+
+// SnippetDiffResult contains the comparison of every local and remote snippet
+type SnippetDiffResult struct {
+	Service  string
+	Version  int
+	Snippets []SnippetDiff
+}
+
+// Differences returns the number of snippets that differ
+func (r SnippetDiffResult) Differences() int {
+	count := 0
+	for _, sd := range r.Snippets {
+		if !sd.Equal() {
+			count++
+		}
+	}
+	return count
+}
+
+// SnippetDiff is the comparison of the local and remote snippet of a name
+// Local is nil when the snippet only exists remotely, and Remote is nil when
+// it only exists locally
+type SnippetDiff struct {
+	Name   string
+	Local  *Snippet
+	Remote *Snippet
+
+	// Changes describes the settings (type, priority and dynamic) that differ
+	Changes []string
+	Result  diff.Result
+}
+
+// Equal reports whether the snippet is the same locally and remotely
+func (sd SnippetDiff) Equal() bool {
+	return sd.Local != nil && sd.Remote != nil && len(sd.Changes) == 0 && sd.Result.Equal()
+}
+
+// Unified renders the content comparison in the unified diff format
+func (sd SnippetDiff) Unified(version int) string {
+	local := "/dev/null"
+	if sd.Local != nil {
+		local = sd.Local.Path
+	}
+	return sd.Result.Unified(fmt.Sprintf("%s (version %d)", sd.Name, version), local)
+}
+
+// SnippetUploadOptions defines the settings for uploading the local snippets
+// the version is selected in the same way as UploadOptions
+type SnippetUploadOptions struct {
+	Service   string
+	Directory string
+
+	Clone   int
+	Version int
+	Latest  bool
+
+	// Comment is given to the version created by cloning
+	Comment string
+
+	// Dynamic uploads the content of the dynamic snippets straight to the
+	// service (without a new version), any other snippet is left alone
+	// the snippets are looked up in Version, or the active version
+	Dynamic bool
+}
+
+// SnippetPlan describes the changes uploading the local snippets would make
+// computing a plan never makes any mutating API calls
+type SnippetPlan struct {
+	Service string
+
+	// Version is the version the plan was computed against
+	// when Clone is true the changes are applied to a clone of it, which is
+	// given the Comment
+	Version int
+	Clone   bool
+	Comment string
+	Dynamic bool
+
+	Create    []PlannedSnippet
+	Update    []PlannedSnippet
+	Unchanged []PlannedSnippet
+
+	// Skipped are the local snippets the upload can't change
+	Skipped []PlannedSnippet
+}
+
+// Empty reports whether the plan has no changes to apply
+func (p SnippetPlan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Update) == 0
+}
+
+// PlannedSnippet is a single local snippet within a SnippetPlan
+type PlannedSnippet struct {
+	Snippet
+
+	// RemoteID identifies the remote snippet (empty when it doesn't exist)
+	RemoteID string
+
+	// Changes describes the settings that differ from the remote snippet
+	// and Diff is the change from the remote content to the local content
+	Changes []string
+	Diff    diff.Result
+
+	// Reason explains why the snippet was skipped
+	Reason string
+}
+
+// SnippetUploadResult contains the outcome of applying a SnippetPlan
+type SnippetUploadResult struct {
+	Service string
+	Version int
+	Dynamic bool
+
+	// ClonedFrom is the version that was cloned (zero if no clone happened)
+	// and Comment is the comment given to the clone
+	ClonedFrom int
+	Comment    string
+
+	Snippets []FileResult
+
+	// Failed summarises the snippets that didn't upload
+	Failed FileErrors
+}
+
+// Err reports whether any of the snippets failed to upload
+func (r SnippetUploadResult) Err() error {
+	return r.Failed.Err()
+}
+
+// ListSnippets returns every snippet in the remote service version
+func ListSnippets(ctx context.Context, client api.Client, opts ListOptions) (*SnippetsResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	snippets, err := remoteSnippets(opts.Service, selectedVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SnippetsResult{
+		Service:  opts.Service,
+		Version:  selectedVersion,
+		Snippets: snippets,
+	}, nil
+}
+
+// GetSnippet returns the named snippet from the remote service version
+// the content of a dynamic snippet is its current (live) content
+func GetSnippet(ctx context.Context, client api.Client, opts SnippetOptions) (*SnippetResult, error) {
+	if opts.Name == "" {
+		return nil, ErrMissingSnippetName
+	}
+
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := client.GetSnippet(&fastly.GetSnippetInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the snippet '%s' in version %d: %s", opts.Name, selectedVersion, err)
+	}
+
+	snippet, err := remoteSnippet(opts.Service, s, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SnippetResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Snippet: snippet,
+	}, nil
+}
+
+// DeleteSnippet removes the named snippet from the remote service version
+// the returned result describes the snippet that was deleted
+func DeleteSnippet(ctx context.Context, client api.Client, opts SnippetOptions) (*SnippetResult, error) {
+	result, err := GetSnippet(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DeleteSnippet(&fastly.DeleteSnippetInput{
+		Service: opts.Service,
+		Version: result.Version,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete the snippet '%s' from version %d: %s", opts.Name, result.Version, err)
+	}
+
+	return result, nil
+}
+
+// DiffSnippets compares the local snippets against the remote service
+// version, including the remote snippets that don't exist locally
+func DiffSnippets(ctx context.Context, client api.Client, opts SnippetDiffOptions) (*SnippetDiffResult, error) {
+	if opts.Context < 0 {
+		return nil, diff.ErrNegativeContext
+	}
+
+	local, err := LocalSnippets(opts.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := remoteSnippets(opts.Service, selectedVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := map[string]*SnippetDiff{}
+	for i := range remote {
+		diffs[remote[i].Name] = &SnippetDiff{Name: remote[i].Name, Remote: &remote[i]}
+	}
+	for i := range local {
+		sd, ok := diffs[local[i].Name]
+		if !ok {
+			sd = &SnippetDiff{Name: local[i].Name}
+			diffs[local[i].Name] = sd
+		}
+		sd.Local = &local[i]
+	}
+
+	result := &SnippetDiffResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+	}
+
+	opt := diff.VCLOptions(opts.Context)
+	for _, sd := range diffs {
+		var remoteContent, localContent string
+		if sd.Remote != nil {
+			remoteContent = sd.Remote.Content
+		}
+		if sd.Local != nil {
+			localContent = sd.Local.Content
+		}
+		if sd.Remote != nil && sd.Local != nil {
+			sd.Changes = snippetChanges(*sd.Remote, *sd.Local)
+		}
+
+		sd.Result = diff.Strings(remoteContent, localContent, opt)
+		result.Snippets = append(result.Snippets, *sd)
+	}
+
+	sort.Slice(result.Snippets, func(i, j int) bool {
+		return result.Snippets[i].Name < result.Snippets[j].Name
+	})
+
+	return result, nil
+}
+
+// PlanSnippets describes which snippets UploadSnippets would create or
+// update, and which version would be cloned, without making any changes
+func PlanSnippets(ctx context.Context, client api.Client, opts SnippetUploadOptions) (*SnippetPlan, error) {
+	local, err := LocalSnippets(opts.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &TargetVersion{Service: opts.Service}
+	if opts.Dynamic {
+		target.Version, err = dynamicVersion(ctx, opts, client)
+	} else {
+		target, err = PlanTarget(ctx, client, Target{
+			Service: opts.Service,
+			Clone:   opts.Clone,
+			Version: opts.Version,
+			Latest:  opts.Latest,
+			Comment: opts.Comment,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := remoteSnippets(opts.Service, target.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]Snippet{}
+	for _, snippet := range remote {
+		byName[snippet.Name] = snippet
+	}
+
+	plan := &SnippetPlan{
+		Service: opts.Service,
+		Version: target.Version,
+		Clone:   target.Clone,
+		Comment: target.Comment,
+		Dynamic: opts.Dynamic,
+	}
+
+	for _, snippet := range local {
+		r, exists := byName[snippet.Name]
+
+		planned := PlannedSnippet{
+			Snippet:  snippet,
+			RemoteID: r.ID,
+			Diff:     diff.Strings(r.Content, snippet.Content, planDiffOptions),
+		}
+
+		if opts.Dynamic {
+			plan.addDynamic(planned, r, exists)
+			continue
+		}
+
+		if exists {
+			planned.Changes = snippetChanges(r, snippet)
+		}
+
+		switch {
+		case !exists:
+			plan.Create = append(plan.Create, planned)
+		case len(planned.Changes) > 0:
+			plan.Update = append(plan.Update, planned)
+		case r.Content == snippet.Content:
+			plan.Unchanged = append(plan.Unchanged, planned)
+		case r.Dynamic:
+			planned.Reason = "the content of a dynamic snippet is uploaded with -dynamic"
+			plan.Skipped = append(plan.Skipped, planned)
+		default:
+			plan.Update = append(plan.Update, planned)
+		}
+	}
+
+	return plan, nil
+}
+
+// addDynamic plans a dynamic upload of the local snippet, only the content
+// of an existing dynamic snippet can be changed
+func (p *SnippetPlan) addDynamic(planned PlannedSnippet, remote Snippet, exists bool) {
+	if !planned.Dynamic {
+		return
+	}
+
+	switch {
+	case !exists:
+		planned.Reason = fmt.Sprintf("not found in version %d (upload without -dynamic to create it)", p.Version)
+		p.Skipped = append(p.Skipped, planned)
+	case !remote.Dynamic:
+		planned.Reason = fmt.Sprintf("not dynamic in version %d (upload without -dynamic to change it)", p.Version)
+		p.Skipped = append(p.Skipped, planned)
+	case remote.Content == planned.Content:
+		p.Unchanged = append(p.Unchanged, planned)
+	default:
+		p.Update = append(p.Update, planned)
+	}
+}
+
+// ApplySnippets makes the changes described by the plan
+// cloning the planned version first when required
+//
+// a snippet's type, priority and dynamic setting can't be changed, so the
+// snippet is deleted and then created again
+func ApplySnippets(ctx context.Context, client api.Client, plan *SnippetPlan) (*SnippetUploadResult, error) {
+	target, err := acquireTarget(ctx, client, TargetVersion{
+		Service: plan.Service,
+		Version: plan.Version,
+		Clone:   plan.Clone,
+		Comment: plan.Comment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SnippetUploadResult{
+		Service:    plan.Service,
+		Version:    target.Version,
+		ClonedFrom: target.ClonedFrom,
+		Comment:    target.Comment,
+		Dynamic:    plan.Dynamic,
+	}
+
+	for _, planned := range plan.Create {
+		err := createSnippet(plan.Service, result.Version, planned.Snippet, client)
+		result.add(planned, true, err)
+	}
+
+	for _, planned := range plan.Update {
+		var err error
+
+		if plan.Dynamic {
+			_, err = client.UpdateDynamicSnippet(&fastly.UpdateDynamicSnippetInput{
+				Service: plan.Service,
+				ID:      planned.RemoteID,
+				Content: planned.Content,
+			})
+		} else {
+			err = replaceSnippet(plan.Service, result.Version, planned.Snippet, client)
+		}
+
+		result.add(planned, false, err)
+	}
+
+	return result, nil
+}
+
+// UploadSnippets creates or updates the remote snippets to match the local
+// snippets (remote snippets that don't exist locally are left alone)
+func UploadSnippets(ctx context.Context, client api.Client, opts SnippetUploadOptions) (*SnippetUploadResult, error) {
+	plan, err := PlanSnippets(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ApplySnippets(ctx, client, plan)
+}
+
+func (r *SnippetUploadResult) add(planned PlannedSnippet, created bool, err error) {
+	r.Snippets = append(r.Snippets, FileResult{
+		Name:    planned.Name,
+		Path:    planned.Path,
+		Created: created,
+		Err:     err,
+	})
+
+	if err != nil {
+		r.Failed = append(r.Failed, FileError{Name: planned.Name, Path: planned.Path, Err: err})
+	}
+}
+
+// LocalSnippets returns the snippets found within the SnippetsDir of the VCL
+// directory sorted by name (there are none when SnippetsDir doesn't exist)
+func LocalSnippets(dir string) ([]Snippet, error) {
+	root := filepath.Join(dir, SnippetsDir)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var snippets []Snippet
+	paths := map[string]string{}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".vcl" {
+			return nil
+		}
+
+		snippet, err := parseSnippetPath(root, path)
+		if err != nil {
+			return err
+		}
+
+		if other, ok := paths[snippet.Name]; ok {
+			return fmt.Errorf("the snippet '%s' is defined by both %s and %s", snippet.Name, other, path)
+		}
+		paths[snippet.Name] = path
+
+		snippet.Content, err = getLocalVCL(path)
+		if err != nil {
+			return err
+		}
+
+		snippets = append(snippets, snippet)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].Name < snippets[j].Name
+	})
+
+	return snippets, nil
+}
+
+// parseSnippetPath reads the type, priority, name and dynamic setting of the
+// snippet from its path within the SnippetsDir
+func parseSnippetPath(root, path string) (Snippet, error) {
+	snippet := Snippet{
+		Path:     path,
+		Priority: DefaultSnippetPriority,
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return snippet, err
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) == 3 && parts[0] == dynamicSnippetsDir {
+		snippet.Dynamic = true
+		parts = parts[1:]
+	}
+
+	if len(parts) != 2 {
+		return snippet, fmt.Errorf("%s: %s", path, ErrSnippetLayout)
+	}
+
+	if !validSnippetType(parts[0]) {
+		return snippet, fmt.Errorf("%s: unknown snippet type '%s' (try: %s)", path, parts[0], strings.Join(SnippetTypes, ", "))
+	}
+	snippet.Type = parts[0]
+
+	name := strings.TrimSuffix(parts[1], ".vcl")
+	if i := strings.Index(name, "_"); i > 0 {
+		if priority, err := strconv.Atoi(name[:i]); err == nil {
+			snippet.Priority = priority
+			name = name[i+1:]
+		}
+	}
+
+	if name == "" {
+		return snippet, fmt.Errorf("%s: %s", path, ErrSnippetLayout)
+	}
+	snippet.Name = name
+
+	return snippet, nil
+}
+
+func validSnippetType(t string) bool {
+	for _, valid := range SnippetTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// snippetChanges describes the settings of the local snippet that differ
+// from the remote snippet
+func snippetChanges(remote, local Snippet) []string {
+	var changes []string
+
+	if remote.Type != local.Type {
+		changes = append(changes, fmt.Sprintf("type: %s -> %s", remote.Type, local.Type))
+	}
+	if remote.Priority != local.Priority {
+		changes = append(changes, fmt.Sprintf("priority: %d -> %d", remote.Priority, local.Priority))
+	}
+	if remote.Dynamic != local.Dynamic {
+		changes = append(changes, fmt.Sprintf("dynamic: %t -> %t", remote.Dynamic, local.Dynamic))
+	}
+
+	return changes
+}
+
+// dynamicVersion returns the version the dynamic snippets are looked up in
+// (see: liveVersion)
+func dynamicVersion(ctx context.Context, opts SnippetUploadOptions, client api.Client) (int, error) {
+	if opts.Clone != 0 || opts.Latest {
+		return 0, ErrDynamicVersion
+	}
+
+	return liveVersion(ctx, opts.Service, opts.Version, client)
+}
+
+// remoteSnippets returns every snippet in the remote service version sorted
+// by name, along with the current content of each dynamic snippet
+func remoteSnippets(service string, version int, client api.Client) ([]Snippet, error) {
+	list, err := client.ListSnippets(&fastly.ListSnippetsInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve list of snippets for version %d: %s", version, err)
+	}
+
+	snippets := make([]Snippet, 0, len(list))
+	for _, s := range list {
+		snippet, err := remoteSnippet(service, s, client)
+		if err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, snippet)
+	}
+
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].Name < snippets[j].Name
+	})
+
+	return snippets, nil
+}
+
+// remoteSnippet converts the API snippet, the content of a dynamic snippet
+// is requested separately as it can change without a new version
+func remoteSnippet(service string, s *fastly.Snippet, client api.Client) (Snippet, error) {
+	snippet := Snippet{
+		Name:     s.Name,
+		Type:     string(s.Type),
+		Priority: s.Priority,
+		Dynamic:  s.Dynamic == 1,
+		Content:  s.Content,
+		ID:       s.ID,
+	}
+
+	if !snippet.Dynamic {
+		return snippet, nil
+	}
+
+	dynamic, err := client.GetDynamicSnippet(&fastly.GetDynamicSnippetInput{
+		Service: service,
+		ID:      s.ID,
+	})
+	if err != nil {
+		return snippet, fmt.Errorf("unable to retrieve the content of the dynamic snippet '%s': %s", s.Name, err)
+	}
+	snippet.Content = dynamic.Content
+
+	return snippet, nil
+}
+
+func createSnippet(service string, version int, snippet Snippet, client api.Client) error {
+	dynamic := 0
+	if snippet.Dynamic {
+		dynamic = 1
+	}
+
+	_, err := client.CreateSnippet(&fastly.CreateSnippetInput{
+		Service:  service,
+		Version:  version,
+		Name:     snippet.Name,
+		Priority: snippet.Priority,
+		Dynamic:  dynamic,
+		Content:  snippet.Content,
+		Type:     fastly.SnippetType(snippet.Type),
+	})
+	return err
+}
+
+// replaceSnippet deletes the remote snippet and then creates it again from
+// the local snippet
+func replaceSnippet(service string, version int, snippet Snippet, client api.Client) error {
+	err := client.DeleteSnippet(&fastly.DeleteSnippetInput{
+		Service: service,
+		Version: version,
+		Name:    snippet.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	return createSnippet(service, version, snippet, client)
+}
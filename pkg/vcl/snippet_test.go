@@ -0,0 +1,332 @@
+package vcl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api/apitest"
+)
+
+// snippetService returns a fake whose first version holds the snippets
+func snippetService(t *testing.T, snippets ...fastly.Snippet) *apitest.Fake {
+	f := newService(t, nil)
+	for _, s := range snippets {
+		if err := f.SetSnippet("svc", 1, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+// snippetSummaries describes each snippet as name:type:priority:content
+// (prefixed with dynamic: for a dynamic snippet)
+func snippetSummaries(t *testing.T, f *apitest.Fake, version int) []string {
+	snippets, err := remoteSnippets("svc", version, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var summaries []string
+	for _, s := range snippets {
+		summary := strings.Join([]string{s.Name, s.Type, formatUint(uint(s.Priority)), s.Content}, ":")
+		if s.Dynamic {
+			summary = "dynamic:" + summary
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+func plannedNames(planned []PlannedSnippet) []string {
+	var names []string
+	for _, p := range planned {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestLocalSnippets(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		snippets []Snippet
+		err      string
+	}{
+		{
+			name: "static and dynamic snippets",
+			files: map[string]string{
+				"snippets/recv/10_auth.vcl":           "auth",
+				"snippets/deliver/headers.vcl":        "headers",
+				"snippets/dynamic/recv/blocklist.vcl": "blocklist",
+				"snippets/recv/README.md":             "ignored",
+				"main.vcl":                            "not a snippet",
+			},
+			snippets: []Snippet{
+				{Name: "auth", Type: "recv", Priority: 10, Content: "auth"},
+				{Name: "blocklist", Type: "recv", Priority: DefaultSnippetPriority, Dynamic: true, Content: "blocklist"},
+				{Name: "headers", Type: "deliver", Priority: DefaultSnippetPriority, Content: "headers"},
+			},
+		},
+		{
+			name:  "unknown type",
+			files: map[string]string{"snippets/reqv/auth.vcl": "auth"},
+			err:   "unknown snippet type 'reqv'",
+		},
+		{
+			name:  "not within a type directory",
+			files: map[string]string{"snippets/auth.vcl": "auth"},
+			err:   ErrSnippetLayout.Error(),
+		},
+		{
+			name: "the same name twice",
+			files: map[string]string{
+				"snippets/recv/10_auth.vcl":  "auth",
+				"snippets/fetch/20_auth.vcl": "auth",
+			},
+			err: "the snippet 'auth' is defined by both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeDir(t, tt.files)
+			defer os.RemoveAll(dir)
+
+			snippets, err := LocalSnippets(dir)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := range snippets {
+				snippets[i].Path = ""
+			}
+			if !reflect.DeepEqual(snippets, tt.snippets) {
+				t.Errorf("got %+v, want %+v", snippets, tt.snippets)
+			}
+		})
+	}
+}
+
+func TestUploadSnippets(t *testing.T) {
+	f := snippetService(t,
+		fastly.Snippet{Name: "auth", Type: fastly.SnippetTypeRecv, Priority: 10, Content: "old auth"},
+		fastly.Snippet{Name: "headers", Type: fastly.SnippetTypeDeliver, Priority: 100, Content: "headers"},
+		fastly.Snippet{Name: "moved", Type: fastly.SnippetTypeRecv, Priority: 100, Content: "moved"},
+		fastly.Snippet{Name: "blocklist", Type: fastly.SnippetTypeRecv, Priority: 100, Dynamic: 1, Content: "old blocklist"},
+		fastly.Snippet{Name: "remote-only", Type: fastly.SnippetTypeLog, Priority: 100, Content: "remote"},
+	)
+
+	dir := writeDir(t, map[string]string{
+		"snippets/recv/10_auth.vcl":           "new auth",
+		"snippets/deliver/headers.vcl":        "headers",
+		"snippets/fetch/moved.vcl":            "moved",
+		"snippets/dynamic/recv/blocklist.vcl": "new blocklist",
+		"snippets/miss/5_created.vcl":         "created",
+	})
+	defer os.RemoveAll(dir)
+
+	opts := SnippetUploadOptions{Service: "svc", Directory: dir}
+
+	plan, err := PlanSnippets(context.Background(), f, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !plan.Clone || plan.Version != 1 {
+		t.Errorf("got version %d (clone: %t), want a clone of version 1", plan.Version, plan.Clone)
+	}
+	if got, want := plannedNames(plan.Create), []string{"created"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got create %v, want %v", got, want)
+	}
+	if got, want := plannedNames(plan.Update), []string{"auth", "moved"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got update %v, want %v", got, want)
+	}
+	if got, want := plannedNames(plan.Unchanged), []string{"headers"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got unchanged %v, want %v", got, want)
+	}
+	// the content of a dynamic snippet is only uploaded with -dynamic
+	if got, want := plannedNames(plan.Skipped), []string{"blocklist"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got skipped %v, want %v", got, want)
+	}
+	if got, want := plan.Update[1].Changes, []string{"type: recv -> fetch"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got changes %v, want %v", got, want)
+	}
+
+	if calls := f.Calls("CloneVersion") + f.Calls("CreateSnippet") + f.Calls("DeleteSnippet"); calls != 0 {
+		t.Errorf("planning made %d changes, want none", calls)
+	}
+
+	result, err := ApplySnippets(context.Background(), f, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if result.Version != 2 || result.ClonedFrom != 1 {
+		t.Errorf("uploaded to version %d (cloned from %d), want version 2 cloned from 1", result.Version, result.ClonedFrom)
+	}
+
+	// remote snippets that don't exist locally are left alone
+	want := []string{
+		"auth:recv:10:new auth",
+		"dynamic:blocklist:recv:100:old blocklist",
+		"created:miss:5:created",
+		"headers:deliver:100:headers",
+		"moved:fetch:100:moved",
+		"remote-only:log:100:remote",
+	}
+	if got := snippetSummaries(t, f, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("got snippets %v, want %v", got, want)
+	}
+
+	// the cloned version was left as it was
+	if got := snippetSummaries(t, f, 1); got[0] != "auth:recv:10:old auth" {
+		t.Errorf("version 1 was changed: %v", got)
+	}
+}
+
+func TestUploadSnippetsDynamic(t *testing.T) {
+	f := snippetService(t,
+		fastly.Snippet{Name: "blocklist", Type: fastly.SnippetTypeRecv, Priority: 100, Dynamic: 1, Content: "old blocklist"},
+		fastly.Snippet{Name: "static", Type: fastly.SnippetTypeRecv, Priority: 100, Content: "static"},
+	)
+	if _, err := f.ActivateVersion(&fastly.ActivateVersionInput{Service: "svc", Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := writeDir(t, map[string]string{
+		"snippets/dynamic/recv/blocklist.vcl": "new blocklist",
+		"snippets/dynamic/recv/static.vcl":    "now dynamic",
+		"snippets/dynamic/recv/missing.vcl":   "missing",
+		"snippets/recv/other.vcl":             "not uploaded with -dynamic",
+	})
+	defer os.RemoveAll(dir)
+
+	opts := SnippetUploadOptions{Service: "svc", Directory: dir, Dynamic: true}
+
+	plan, err := PlanSnippets(context.Background(), f, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := plannedNames(plan.Update), []string{"blocklist"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got update %v, want %v", got, want)
+	}
+	if got, want := plannedNames(plan.Skipped), []string{"missing", "static"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got skipped %v, want %v", got, want)
+	}
+
+	result, err := ApplySnippets(context.Background(), f, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the active version is updated in place
+	if result.Version != 1 || f.Calls("CloneVersion") != 0 {
+		t.Errorf("uploaded to version %d after %d clone(s), want version 1 without a clone", result.Version, f.Calls("CloneVersion"))
+	}
+	if got, want := snippetSummaries(t, f, 1), []string{"dynamic:blocklist:recv:100:new blocklist", "static:recv:100:static"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got snippets %v, want %v", got, want)
+	}
+
+	// a dynamic upload never clones a version
+	opts.Latest = true
+	if _, err := PlanSnippets(context.Background(), f, opts); err != ErrDynamicVersion {
+		t.Errorf("got error %v, want %v", err, ErrDynamicVersion)
+	}
+}
+
+func TestDiffSnippets(t *testing.T) {
+	f := snippetService(t,
+		fastly.Snippet{Name: "auth", Type: fastly.SnippetTypeRecv, Priority: 10, Content: "old auth\n"},
+		fastly.Snippet{Name: "headers", Type: fastly.SnippetTypeDeliver, Priority: 100, Content: "headers\n"},
+		fastly.Snippet{Name: "moved", Type: fastly.SnippetTypeRecv, Priority: 100, Content: "moved\n"},
+		fastly.Snippet{Name: "remote-only", Type: fastly.SnippetTypeLog, Priority: 100, Content: "remote\n"},
+	)
+
+	dir := writeDir(t, map[string]string{
+		"snippets/recv/10_auth.vcl":    "new auth\n",
+		"snippets/deliver/headers.vcl": "headers\n",
+		"snippets/fetch/moved.vcl":     "moved\n",
+		"snippets/miss/local-only.vcl": "local\n",
+	})
+	defer os.RemoveAll(dir)
+
+	result, err := DiffSnippets(context.Background(), f, SnippetDiffOptions{Service: "svc", Directory: dir, Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	equal := map[string]bool{}
+	for _, sd := range result.Snippets {
+		equal[sd.Name] = sd.Equal()
+	}
+	want := map[string]bool{"auth": false, "headers": true, "local-only": false, "moved": false, "remote-only": false}
+	if !reflect.DeepEqual(equal, want) {
+		t.Errorf("got %v, want %v", equal, want)
+	}
+	if result.Differences() != 4 {
+		t.Errorf("got %d differences, want 4", result.Differences())
+	}
+
+	for _, sd := range result.Snippets {
+		switch sd.Name {
+		case "auth":
+			if sd.Result.Added != 1 || sd.Result.Removed != 1 {
+				t.Errorf("got %d added and %d removed lines for auth, want 1 and 1", sd.Result.Added, sd.Result.Removed)
+			}
+		case "moved":
+			if !reflect.DeepEqual(sd.Changes, []string{"type: recv -> fetch"}) || !sd.Result.Equal() {
+				t.Errorf("got changes %v for moved, want only its type to differ", sd.Changes)
+			}
+		case "remote-only":
+			if sd.Local != nil || sd.Remote == nil {
+				t.Errorf("got %+v, want only a remote snippet", sd)
+			}
+		case "local-only":
+			if sd.Local == nil || sd.Remote != nil || filepath.Base(sd.Local.Path) != "local-only.vcl" {
+				t.Errorf("got %+v, want only a local snippet", sd)
+			}
+		}
+	}
+}
+
+func TestDeleteSnippet(t *testing.T) {
+	f := snippetService(t,
+		fastly.Snippet{Name: "auth", Type: fastly.SnippetTypeRecv, Priority: 10, Content: "auth"},
+		fastly.Snippet{Name: "headers", Type: fastly.SnippetTypeDeliver, Priority: 100, Content: "headers"},
+	)
+
+	result, err := DeleteSnippet(context.Background(), f, SnippetOptions{Service: "svc", Name: "auth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Version != 1 || result.Content != "auth" {
+		t.Errorf("got %+v, want the deleted snippet from version 1", result)
+	}
+
+	if got, want := snippetSummaries(t, f, 1), []string{"headers:deliver:100:headers"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got snippets %v, want %v", got, want)
+	}
+
+	if _, err := DeleteSnippet(context.Background(), f, SnippetOptions{Service: "svc", Name: "auth"}); err == nil {
+		t.Error("expected an error deleting a snippet that doesn't exist")
+	}
+	if _, err := DeleteSnippet(context.Background(), f, SnippetOptions{Service: "svc"}); err != ErrMissingSnippetName {
+		t.Errorf("got error %v, want %v", err, ErrMissingSnippetName)
+	}
+}
@@ -0,0 +1,144 @@
+package vcl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// SyncOptions defines the settings for mirroring the local VCL directory
+// the version is selected in the same way as UploadOptions
+type SyncOptions struct {
+	UploadOptions
+
+	// DeleteMatch is a regex of the names of the remote files that can be
+	// deleted, remote files only have a name (no directory) so the Match and
+	// Skip path regexes can't select them, and when either is set no remote
+	// file is deleted unless its name matches DeleteMatch
+	DeleteMatch string
+}
+
+// SyncResult contains the outcome of applying a sync Plan
+type SyncResult struct {
+	Service string
+	Version int
+
+	// ClonedFrom is the version that was cloned (zero if no clone happened)
+	// and Comment is the comment given to the clone
+	ClonedFrom int
+	Comment    string
+
+	Files []FileResult
+
+	// Failed summarises the files that didn't upload or delete
+	Failed FileErrors
+
+	// Main is the VCL file designated as the main VCL (empty if none was)
+	Main    string
+	MainErr error
+}
+
+// Err reports whether any part of the sync failed
+// the error is a FileErrors summary when only some of the files failed
+func (r SyncResult) Err() error {
+	if r.MainErr != nil {
+		return append(r.Failed, FileError{Name: r.Main, Err: r.MainErr})
+	}
+	return r.Failed.Err()
+}
+
+// PlanSync compares the local VCL directory against the selected remote
+// version without making any changes to the service
+func PlanSync(ctx context.Context, client api.Client, opts SyncOptions) (*Plan, error) {
+	deletable, err := opts.deletable()
+	if err != nil {
+		return nil, err
+	}
+
+	return planFiles(ctx, client, opts.UploadOptions, deletable)
+}
+
+// deletable reports whether a remote file missing locally is deleted
+func (opts SyncOptions) deletable() (func(name string) bool, error) {
+	if opts.DeleteMatch == "" {
+		filtered := opts.Match != "" || opts.Skip != ""
+		return func(string) bool { return !filtered }, nil
+	}
+
+	match, err := regexp.Compile(opts.DeleteMatch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delete match regex: %s", err)
+	}
+	return match.MatchString, nil
+}
+
+// ApplySync makes the changes described by the plan
+// cloning the planned version first when required
+func ApplySync(ctx context.Context, client api.Client, plan *Plan) (*SyncResult, error) {
+	r := &run{
+		service:     plan.Service,
+		concurrency: plan.Concurrency,
+	}
+
+	target, err := acquireTarget(ctx, client, plan.target())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{
+		Service:    plan.Service,
+		Version:    target.Version,
+		ClonedFrom: target.ClonedFrom,
+		Comment:    target.Comment,
+	}
+
+	var paths []string
+	for _, file := range plan.Create {
+		paths = append(paths, file.Path)
+	}
+	for _, file := range plan.Update {
+		paths = append(paths, file.Path)
+	}
+
+	responses, failed := r.processPaths(ctx, result.Version, paths, uploadVCL, client)
+	result.Failed = failed
+
+	for _, vr := range responses {
+		result.Files = append(result.Files, FileResult{
+			Name:    vr.Name,
+			Path:    vr.Path,
+			Created: vr.Created,
+			Err:     vr.Err,
+		})
+	}
+
+	for _, file := range plan.Delete {
+		fr := FileResult{Name: file.Name, Deleted: true}
+
+		if err := ctx.Err(); err != nil {
+			fr.Err = err
+		} else {
+			fr.Err = client.DeleteVCL(&fastly.DeleteVCLInput{
+				Service: plan.Service,
+				Version: result.Version,
+				Name:    file.Name,
+			})
+		}
+
+		if fr.Err != nil {
+			result.Failed = append(result.Failed, FileError{Name: fr.Name, Err: fr.Err})
+		}
+
+		result.Files = append(result.Files, fr)
+	}
+
+	if plan.Main != "" {
+		result.Main = plan.Main
+		result.MainErr = setMain(ctx, plan.Service, result.Version, plan.Main, client)
+	}
+
+	return result, nil
+}
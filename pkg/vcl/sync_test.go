@@ -0,0 +1,93 @@
+package vcl
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestPlanSyncDeletes(t *testing.T) {
+	dir := writeDir(t, map[string]string{
+		"shared/shared_acl.vcl":      "acl office {\n}\n",
+		"shared/shared_backends.vcl": "backend origin {\n}\n",
+		"site/main.vcl":              "sub vcl_recv {\n}\n",
+	})
+	defer os.RemoveAll(dir)
+
+	remote := map[string]string{
+		"shared_acl":      "acl office {\n}\n",
+		"shared_backends": "backend origin {\n}\n",
+		"shared_old":      "sub vcl_deliver {\n}\n",
+		"main":            "sub vcl_recv {\n}\n",
+		"legacy":          "sub vcl_error {\n}\n",
+	}
+
+	tests := []struct {
+		name    string
+		opts    SyncOptions
+		deleted []string
+		err     bool
+	}{
+		{
+			name:    "no filters",
+			deleted: []string{"legacy", "shared_old"},
+		},
+		{
+			name: "directory match without a delete match",
+			opts: SyncOptions{UploadOptions: UploadOptions{Match: "/shared/"}},
+		},
+		{
+			name: "directory skip without a delete match",
+			opts: SyncOptions{UploadOptions: UploadOptions{Skip: "/site/"}},
+		},
+		{
+			name:    "directory match with a delete match",
+			opts:    SyncOptions{UploadOptions: UploadOptions{Match: "/shared/"}, DeleteMatch: "^shared_"},
+			deleted: []string{"shared_old"},
+		},
+		{
+			name:    "files outside of the filters are still local",
+			opts:    SyncOptions{UploadOptions: UploadOptions{Match: "/shared/"}, DeleteMatch: "."},
+			deleted: []string{"legacy", "shared_old"},
+		},
+		{
+			name:    "delete match without filters",
+			opts:    SyncOptions{DeleteMatch: "^legacy$"},
+			deleted: []string{"legacy"},
+		},
+		{
+			name: "invalid delete match",
+			opts: SyncOptions{DeleteMatch: "("},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newService(t, remote)
+
+			opts := tt.opts
+			opts.Service = "svc"
+			opts.Directory = dir
+
+			plan, err := PlanSync(context.Background(), f, opts)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var deleted []string
+			for _, file := range plan.Delete {
+				deleted = append(deleted, file.Name)
+			}
+			if !equalNames(deleted, tt.deleted) {
+				t.Errorf("planned to delete %v, want %v", deleted, tt.deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package vcl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// Target selects the version a change to the service's configuration (e.g. a
+// dictionary) is made to, in the same way as UploadOptions...
+//
+//	A. clone the specified version before making the change: `Clone`
+//	B. make the change to the specified version: `Version`
+//	C. make the change to the latest version: `Latest`
+//	D. clone the latest version available
+type Target struct {
+	Service string
+
+	Clone   int
+	Version int
+	Latest  bool
+
+	// Comment is given to the version created by cloning
+	Comment string
+}
+
+// TargetVersion is the version a Target selected
+//
+// When planned, Clone reports whether Version would be cloned first, and
+// once acquired, ClonedFrom is the version that was cloned (zero if none was)
+type TargetVersion struct {
+	Service string
+	Version int
+
+	Clone      bool
+	ClonedFrom int
+	Comment    string
+}
+
+// PlanTarget returns the version the change would be made to without making
+// any changes to the service
+func PlanTarget(ctx context.Context, client api.Client, t Target) (*TargetVersion, error) {
+	if t.Clone != 0 && t.Version != 0 {
+		return nil, ErrConflictingVersions
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tv := &TargetVersion{Service: t.Service}
+
+	switch {
+	// clone the specified version and make the change to that
+	case t.Clone != 0:
+		tv.Version = t.Clone
+		tv.Clone = true
+
+	// make the change to the specified version (it can't be activated)
+	case t.Version != 0:
+		if err := checkNotActive(t.Service, t.Version, client); err != nil {
+			return nil, err
+		}
+		tv.Version = t.Version
+
+	default:
+		latestVersion, err := api.GetLatestVCLVersion(t.Service, client)
+		if err != nil {
+			return nil, err
+		}
+		tv.Version = latestVersion
+
+		// make the change to the latest version (it can't be activated),
+		// otherwise clone the latest version and make the change to that
+		if t.Latest {
+			if err := checkNotActive(t.Service, latestVersion, client); err != nil {
+				return nil, err
+			}
+		} else {
+			tv.Clone = true
+		}
+	}
+
+	if tv.Clone {
+		tv.Comment = t.Comment
+	}
+
+	return tv, nil
+}
+
+// acquireTarget clones the planned version when required
+func acquireTarget(ctx context.Context, client api.Client, planned TargetVersion) (*TargetVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if !planned.Clone {
+		return &TargetVersion{Service: planned.Service, Version: planned.Version}, nil
+	}
+
+	clonedVersion, err := cloneFromVersion(planned.Service, planned.Version, planned.Comment, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TargetVersion{
+		Service:    planned.Service,
+		Version:    clonedVersion.Number,
+		ClonedFrom: planned.Version,
+		Comment:    planned.Comment,
+	}, nil
+}
+
+// liveVersion returns the version used to look up the objects that change
+// without a new version (e.g. dictionary items), which is the specified
+// version, or the active version (falling back to the latest version when
+// none is active)
+func liveVersion(ctx context.Context, service string, version int, client api.Client) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if version != 0 {
+		return version, nil
+	}
+
+	active, err := api.GetActiveVersion(service, client)
+	if err != nil {
+		return 0, err
+	}
+	if active != 0 {
+		return active, nil
+	}
+
+	return api.GetLatestVCLVersion(service, client)
+}
+
+// cloneFromVersion clones the version, then gives the clone the comment
+// (unless it's empty)
+func cloneFromVersion(service string, version int, comment string, client api.Client) (*fastly.Version, error) {
+	clonedVersion, err := client.CloneVersion(&fastly.CloneVersionInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if comment == "" {
+		return clonedVersion, nil
+	}
+
+	commentedVersion, err := client.UpdateVersion(&fastly.UpdateVersionInput{
+		Service: service,
+		Version: clonedVersion.Number,
+		Comment: comment,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to comment on version %d: %s", clonedVersion.Number, err)
+	}
+
+	return commentedVersion, nil
+}
+
+func checkNotActive(service string, version int, client api.Client) error {
+	getVersion, err := client.GetVersion(&fastly.GetVersionInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return err
+	}
+
+	if getVersion.Active {
+		return ErrActiveVersion
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package vcl
+
+import (
+	"context"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/sirupsen/logrus"
+)
+
+// UploadOptions defines the settings for uploading local VCL files
+//
+// The version uploaded to is selected by (in order of precedence)...
+//
+//	A. clone the specified version before uploading files: `Clone`
+//	B. upload files to the specified version: `Version`
+//	C. upload files to the latest version: `Latest`
+//	D. clone the latest version available
+type UploadOptions struct {
+	Service   string
+	Directory string
+	Match     string
+	Skip      string
+
+	Clone   int
+	Version int
+	Latest  bool
+
+	// Concurrency is the number of files uploaded at the same time
+	// (zero means DefaultConcurrency)
+	Concurrency int
+
+	// Main is the name of the VCL file to designate as the main VCL
+	// when empty the MainMarker file in the directory is used (if present)
+	Main string
+
+	// Comment is given to the version created by cloning (see: FormatComment)
+	// an empty comment leaves the version without one
+	Comment string
+}
+
+// UploadResult contains the outcome of uploading each local VCL file
+type UploadResult struct {
+	Service string
+	Version int
+
+	// ClonedFrom is the version that was cloned (zero if no clone happened)
+	// and Comment is the comment given to the clone
+	ClonedFrom int
+	Comment    string
+
+	Files []FileResult
+
+	// Failed summarises the files that didn't upload
+	Failed FileErrors
+
+	// Main is the VCL file designated as the main VCL (empty if none was)
+	Main    string
+	MainErr error
+}
+
+// Err reports whether any part of the upload failed
+// the error is a FileErrors summary when only some of the files failed
+func (r UploadResult) Err() error {
+	if r.MainErr != nil {
+		return append(r.Failed, FileError{Name: r.Main, Err: r.MainErr})
+	}
+	return r.Failed.Err()
+}
+
+// target selects the version uploaded to
+func (opts UploadOptions) target() Target {
+	return Target{
+		Service: opts.Service,
+		Clone:   opts.Clone,
+		Version: opts.Version,
+		Latest:  opts.Latest,
+		Comment: opts.Comment,
+	}
+}
+
+// FileResult is the outcome of uploading (or deleting) a single file
+type FileResult struct {
+	Name    string
+	Path    string
+	Created bool
+	Deleted bool
+	Err     error
+}
+
+// Upload takes specified list of files and creates new remote version
+// if upload fails it'll attempt uploading over existing remote version
+func Upload(ctx context.Context, client api.Client, opts UploadOptions) (*UploadResult, error) {
+	if opts.Clone != 0 && opts.Version != 0 {
+		return nil, ErrConflictingVersions
+	}
+
+	r, err := newRun(opts.Service, opts.Match, opts.Skip, opts.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	main, err := resolveMain(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	planned, err := PlanTarget(ctx, client, opts.target())
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := acquireTarget(ctx, client, *planned)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, failed, err := r.processFiles(ctx, target.Version, opts.Directory, uploadVCL, client)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UploadResult{
+		Service:    opts.Service,
+		Version:    target.Version,
+		ClonedFrom: target.ClonedFrom,
+		Comment:    target.Comment,
+		Failed:     failed,
+	}
+
+	for _, vr := range responses {
+		result.Files = append(result.Files, FileResult{
+			Name:    vr.Name,
+			Path:    vr.Path,
+			Created: vr.Created,
+			Err:     vr.Err,
+		})
+	}
+
+	if main != "" {
+		result.Main = main
+		result.MainErr = setMain(ctx, opts.Service, target.Version, main, client)
+	}
+
+	return result, nil
+}
+
+func uploadVCL(ctx context.Context, r *run, selectedVersion int, path string, client api.Client, ch chan vclResponse) {
+	defer r.wg.Done()
+
+	name := extractName(path)
+
+	if err := ctx.Err(); err != nil {
+		ch <- vclResponse{Path: path, Name: name, Err: err}
+		return
+	}
+
+	content, err := getLocalVCL(path)
+	if err != nil {
+		ch <- vclResponse{
+			Path: path,
+			Name: name,
+			Err:  err,
+		}
+		return
+	}
+
+	// First check if the local file exists already on the remote
+	_, err = client.GetVCL(&fastly.GetVCLInput{
+		Service: r.service,
+		Version: selectedVersion,
+		Name:    name,
+	})
+
+	if err != nil {
+		// If the file DOESNT exist, then we'll create it
+		logger.WithFields(logrus.Fields{
+			"name":  name,
+			"error": err,
+		}).Debug("will attempt to create the file")
+
+		vclFile, err := client.CreateVCL(&fastly.CreateVCLInput{
+			Service: r.service,
+			Version: selectedVersion,
+			Name:    name,
+			Content: content,
+		})
+		if err != nil {
+			ch <- vclResponse{
+				Path: path,
+				Name: name,
+				Err:  err,
+			}
+		} else {
+			ch <- vclResponse{
+				Path:    path,
+				Name:    name,
+				Content: vclFile.Content,
+				Created: true,
+			}
+		}
+		return
+	}
+
+	// If the file DOES exist, then we'll upload our version on top of it
+	vclFileUpdate, err := client.UpdateVCL(&fastly.UpdateVCLInput{
+		Service: r.service,
+		Version: selectedVersion,
+		Name:    name,
+		Content: content,
+	})
+	if err != nil {
+		ch <- vclResponse{
+			Path: path,
+			Name: name,
+			Err:  err,
+		}
+	} else {
+		ch <- vclResponse{
+			Path:    path,
+			Name:    name,
+			Content: vclFileUpdate.Content,
+		}
+	}
+}
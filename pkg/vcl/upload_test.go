@@ -0,0 +1,198 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api/apitest"
+)
+
+func TestUpload(t *testing.T) {
+	dir := writeDir(t, localFiles)
+	defer os.RemoveAll(dir)
+
+	remote := map[string]string{
+		"main": "sub vcl_recv {\n  set req.http.X-Version = \"1\";\n}\n",
+	}
+	uploaded := map[string]string{
+		"main":  localFiles["main.vcl"],
+		"other": localFiles["other.vcl"],
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(*apitest.Fake)
+		opts    UploadOptions
+		version int
+		cloned  int
+		created []string
+		files   map[string]string
+		err     error
+	}{
+		{
+			name:    "clones the latest version",
+			opts:    UploadOptions{Comment: "uploaded"},
+			version: 2,
+			cloned:  1,
+			created: []string{"other"},
+			files:   uploaded,
+		},
+		{
+			name:    "clones the specified version",
+			setup:   func(f *apitest.Fake) { f.AddVersion("svc") },
+			opts:    UploadOptions{Clone: 1},
+			version: 3,
+			cloned:  1,
+			created: []string{"other"},
+			files:   uploaded,
+		},
+		{
+			name:    "uploads to the specified version",
+			opts:    UploadOptions{Version: 1},
+			version: 1,
+			created: []string{"other"},
+			files:   uploaded,
+		},
+		{
+			name:    "uploads to the latest version",
+			setup:   func(f *apitest.Fake) { f.AddVersion("svc") },
+			opts:    UploadOptions{Latest: true},
+			version: 2,
+			created: []string{"main", "other"},
+			files:   uploaded,
+		},
+		{
+			name:    "only uploads the matching files",
+			opts:    UploadOptions{Version: 1, Match: `main\.vcl$`},
+			version: 1,
+			files:   map[string]string{"main": localFiles["main.vcl"]},
+		},
+		{
+			name: "refuses the active version",
+			setup: func(f *apitest.Fake) {
+				f.ActivateVersion(&fastly.ActivateVersionInput{Service: "svc", Version: 1})
+			},
+			opts: UploadOptions{Latest: true},
+			err:  ErrActiveVersion,
+		},
+		{
+			name: "refuses both a clone and upload version",
+			opts: UploadOptions{Clone: 1, Version: 1},
+			err:  ErrConflictingVersions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newService(t, remote)
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			opts := tt.opts
+			opts.Service = "svc"
+			opts.Directory = dir
+
+			result, err := Upload(context.Background(), f, opts)
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				if calls := f.Calls("CloneVersion") + f.Calls("CreateVCL") + f.Calls("UpdateVCL"); calls != 0 {
+					t.Errorf("made %d changes to the service", calls)
+				}
+				return
+			}
+
+			if result.Version != tt.version || result.ClonedFrom != tt.cloned {
+				t.Errorf("uploaded to version %d cloned from %d, want %d cloned from %d", result.Version, result.ClonedFrom, tt.version, tt.cloned)
+			}
+			if result.Err() != nil {
+				t.Errorf("unexpected failures: %s", result.Err())
+			}
+
+			var created []string
+			for _, fr := range result.Files {
+				if fr.Created {
+					created = append(created, fr.Name)
+				}
+			}
+			if !equalNames(created, tt.created) {
+				t.Errorf("created %v, want %v", created, tt.created)
+			}
+
+			equalFiles(t, f, tt.version, tt.files)
+			if tt.cloned != 0 {
+				equalFiles(t, f, tt.cloned, remote)
+			}
+		})
+	}
+}
+
+func TestUploadComment(t *testing.T) {
+	dir := writeDir(t, localFiles)
+	defer os.RemoveAll(dir)
+
+	f := newService(t, nil)
+
+	result, err := Upload(context.Background(), f, UploadOptions{
+		Service:   "svc",
+		Directory: dir,
+		Comment:   "uploaded",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := f.GetVersion(&fastly.GetVersionInput{Service: "svc", Version: result.Version})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Comment != "uploaded" || v.Comment != "uploaded" {
+		t.Errorf("got comment %q on version %q, want %q", result.Comment, v.Comment, "uploaded")
+	}
+}
+
+func TestUploadPartialFailure(t *testing.T) {
+	dir := writeDir(t, localFiles)
+	defer os.RemoveAll(dir)
+
+	f := newService(t, map[string]string{"main": "old\n"})
+	f.FailOn("CreateVCL", errors.New("unavailable"))
+
+	result, err := Upload(context.Background(), f, UploadOptions{
+		Service:   "svc",
+		Directory: dir,
+		Version:   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Failed) != 1 || result.Failed[0].Name != "other" {
+		t.Fatalf("got failures %v, want only other", result.Failed)
+	}
+	equalFiles(t, f, 1, map[string]string{"main": localFiles["main.vcl"]})
+}
+
+// equalNames compares the names regardless of their order
+func equalNames(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+
+	seen := map[string]int{}
+	for _, name := range got {
+		seen[name]++
+	}
+	for _, name := range want {
+		seen[name]--
+		if seen[name] < 0 {
+			return false
+		}
+	}
+	return true
+}
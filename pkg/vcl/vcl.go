@@ -0,0 +1,47 @@
+// Vcl is a package that exposes the behaviour of the CLI as a library, so the
+// same operations (upload, diff, list, delete etc) can be used by other tools.
+//
+// None of the functions in this package print to stdout or exit the process,
+// instead they return typed results along with any errors.
+
+package vcl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/sirupsen/logrus"
+)
+
+var logger *logrus.Entry
+
+func init() {
+	logger = logrus.WithFields(logrus.Fields{
+		"package": "vcl",
+	})
+}
+
+// ErrActiveVersion is returned when attempting to modify an activated version
+var ErrActiveVersion = errors.New("the selected version is already activated")
+
+// ErrConflictingVersions is returned when both a version to clone and a
+// version to upload to are provided
+var ErrConflictingVersions = errors.New("please do not provide both a clone version and an upload version")
+
+// ErrMissingName is returned when an operation requires a VCL file name
+var ErrMissingName = errors.New("you must provide a VCL name")
+
+// resolveVersion returns the provided version, or the latest service version
+// when the provided version is zero
+func resolveVersion(ctx context.Context, service string, version int, client api.Client) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if version != 0 {
+		return version, nil
+	}
+
+	return api.GetLatestVCLVersion(service, client)
+}
@@ -0,0 +1,69 @@
+package vcl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/integralist/go-fastly-cli/pkg/api/apitest"
+)
+
+// the local VCL directory used by the tests
+var localFiles = map[string]string{
+	"main.vcl":  "sub vcl_recv {\n  set req.http.X-Version = \"2\";\n}\n",
+	"other.vcl": "sub vcl_fetch {\n  set beresp.ttl = 60s;\n}\n",
+}
+
+// writeDir creates a temporary directory containing the files, which the
+// caller removes once done
+func writeDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "vcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// newService returns a fake with the service "svc", whose first version
+// holds the VCL files
+func newService(t *testing.T, files map[string]string) *apitest.Fake {
+	f := apitest.NewFake()
+	f.AddService("svc")
+
+	for name, content := range files {
+		if err := f.SetVCL("svc", 1, name, content, name == "main"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return f
+}
+
+// equalFiles reports whether the VCL files of the version match the want
+func equalFiles(t *testing.T, f *apitest.Fake, version int, want map[string]string) {
+	got, err := f.VCLs("svc", version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("version %d has files %v, want %v", version, got, want)
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("version %d file %s is %q, want %q", version, name, got[name], content)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package vcl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// VersionOptions identifies a single service version
+type VersionOptions struct {
+	Service string
+
+	// Version to operate on (zero means the latest version)
+	Version int
+}
+
+// ActivateResult describes the version that was activated
+type ActivateResult struct {
+	Service string
+	Version int
+}
+
+// ValidateResult contains the outcome of validating a service version
+type ValidateResult struct {
+	Service string
+	Version int
+	Valid   bool
+	Message string
+}
+
+// StatusResult contains the activation status of a service version
+type StatusResult struct {
+	Service string
+	Version int
+	Active  bool
+}
+
+// SettingsResult contains the settings of a service version
+type SettingsResult struct {
+	Service     string
+	Version     int
+	DefaultHost string
+	DefaultTTL  uint
+}
+
+// Activate activates the specified Fastly service version
+func Activate(ctx context.Context, client api.Client, opts VersionOptions) (*ActivateResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.ActivateVersion(&fastly.ActivateVersionInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem activating version %d: %s", selectedVersion, err)
+	}
+
+	return &ActivateResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+	}, nil
+}
+
+// Validate validates the specified Fastly service version
+func Validate(ctx context.Context, client api.Client, opts VersionOptions) (*ValidateResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, msg, err := client.ValidateVersion(&fastly.ValidateVersionInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem validating version %d: %s", selectedVersion, err)
+	}
+
+	return &ValidateResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Valid:   valid,
+		Message: msg,
+	}, nil
+}
+
+// Status returns the activation status of the specified service version
+func Status(ctx context.Context, client api.Client, opts VersionOptions) (*StatusResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	versionStatus, err := client.GetVersion(&fastly.GetVersionInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem getting the status for version %d: %s", selectedVersion, err)
+	}
+
+	return &StatusResult{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Active:  versionStatus.Active,
+	}, nil
+}
+
+// Settings returns the settings (Default TTL & Host) of the specified service
+// version
+func Settings(ctx context.Context, client api.Client, opts VersionOptions) (*SettingsResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := client.GetSettings(&fastly.GetSettingsInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem getting the settings for version %d: %s", selectedVersion, err)
+	}
+
+	return &SettingsResult{
+		Service:     opts.Service,
+		Version:     selectedVersion,
+		DefaultHost: settings.DefaultHost,
+		DefaultTTL:  settings.DefaultTTL,
+	}, nil
+}
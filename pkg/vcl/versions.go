@@ -0,0 +1,201 @@
+package vcl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+)
+
+// ErrMissingComment is returned when a version comment isn't provided
+var ErrMissingComment = errors.New("you must provide a version comment")
+
+// VersionInfo describes a single service version
+type VersionInfo struct {
+	Number    int
+	Active    bool
+	Locked    bool
+	Deployed  bool
+	Staging   bool
+	Testing   bool
+	Comment   string
+	CreatedAt string
+	UpdatedAt string
+}
+
+// VersionsResult contains every version of a service sorted by number
+type VersionsResult struct {
+	Service  string
+	Versions []VersionInfo
+}
+
+// VersionResult contains the details of a single service version
+type VersionResult struct {
+	Service string
+	VersionInfo
+}
+
+// DescribeResult contains the details of a service version and its VCL files
+type DescribeResult struct {
+	VersionResult
+	Files []RemoteFile
+}
+
+// CloneResult describes the version created by cloning another
+type CloneResult struct {
+	Service string
+	From    int
+	Version int
+}
+
+// CommentOptions identifies the version to comment on and the comment
+type CommentOptions struct {
+	Service string
+
+	// Version to comment on (zero means the latest version)
+	Version int
+	Comment string
+}
+
+// Versions returns every version of the service, sorted by number
+func Versions(ctx context.Context, client api.Client, service string) (*VersionsResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	versions, err := api.GetSortedVersions(service, client)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VersionsResult{Service: service}
+	for _, v := range versions {
+		result.Versions = append(result.Versions, versionInfo(v))
+	}
+
+	return result, nil
+}
+
+// Describe returns the details of the specified service version, along with
+// the VCL files it contains
+func Describe(ctx context.Context, client api.Client, opts VersionOptions) (*DescribeResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := client.GetVersion(&fastly.GetVersionInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem getting version %d: %s", selectedVersion, err)
+	}
+
+	files, err := List(ctx, client, ListOptions{
+		Service: opts.Service,
+		Version: selectedVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DescribeResult{
+		VersionResult: VersionResult{
+			Service:     opts.Service,
+			VersionInfo: versionInfo(v),
+		},
+		Files: files.Files,
+	}, nil
+}
+
+// Clone creates a new version from the specified service version
+func Clone(ctx context.Context, client api.Client, opts VersionOptions) (*CloneResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	clonedVersion, err := cloneFromVersion(opts.Service, selectedVersion, "", client)
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem cloning version %d: %s", selectedVersion, err)
+	}
+
+	return &CloneResult{
+		Service: opts.Service,
+		From:    selectedVersion,
+		Version: clonedVersion.Number,
+	}, nil
+}
+
+// Lock locks the specified service version so it can no longer be modified
+func Lock(ctx context.Context, client api.Client, opts VersionOptions) (*VersionResult, error) {
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := client.LockVersion(&fastly.LockVersionInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem locking version %d: %s", selectedVersion, err)
+	}
+
+	return &VersionResult{
+		Service:     opts.Service,
+		VersionInfo: versionInfo(v),
+	}, nil
+}
+
+// Comment replaces the comment of the specified service version
+func Comment(ctx context.Context, client api.Client, opts CommentOptions) (*VersionResult, error) {
+	if opts.Comment == "" {
+		return nil, ErrMissingComment
+	}
+
+	selectedVersion, err := resolveVersion(ctx, opts.Service, opts.Version, client)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := client.UpdateVersion(&fastly.UpdateVersionInput{
+		Service: opts.Service,
+		Version: selectedVersion,
+		Comment: opts.Comment,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem commenting on version %d: %s", selectedVersion, err)
+	}
+
+	return &VersionResult{
+		Service:     opts.Service,
+		VersionInfo: versionInfo(v),
+	}, nil
+}
+
+func versionInfo(v *fastly.Version) VersionInfo {
+	return VersionInfo{
+		Number:    v.Number,
+		Active:    v.Active,
+		Locked:    v.Locked,
+		Deployed:  v.Deployed,
+		Staging:   v.Staging,
+		Testing:   v.Testing,
+		Comment:   v.Comment,
+		CreatedAt: formatTime(v.CreatedAt),
+		UpdatedAt: formatTime(v.UpdatedAt),
+	}
+}
+
+// formatTime formats the timestamp as the API does (empty when there's none)
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
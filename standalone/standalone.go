@@ -1,85 +1,262 @@
-// Standalone is a package that defines behaviour which relies on a single top
-// level flag, and not nested flags or sub commands.
+// Standalone is a package that defines the behaviour of the version commands
+// which only take a version number (originally single top level flags such
+// as -activate), and not nested flags.
 
 package standalone
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 
 	"github.com/integralist/go-fastly-cli/common"
-	"github.com/sethvargo/go-fastly/fastly"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/api"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
 )
 
+// ActivateOptions are the guardrails checked before a version is activated
+type ActivateOptions struct {
+	// DryRun only reports the version, it isn't activated
+	DryRun bool
+
+	// Yes skips typing the version number to confirm the activation
+	Yes bool
+
+	// Protected services are only activated when Force is set
+	Protected bool
+	Force     bool
+}
+
+// ErrProtectedService is reported when a version of a protected service is
+// activated without -force
+var ErrProtectedService = errors.New("the service is protected, use -force to activate it")
+
+// errConfirmationRequired is reported when the activation can't be confirmed
+// interactively (i.e. structured output was requested) and -yes wasn't given
+var errConfirmationRequired = errors.New("the activation must be confirmed, use -yes to skip the confirmation")
+
+// ErrNotConfirmed is returned by Confirm when the user didn't confirm the
+// activation
+var ErrNotConfirmed = errors.New("the version was not activated")
+
+// Check reports the guardrails that would stop an activation before any
+// changes are made (e.g. before uploading the files of a deploy)
+func (opts ActivateOptions) Check() error {
+	if opts.Protected && !opts.Force {
+		return ErrProtectedService
+	}
+	if !opts.Yes && output.Structured() {
+		return errConfirmationRequired
+	}
+	return nil
+}
+
+// Confirm is the vcl.ConfirmActivation of every command that activates a
+// version, the plan is displayed (unless structured output was requested)
+// and then the user has to type the version number (unless -yes)
+//
+// The plan is only confirmed once validation passed (vcl.ErrInvalidVersion
+// otherwise) and protected services also require -force
+func (opts ActivateOptions) Confirm(plan *vcl.ActivatePlan) error {
+	if !output.Structured() {
+		printActivatePlan(plan, opts.Protected)
+	}
+
+	if !plan.Valid {
+		return vcl.ErrInvalidVersion
+	}
+
+	if err := opts.Check(); err != nil {
+		return err
+	}
+
+	if !opts.Yes {
+		question := fmt.Sprintf("\nType the version number (%d) to activate it", plan.Version)
+		if !common.ConfirmTyped(question, strconv.Itoa(plan.Version)) {
+			return ErrNotConfirmed
+		}
+	}
+
+	return nil
+}
+
 // ActivateVersion activates the specified Fastly service version
-func ActivateVersion(version, service string, client *fastly.Client) {
-	v, err := strconv.Atoi(version)
+// the active version, the comment, validation and VCL differences of the
+// version are displayed first and the user has to type the version number
+// to confirm (unless -yes), a version that fails validation is never
+// activated and protected services also require -force
+func ActivateVersion(ctx context.Context, version, service string, opts ActivateOptions, client api.Client) {
+	v, err := common.ParseVersion(version)
 	if err != nil {
-		fmt.Println(err)
-		common.Failure()
+		output.Fail(err)
 	}
 
-	_, err = client.ActivateVersion(&fastly.ActivateVersionInput{
+	plan, err := vcl.PlanActivate(ctx, client, vcl.VersionOptions{
 		Service: service,
 		Version: v,
 	})
 	if err != nil {
-		fmt.Printf("\nThere was a problem activating version %s\n\n%s", common.Yellow(version), common.Red(err))
-		common.Failure()
+		output.Failf(err, "\nThere was a problem checking version %s\n\n%s", common.Yellow(version), common.Red(err))
 	}
 
-	fmt.Printf("\nService '%s' now has version '%s' activated\n\n", common.Yellow(service), common.Green(version))
-}
+	if opts.DryRun || plan.Active == plan.Version {
+		doc := output.ActivatePlan(plan)
+		doc.DryRun = opts.DryRun
 
-// ValidateVersion validates the specified Fastly service version
-func ValidateVersion(version, service string, client *fastly.Client) {
-	v, err := strconv.Atoi(version)
-	if err != nil {
-		fmt.Println(err)
-		common.Failure()
+		switch {
+		case output.Structured():
+			output.Write(doc)
+		case opts.DryRun:
+			printActivatePlan(plan, opts.Protected)
+			fmt.Printf("\nService '%s' would have version '%s' activated\n\n", common.Yellow(plan.Service), common.Green(plan.Version))
+		default:
+			printActivatePlan(plan, opts.Protected)
+		}
+		return
+	}
+
+	switch err := opts.Confirm(plan); err {
+	case nil:
+	case ErrNotConfirmed:
+		fmt.Println("\nThe version was not activated")
+		common.Success()
+	case vcl.ErrInvalidVersion:
+		output.Failf(err, "\nRefusing to activate version '%s' as it failed validation\n\n", common.Red(plan.Version))
+	case ErrProtectedService:
+		output.Failf(err, "\nService '%s' is protected, use -force to activate it\n\n", common.Red(plan.Service))
+	default:
+		output.Fail(err)
 	}
 
-	valid, msg, err := client.ValidateVersion(&fastly.ValidateVersionInput{
+	result, err := vcl.Activate(ctx, client, vcl.VersionOptions{
 		Service: service,
-		Version: v,
+		Version: plan.Version,
 	})
 	if err != nil {
-		fmt.Printf("\nThere was a problem validating version %s\n\n%s", common.Yellow(version), common.Red(err))
-		common.Failure()
+		output.Failf(err, "\nThere was a problem activating version %s\n\n%s", common.Yellow(plan.Version), common.Red(err))
 	}
 
-	var validColour, details string
+	if output.Structured() {
+		output.Write(output.Activate(plan, result))
+		return
+	}
+
+	fmt.Printf("\nService '%s' now has version '%s' activated\n\n", common.Yellow(service), common.Green(result.Version))
+}
+
+func printActivatePlan(plan *vcl.ActivatePlan, protected bool) {
+	if plan.Active == plan.Version {
+		fmt.Printf("\nService '%s' already has version '%s' activated\n\n", common.Yellow(plan.Service), common.Green(plan.Version))
+		return
+	}
 
-	validColour = common.Green(valid)
+	name := fmt.Sprintf("'%s'", common.Yellow(plan.Service))
+	if protected {
+		name += " " + common.Red("(protected)")
+	}
 
-	if valid == false {
-		validColour = common.Red(valid)
-		details = common.Red(msg)
+	active := "none"
+	if plan.Active != 0 {
+		active = fmt.Sprintf("%d", plan.Active)
 	}
 
-	fmt.Printf("\nService '%s' valid? %s\n\n%s", common.Yellow(service), validColour, details)
+	comment := "none"
+	if plan.Comment != "" {
+		comment = fmt.Sprintf("%q", plan.Comment)
+	}
+
+	locked := ""
+	if plan.Locked {
+		locked = " (locked)"
+	}
+
+	validation := common.Green("valid")
+	if !plan.Valid {
+		validation = fmt.Sprintf("%s %s", common.Red("invalid"), plan.Message)
+	}
+
+	fmt.Printf("\nService %s\n\n", name)
+	fmt.Printf("  active version:  %s\n", common.Yellow(active))
+	fmt.Printf("  target version:  %s%s\n", common.Green(plan.Version), locked)
+	fmt.Printf("  comment:         %s\n", comment)
+	fmt.Printf("  validation:      %s\n", validation)
+
+	switch {
+	case plan.Active == 0:
+		fmt.Println("\nThere is no active version to compare the VCL against")
+	case len(plan.Files) == 0:
+		fmt.Printf("\nThere are no VCL differences from version '%s'\n", common.Yellow(plan.Active))
+	default:
+		fmt.Printf("\nVCL differences from version '%s':\n\n", common.Yellow(plan.Active))
+		for _, vd := range plan.Files {
+			fmt.Printf("  %s: %s lines added, %s lines removed\n", vd.Name, common.Green(vd.Result.Added), common.Red(vd.Result.Removed))
+		}
+	}
 }
 
-// PrintLatestSettings sends the sepecified service version settings to stdout
-func PrintLatestSettings(serviceID string, client *fastly.Client) {
-	latestVersion, err := common.GetLatestVCLVersion(serviceID, client)
+// ValidateVersion validates the specified Fastly service version
+// each of the services is validated at the same time
+func ValidateVersion(ctx context.Context, version string, services []string, client api.Client) {
+	v, err := common.ParseVersion(version)
 	if err != nil {
-		fmt.Println(err)
-		common.Failure()
+		output.Fail(err)
 	}
 
-	PrintSettingsFor(serviceID, latestVersion, client)
+	output.Services(services, func(service string) output.Report {
+		result, err := vcl.Validate(ctx, client, vcl.VersionOptions{
+			Service: service,
+			Version: v,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "\nThere was a problem validating version %s\n\n%s", common.Yellow(version), common.Red(err))
+		}
+
+		report := output.Report{
+			Service: service,
+			Text:    func() { printValidate(result) },
+			Doc:     output.Validate(result),
+		}
+		if !result.Valid {
+			report.Code = common.ExitFailure
+		}
+		return report
+	})
 }
 
-// PrintSettingsFor sends the sepecified service version settings to stdout
-func PrintSettingsFor(serviceID string, serviceVersion int, client *fastly.Client) {
-	settings, err := client.GetSettings(&fastly.GetSettingsInput{
-		Service: serviceID,
-		Version: serviceVersion,
+func printValidate(result *vcl.ValidateResult) {
+	var validColour, details string
+
+	validColour = common.Green(result.Valid)
+
+	if result.Valid == false {
+		validColour = common.Red(result.Valid)
+		details = common.Red(result.Message)
+	}
+
+	fmt.Printf("\nService '%s' valid? %s\n\n%s", common.Yellow(result.Service), validColour, details)
+}
+
+// PrintSettings sends the specified service version settings to stdout
+// the version can be either a version number or "latest"
+func PrintSettings(ctx context.Context, version, service string, client api.Client) {
+	v, err := common.ParseVersion(version)
+	if err != nil {
+		output.Fail(err)
+	}
+
+	settings, err := vcl.Settings(ctx, client, vcl.VersionOptions{
+		Service: service,
+		Version: v,
 	})
 	if err != nil {
-		fmt.Printf("\nThere was a problem getting the settings for version %s\n\n%s", common.Yellow(serviceVersion), common.Red(err))
-		common.Failure()
+		output.Failf(err, "\nThere was a problem getting the settings for version %s\n\n%s", common.Yellow(version), common.Red(err))
+	}
+
+	if output.Structured() {
+		output.Write(output.Settings(settings))
+		return
 	}
 
 	fmt.Printf(
@@ -89,39 +266,37 @@ func PrintSettingsFor(serviceID string, serviceVersion int, client *fastly.Clien
 	)
 }
 
-// GetLatestServiceVersionStatus returns the latest Fastly service version and its status
-func GetLatestServiceVersionStatus(serviceID string, client *fastly.Client) (string, error) {
-	latestVersion, err := common.GetLatestVCLVersion(serviceID, client)
+// PrintStatus sends the status of the specified service version to stdout
+// the version can be either a version number or "latest"
+// each of the services is checked at the same time
+func PrintStatus(ctx context.Context, version string, services []string, client api.Client) {
+	v, err := common.ParseVersion(version)
 	if err != nil {
-		return "", err
+		output.Fail(err)
 	}
 
-	status, err := GetStatusForVersion(serviceID, latestVersion, client)
-	if err != nil {
-		return "", err
-	}
-
-	return status, nil
-}
+	output.Services(services, func(service string) output.Report {
+		status, err := vcl.Status(ctx, client, vcl.VersionOptions{
+			Service: service,
+			Version: v,
+		})
+		if err != nil {
+			return output.ErrorReport(service, err, "\nThere was a problem getting the status for version %s\n\n%s\n\n", common.Yellow(version), common.Red(err))
+		}
 
-// GetStatusForVersion returns the status of the specified Fastly service version
-func GetStatusForVersion(serviceID string, statusVersion int, client *fastly.Client) (string, error) {
-	versionStatus, err := client.GetVersion(&fastly.GetVersionInput{
-		Service: serviceID,
-		Version: statusVersion,
+		return output.Report{
+			Service: service,
+			Text:    func() { printStatus(status) },
+			Doc:     output.Status(status),
+		}
 	})
-	if err != nil {
-		msg := "\nThere was a problem getting the status for version %s\n\n%s\n\n"
-		return "", fmt.Errorf(msg, common.Yellow(statusVersion), common.Red(err))
-	}
+}
 
+func printStatus(status *vcl.StatusResult) {
 	activated := common.Green("not activated")
-	if versionStatus.Active {
+	if status.Active {
 		activated = common.Red("already activated")
 	}
 
-	msg := "\nService '%s' version '%s' is '%s'\n\n"
-	status := fmt.Sprintf(msg, common.Yellow(serviceID), common.Yellow(statusVersion), activated)
-
-	return status, nil
+	fmt.Printf("\nService '%s' version '%s' is '%s'\n\n", common.Yellow(status.Service), common.Yellow(status.Version), activated)
 }
@@ -0,0 +1,140 @@
+package standalone
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+)
+
+// answer replaces stdin with the typed answer, the returned function puts
+// stdin back
+func answer(t *testing.T, typed string) func() {
+	file, err := ioutil.TempFile("", "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(typed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	stdin := os.Stdin
+	os.Stdin = file
+
+	return func() {
+		os.Stdin = stdin
+		file.Close()
+		os.Remove(file.Name())
+	}
+}
+
+func TestActivateOptionsConfirm(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       ActivateOptions
+		invalid    bool
+		structured bool
+		typed      string
+		err        error
+	}{
+		{
+			name:  "typed version number",
+			opts:  ActivateOptions{},
+			typed: "3\n",
+		},
+		{
+			name:  "wrong version number",
+			opts:  ActivateOptions{},
+			typed: "2\n",
+			err:   ErrNotConfirmed,
+		},
+		{
+			name:  "nothing typed",
+			opts:  ActivateOptions{},
+			typed: "",
+			err:   ErrNotConfirmed,
+		},
+		{
+			name: "yes skips the confirmation",
+			opts: ActivateOptions{Yes: true},
+		},
+		{
+			name:    "invalid version is refused",
+			opts:    ActivateOptions{Yes: true, Force: true},
+			invalid: true,
+			err:     vcl.ErrInvalidVersion,
+		},
+		{
+			name: "protected service needs force",
+			opts: ActivateOptions{Yes: true, Protected: true},
+			err:  ErrProtectedService,
+		},
+		{
+			name: "protected service with force",
+			opts: ActivateOptions{Yes: true, Protected: true, Force: true},
+		},
+		{
+			name:       "structured output needs yes",
+			opts:       ActivateOptions{},
+			structured: true,
+			err:        errConfirmationRequired,
+		},
+		{
+			name:       "structured output with yes",
+			opts:       ActivateOptions{Yes: true},
+			structured: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.structured {
+				output.SetFormat(output.JSON)
+				defer output.SetFormat(output.Text)
+			}
+			defer answer(t, tt.typed)()
+
+			plan := &vcl.ActivatePlan{Service: "svc", Version: 3, Active: 2, Valid: !tt.invalid}
+			if tt.invalid {
+				plan.Message = "Syntax error"
+			}
+
+			if err := tt.opts.Confirm(plan); err != tt.err {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestActivateOptionsCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       ActivateOptions
+		structured bool
+		err        error
+	}{
+		{name: "no guardrails", opts: ActivateOptions{}},
+		{name: "protected service", opts: ActivateOptions{Protected: true}, err: ErrProtectedService},
+		{name: "protected service with force", opts: ActivateOptions{Protected: true, Force: true}},
+		{name: "structured output", opts: ActivateOptions{}, structured: true, err: errConfirmationRequired},
+		{name: "structured output with yes", opts: ActivateOptions{Yes: true}, structured: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.structured {
+				output.SetFormat(output.JSON)
+				defer output.SetFormat(output.Text)
+			}
+
+			if err := tt.opts.Check(); err != tt.err {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
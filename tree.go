@@ -0,0 +1,1138 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/integralist/go-fastly-cli/cli"
+	"github.com/integralist/go-fastly-cli/commands"
+	"github.com/integralist/go-fastly-cli/output"
+	"github.com/integralist/go-fastly-cli/pkg/vcl"
+	"github.com/integralist/go-fastly-cli/standalone"
+)
+
+// errMissingVersion is reported when a version command isn't given a version
+var errMissingVersion = errors.New("a service version is required (e.g. 123 or latest)")
+
+// errMissingItemsFile is reported when an import isn't given a file of items
+var errMissingItemsFile = errors.New("you must provide a json or csv file of items")
+
+// errMissingEntriesFile is reported when an ACL sync isn't given a file of
+// entries
+var errMissingEntriesFile = errors.New("you must provide a file of ip addresses and cidr blocks")
+
+// errMissingItemValue is reported when a dictionary item isn't given a value
+var errMissingItemValue = errors.New("you must provide a dictionary item value")
+
+// commandTree defines every command, the deprecated forms (e.g. `fastly
+// upload` or the -activate flag) still work but print a warning first
+func commandTree(a *app) *cli.Command {
+	f := a.f
+
+	root := &cli.Command{
+		Name:    filepath.Base(os.Args[0]),
+		Summary: "Manage the VCL files and versions of your Fastly services",
+		Flags:   flag.CommandLine,
+	}
+	root.Run = a.legacy(root)
+
+	vclDelete := &cli.Command{
+		Name:    "delete",
+		Summary: "delete a specific vcl file from the remote service",
+		Example: "fastly vcl delete -name test_file -version 123",
+		Flags:   f.Top.Delete,
+		Run:     a.vclDelete,
+	}
+	vclDeploy := &cli.Command{
+		Name:    "deploy",
+		Summary: "upload local files, then validate and activate the remote service version",
+		Example: "fastly vcl deploy -clone 123",
+		Flags:   f.Top.Deploy,
+		Run:     a.vclDeploy,
+	}
+	vclDiff := &cli.Command{
+		Name:    "diff",
+		Summary: "view a diff between your local files and the remote versions",
+		Example: "fastly vcl diff -version 123",
+		Flags:   f.Top.Diff,
+		Run:     a.vclDiff,
+	}
+	vclList := &cli.Command{
+		Name:    "list",
+		Summary: "list all vcl files found within specified remote service version",
+		Example: "fastly vcl list -version 123",
+		Flags:   f.Top.List,
+		Run:     a.vclList,
+	}
+	vclSync := &cli.Command{
+		Name:    "sync",
+		Summary: "make your remote service version exactly mirror your local files (inc. deletions)",
+		Example: "fastly vcl sync -version 123",
+		Flags:   f.Top.Sync,
+		Run:     a.vclSync,
+	}
+	vclUpload := &cli.Command{
+		Name:    "upload",
+		Summary: "upload local files to your remote service version",
+		Example: "fastly vcl upload -version 123",
+		Flags:   f.Top.Upload,
+		Run:     a.vclUpload,
+	}
+
+	versionActivate := &cli.Command{
+		Name:    "activate",
+		Args:    "<version>",
+		Summary: "activate a service version (after confirming its changes)",
+		Example: "fastly version activate 123\nfastly version activate -yes -force latest",
+		Run:     a.versionActivate,
+	}
+	versionClone := &cli.Command{
+		Name:    "clone",
+		Args:    "<version>",
+		Summary: "create a new version from a service version",
+		Example: "fastly version clone 123\nfastly version clone latest",
+		Run:     a.versionClone,
+	}
+	versionComment := &cli.Command{
+		Name:    "comment",
+		Args:    "<version> <comment>",
+		Summary: "replace the comment of a service version",
+		Example: "fastly version comment 123 \"Add the new backend\"",
+		Run:     a.versionComment,
+	}
+	versionList := &cli.Command{
+		Name:    "list",
+		Summary: "list every version of the service (sorted by number)",
+		Example: "fastly version list\nfastly -service prod version list",
+		Run:     a.versionList,
+	}
+	versionLock := &cli.Command{
+		Name:    "lock",
+		Args:    "<version>",
+		Summary: "lock a service version so it can no longer be modified",
+		Example: "fastly version lock 123",
+		Run:     a.versionLock,
+	}
+	versionRollback := &cli.Command{
+		Name:    "rollback",
+		Summary: "re-activate the previously active remote service version",
+		Example: "fastly version rollback -to 123",
+		Flags:   f.Top.Rollback,
+		Run:     a.versionRollback,
+	}
+	versionSettings := &cli.Command{
+		Name:    "settings",
+		Args:    "<version>",
+		Summary: "show the settings (Default TTL & Host) of a service version",
+		Example: "fastly version settings latest",
+		Run:     a.versionSettings,
+	}
+	versionShow := &cli.Command{
+		Name:    "show",
+		Args:    "<version>",
+		Summary: "show the details and vcl files of a service version",
+		Example: "fastly version show 123\nfastly version show latest",
+		Run:     a.versionShow,
+	}
+	versionStatus := &cli.Command{
+		Name:    "status",
+		Args:    "<version>",
+		Summary: "show whether a service version is active",
+		Example: "fastly version status latest",
+		Run:     a.versionStatus,
+	}
+	versionValidate := &cli.Command{
+		Name:    "validate",
+		Args:    "<version>",
+		Summary: "validate a service version",
+		Example: "fastly version validate 123",
+		Run:     a.versionValidate,
+	}
+
+	snippetDelete := &cli.Command{
+		Name:    "delete",
+		Args:    "<name>",
+		Summary: "delete a snippet from the remote service version",
+		Example: "fastly snippet delete -version 123 redirects",
+		Flags:   f.Top.SnippetDelete,
+		Run:     a.snippetDelete,
+	}
+	snippetDiff := &cli.Command{
+		Name:    "diff",
+		Summary: "view a diff between your local snippets and the remote version",
+		Example: "fastly snippet diff -version 123",
+		Flags:   f.Top.SnippetDiff,
+		Run:     a.snippetDiff,
+	}
+	snippetGet := &cli.Command{
+		Name:    "get",
+		Args:    "<name>",
+		Summary: "show the content of a snippet (the live content of a dynamic snippet)",
+		Example: "fastly snippet get redirects > snippets/recv/10_redirects.vcl",
+		Flags:   f.Top.SnippetGet,
+		Run:     a.snippetGet,
+	}
+	snippetList := &cli.Command{
+		Name:    "list",
+		Summary: "list the snippets found within the remote service version",
+		Example: "fastly snippet list -version 123",
+		Flags:   f.Top.SnippetList,
+		Run:     a.snippetList,
+	}
+	snippetUpload := &cli.Command{
+		Name:    "upload",
+		Summary: "upload local snippets to your remote service version (or dynamic snippets in place)",
+		Example: "fastly snippet upload -clone 123\nfastly snippet upload -dynamic",
+		Flags:   f.Top.SnippetUpload,
+		Run:     a.snippetUpload,
+	}
+
+	dictionaryCreate := &cli.Command{
+		Name:    "create",
+		Args:    "<dictionary>",
+		Summary: "create an empty dictionary in the remote service version",
+		Example: "fastly dictionary create -clone 123 routes",
+		Flags:   f.Top.DictionaryCreate,
+		Run:     a.dictionaryCreate,
+	}
+	dictionaryDelete := &cli.Command{
+		Name:    "delete",
+		Args:    "<dictionary>",
+		Summary: "delete a dictionary (and its items) from the remote service version",
+		Example: "fastly dictionary delete -version 123 routes",
+		Flags:   f.Top.DictionaryDelete,
+		Run:     a.dictionaryDelete,
+	}
+	dictionaryList := &cli.Command{
+		Name:    "list",
+		Summary: "list the dictionaries found within the remote service version",
+		Example: "fastly dictionary list -version 123",
+		Flags:   f.Top.DictionaryList,
+		Run:     a.dictionaryList,
+	}
+
+	itemDelete := &cli.Command{
+		Name:    "delete",
+		Args:    "<dictionary> <key>",
+		Summary: "delete a dictionary item (live immediately)",
+		Example: "fastly dictionary items delete routes /old",
+		Flags:   f.Top.ItemDelete,
+		Run:     a.itemDelete,
+	}
+	itemGet := &cli.Command{
+		Name:    "get",
+		Args:    "<dictionary> <key>",
+		Summary: "show the value of a dictionary item",
+		Example: "fastly dictionary items get routes /old",
+		Flags:   f.Top.ItemGet,
+		Run:     a.itemGet,
+	}
+	itemImport := &cli.Command{
+		Name:    "import",
+		Args:    "<dictionary> <file>",
+		Summary: "make the dictionary contain exactly the items of a json or csv file (live immediately)",
+		Example: "fastly dictionary items import routes routes.json\nfastly dictionary items import -format csv -yes routes routes.txt",
+		Flags:   f.Top.ItemImport,
+		Run:     a.itemImport,
+	}
+	itemList := &cli.Command{
+		Name:    "list",
+		Args:    "<dictionary>",
+		Summary: "list the items of a dictionary",
+		Example: "fastly dictionary items list routes",
+		Flags:   f.Top.ItemList,
+		Run:     a.itemList,
+	}
+	itemSet := &cli.Command{
+		Name:    "set",
+		Args:    "<dictionary> <key> <value>",
+		Summary: "create or update a dictionary item (live immediately)",
+		Example: "fastly dictionary items set routes /old /new",
+		Flags:   f.Top.ItemSet,
+		Run:     a.itemSet,
+	}
+
+	aclCreate := &cli.Command{
+		Name:    "create",
+		Args:    "<acl>",
+		Summary: "create an empty ACL in the remote service version",
+		Example: "fastly acl create -clone 123 blocklist",
+		Flags:   f.Top.ACLCreate,
+		Run:     a.aclCreate,
+	}
+	aclDelete := &cli.Command{
+		Name:    "delete",
+		Args:    "<acl>",
+		Summary: "delete an ACL (and its entries) from the remote service version",
+		Example: "fastly acl delete -version 123 blocklist",
+		Flags:   f.Top.ACLDelete,
+		Run:     a.aclDelete,
+	}
+	aclList := &cli.Command{
+		Name:    "list",
+		Summary: "list the ACLs found within the remote service version",
+		Example: "fastly acl list -version 123",
+		Flags:   f.Top.ACLList,
+		Run:     a.aclList,
+	}
+
+	entryAdd := &cli.Command{
+		Name:    "add",
+		Args:    "<acl> <entry>",
+		Summary: "add an ip address or cidr block to an ACL (live immediately)",
+		Example: "fastly acl entries add -comment \"office\" blocklist 192.0.2.0/24\nfastly acl entries add blocklist '!192.0.2.7'",
+		Flags:   f.Top.EntryAdd,
+		Run:     a.entryAdd,
+	}
+	entryList := &cli.Command{
+		Name:    "list",
+		Args:    "<acl>",
+		Summary: "list the entries of an ACL",
+		Example: "fastly acl entries list blocklist",
+		Flags:   f.Top.EntryList,
+		Run:     a.entryList,
+	}
+	entryRemove := &cli.Command{
+		Name:    "remove",
+		Args:    "<acl> <entry>",
+		Summary: "remove an ip address or cidr block from an ACL (live immediately)",
+		Example: "fastly acl entries remove blocklist 192.0.2.0/24",
+		Flags:   f.Top.EntryRemove,
+		Run:     a.entryRemove,
+	}
+	entrySync := &cli.Command{
+		Name:    "sync",
+		Args:    "<acl> <file>",
+		Summary: "make an ACL contain exactly the entries of a file (live immediately)",
+		Example: "fastly acl entries sync blocklist blocklist.txt\nfastly -service prod acl entries sync -yes blocklist blocklist.txt",
+		Flags:   f.Top.EntrySync,
+		Run:     a.entrySync,
+	}
+
+	backendCreate := &cli.Command{
+		Name:    "create",
+		Args:    "<backend>",
+		Summary: "create a backend in the remote service version",
+		Example: "fastly backend create -address origin.example.com -port 443 -use-ssl -ssl-cert-hostname origin.example.com origin",
+		Flags:   f.Top.BackendCreate,
+		Run:     a.backendCreate,
+	}
+	backendDelete := &cli.Command{
+		Name:    "delete",
+		Args:    "<backend>",
+		Summary: "delete a backend from the remote service version",
+		Example: "fastly backend delete -version 123 origin",
+		Flags:   f.Top.BackendDelete,
+		Run:     a.backendDelete,
+	}
+	backendDiff := &cli.Command{
+		Name:    "diff",
+		Args:    "<from> [to]",
+		Summary: "compare the backends of two remote service versions field by field",
+		Example: "fastly backend diff 122 123\nfastly backend diff 122",
+		Flags:   f.Top.BackendDiff,
+		Run:     a.backendDiff,
+	}
+	backendList := &cli.Command{
+		Name:    "list",
+		Summary: "list the backends found within the remote service version",
+		Example: "fastly backend list -version 123",
+		Flags:   f.Top.BackendList,
+		Run:     a.backendList,
+	}
+	backendShow := &cli.Command{
+		Name:    "show",
+		Args:    "<backend>",
+		Summary: "show every setting of a backend",
+		Example: "fastly backend show -version 123 origin",
+		Flags:   f.Top.BackendShow,
+		Run:     a.backendShow,
+	}
+	backendUpdate := &cli.Command{
+		Name:    "update",
+		Args:    "<backend>",
+		Summary: "change the settings of a backend provided as flags",
+		Example: "fastly backend update -min-tls-version 1.2 -port 443 origin",
+		Flags:   f.Top.BackendUpdate,
+		Run:     a.backendUpdate,
+	}
+
+	healthCheckCreate := &cli.Command{
+		Name:    "create",
+		Args:    "<healthcheck>",
+		Summary: "create a healthcheck in the remote service version",
+		Example: "fastly healthcheck create -host www.example.com -path /healthz -check-interval 15000 -window 5 -threshold 3 origin-check",
+		Flags:   f.Top.HealthCheckCreate,
+		Run:     a.healthCheckCreate,
+	}
+	healthCheckDelete := &cli.Command{
+		Name:    "delete",
+		Args:    "<healthcheck>",
+		Summary: "delete a healthcheck from the remote service version",
+		Example: "fastly healthcheck delete -version 123 origin-check",
+		Flags:   f.Top.HealthCheckDelete,
+		Run:     a.healthCheckDelete,
+	}
+	healthCheckList := &cli.Command{
+		Name:    "list",
+		Summary: "list the healthchecks found within the remote service version",
+		Example: "fastly healthcheck list -version 123",
+		Flags:   f.Top.HealthCheckList,
+		Run:     a.healthCheckList,
+	}
+	healthCheckUpdate := &cli.Command{
+		Name:    "update",
+		Args:    "<healthcheck>",
+		Summary: "change the settings of a healthcheck provided as flags",
+		Example: "fastly healthcheck update -path /status origin-check",
+		Flags:   f.Top.HealthCheckUpdate,
+		Run:     a.healthCheckUpdate,
+	}
+
+	directorCreate := &cli.Command{
+		Name:    "create",
+		Args:    "<director>",
+		Summary: "create a director in the remote service version",
+		Example: "fastly director create -type round-robin -quorum 50 -backends origin-a,origin-b pool",
+		Flags:   f.Top.DirectorCreate,
+		Run:     a.directorCreate,
+	}
+	directorDelete := &cli.Command{
+		Name:    "delete",
+		Args:    "<director>",
+		Summary: "delete a director from the remote service version",
+		Example: "fastly director delete -version 123 pool",
+		Flags:   f.Top.DirectorDelete,
+		Run:     a.directorDelete,
+	}
+	directorList := &cli.Command{
+		Name:    "list",
+		Summary: "list the directors found within the remote service version",
+		Example: "fastly director list -version 123",
+		Flags:   f.Top.DirectorList,
+		Run:     a.directorList,
+	}
+	directorUpdate := &cli.Command{
+		Name:    "update",
+		Args:    "<director>",
+		Summary: "change the settings of a director provided as flags",
+		Example: "fastly director update -backends origin-a,origin-b,origin-c pool",
+		Flags:   f.Top.DirectorUpdate,
+		Run:     a.directorUpdate,
+	}
+
+	conditionCreate := &cli.Command{
+		Name:    "create",
+		Args:    "<condition>",
+		Summary: "create a condition in the remote service version",
+		Example: "fastly condition create -statement 'req.url ~ \"^/api/\"' api-requests",
+		Flags:   f.Top.ConditionCreate,
+		Run:     a.conditionCreate,
+	}
+	conditionDelete := &cli.Command{
+		Name:    "delete",
+		Args:    "<condition>",
+		Summary: "delete a condition from the remote service version",
+		Example: "fastly condition delete -version 123 api-requests",
+		Flags:   f.Top.ConditionDelete,
+		Run:     a.conditionDelete,
+	}
+	conditionList := &cli.Command{
+		Name:    "list",
+		Summary: "list the conditions found within the remote service version",
+		Example: "fastly condition list -version 123",
+		Flags:   f.Top.ConditionList,
+		Run:     a.conditionList,
+	}
+	conditionUpdate := &cli.Command{
+		Name:    "update",
+		Args:    "<condition>",
+		Summary: "change the settings of a condition provided as flags",
+		Example: "fastly condition update -priority 5 api-requests",
+		Flags:   f.Top.ConditionUpdate,
+		Run:     a.conditionUpdate,
+	}
+
+	domainAdd := &cli.Command{
+		Name:    "add",
+		Args:    "<domain>",
+		Summary: "add a domain to the remote service version",
+		Example: "fastly domain add www.example.com\nfastly domain add -version 123 \"*.example.com\"",
+		Flags:   f.Top.DomainAdd,
+		Run:     a.domainAdd,
+	}
+	domainCheck := &cli.Command{
+		Name:    "check",
+		Summary: "check each domain of the remote service version CNAMEs to Fastly (via Fastly and local DNS)",
+		Example: "fastly domain check\nfastly domain check -version 123 -resolver 1.1.1.1",
+		Flags:   f.Top.DomainCheck,
+		Run:     a.domainCheck,
+	}
+	domainList := &cli.Command{
+		Name:    "list",
+		Summary: "list the domains found within the remote service version",
+		Example: "fastly domain list -version 123",
+		Flags:   f.Top.DomainList,
+		Run:     a.domainList,
+	}
+	domainRemove := &cli.Command{
+		Name:    "remove",
+		Args:    "<domain>",
+		Summary: "remove a domain from the remote service version",
+		Example: "fastly domain remove -version 123 www.example.com",
+		Flags:   f.Top.DomainRemove,
+		Run:     a.domainRemove,
+	}
+
+	authList := &cli.Command{
+		Name:    "list",
+		Summary: "list the stored profiles",
+		Example: "fastly auth list",
+		Run:     a.authList,
+	}
+	authLogin := &cli.Command{
+		Name:    "login",
+		Args:    "[profile]",
+		Summary: "verify an api token (read from stdin) and store it as a named profile",
+		Example: "fastly auth login stage\npass show fastly/prod | fastly auth login prod",
+		Flags:   f.Top.Auth,
+		Run:     a.authLogin,
+	}
+	authLogout := &cli.Command{
+		Name:    "logout",
+		Args:    "[profile]",
+		Summary: "remove a stored profile",
+		Example: "fastly auth logout stage",
+		Run:     a.authLogout,
+	}
+
+	configShow := &cli.Command{
+		Name:    "show",
+		Summary: "show the resolved settings and where each of them came from",
+		Example: "fastly -env stage config show",
+		Run:     a.configShow,
+	}
+
+	return root.Add(
+		(&cli.Command{Name: "vcl", Summary: "manage the vcl files of a service version"}).Add(vclDelete, vclDeploy, vclDiff, vclList, vclSync, vclUpload),
+		(&cli.Command{Name: "version", Summary: "manage the versions of a service"}).Add(versionActivate, versionClone, versionComment, versionList, versionLock, versionRollback, versionSettings, versionShow, versionStatus, versionValidate),
+		(&cli.Command{Name: "snippet", Summary: "manage the vcl snippets of a service version"}).Add(snippetDelete, snippetDiff, snippetGet, snippetList, snippetUpload),
+		(&cli.Command{Name: "dictionary", Summary: "manage the edge dictionaries of a service version"}).Add(
+			dictionaryCreate, dictionaryDelete, dictionaryList,
+			(&cli.Command{Name: "items", Summary: "manage the items of a dictionary (not versioned)"}).Add(itemDelete, itemGet, itemImport, itemList, itemSet),
+		),
+		(&cli.Command{Name: "acl", Summary: "manage the access control lists of a service version"}).Add(
+			aclCreate, aclDelete, aclList,
+			(&cli.Command{Name: "entries", Summary: "manage the entries of an ACL (not versioned)"}).Add(entryAdd, entryList, entryRemove, entrySync),
+		),
+		(&cli.Command{Name: "backend", Summary: "manage the backends of a service version"}).Add(backendCreate, backendDelete, backendDiff, backendList, backendShow, backendUpdate),
+		(&cli.Command{Name: "healthcheck", Summary: "manage the healthchecks of a service version"}).Add(healthCheckCreate, healthCheckDelete, healthCheckList, healthCheckUpdate),
+		(&cli.Command{Name: "director", Summary: "manage the directors of a service version"}).Add(directorCreate, directorDelete, directorList, directorUpdate),
+		(&cli.Command{Name: "condition", Summary: "manage the conditions of a service version"}).Add(conditionCreate, conditionDelete, conditionList, conditionUpdate),
+		(&cli.Command{Name: "domain", Summary: "manage the domains of a service version"}).Add(domainAdd, domainCheck, domainList, domainRemove),
+		(&cli.Command{Name: "auth", Summary: "manage the api tokens stored as named profiles"}).Add(authList, authLogin, authLogout),
+		(&cli.Command{Name: "config", Summary: "inspect the resolved configuration"}).Add(configShow),
+		root.CompletionCommand(),
+		root.HelpCommand(),
+
+		// the original forms of the commands
+		vclDelete.Alias("delete"),
+		vclDeploy.Alias("deploy"),
+		vclDiff.Alias("diff"),
+		vclList.Alias("list"),
+		versionRollback.Alias("rollback"),
+		vclSync.Alias("sync"),
+		vclUpload.Alias("upload"),
+	)
+}
+
+// legacy runs the deprecated top level flags (e.g. -activate 123), and
+// otherwise displays the help as no command was given
+func (a *app) legacy(root *cli.Command) func([]string) {
+	return func(args []string) {
+		f := a.f
+
+		switch {
+		case *f.Top.Activate != "":
+			cli.Deprecated(root.Name+" -activate", root.Name+" version activate")
+			a.versionActivate([]string{*f.Top.Activate})
+		case *f.Top.Validate != "":
+			cli.Deprecated(root.Name+" -validate", root.Name+" version validate")
+			a.versionValidate([]string{*f.Top.Validate})
+		case *f.Top.Status != "":
+			cli.Deprecated(root.Name+" -status", root.Name+" version status")
+			a.versionStatus([]string{*f.Top.Status})
+		case *f.Top.Settings != "":
+			cli.Deprecated(root.Name+" -settings", root.Name+" version settings")
+			a.versionSettings([]string{*f.Top.Settings})
+		default:
+			root.Help()
+		}
+	}
+}
+
+// versionArg returns the version given to a version command
+func versionArg(args []string, command string) string {
+	if len(args) == 0 {
+		output.Failf(errMissingVersion, "Please provide a service version\n  e.g. fastly version %s 123\n", command)
+	}
+	return args[0]
+}
+
+// snippetArg returns the snippet named after a snippet command
+func snippetArg(args []string, command string) string {
+	if len(args) == 0 {
+		output.Failf(vcl.ErrMissingSnippetName, "Please provide a snippet name\n  e.g. fastly snippet %s redirects\n", command)
+	}
+	return args[0]
+}
+
+// requireArgs returns the arguments given to a command, reporting the error
+// of the first missing argument along with an example of the command
+func requireArgs(args []string, example string, missing ...error) []string {
+	if len(args) < len(missing) {
+		err := missing[len(args)]
+		output.Failf(err, "Please provide the missing argument (%s)\n  e.g. %s\n", err, example)
+	}
+	return args
+}
+
+// profileArg returns the profile named after an auth command (if any)
+func profileArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+func (a *app) vclDelete(args []string) {
+	a.connect()
+	requireSingleService(a.services, "vcl delete")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.Delete(a.ctx, a.f, a.client)
+}
+
+func (a *app) vclDeploy(args []string) {
+	a.connect()
+	requireSingleService(a.services, "vcl deploy")
+	if !*a.f.Top.DryRun {
+		requireForce(a.cfg, a.service, *a.f.Top.Force)
+		verifyToken(a.client)
+	}
+	commands.Deploy(a.ctx, a.f, a.client, a.activateOptions(*a.f.Top.Yes))
+}
+
+func (a *app) vclDiff(args []string) {
+	a.connect()
+	commands.Diff(a.ctx, a.f, a.client)
+}
+
+func (a *app) vclList(args []string) {
+	a.connect()
+	commands.List(a.ctx, a.f, a.client)
+}
+
+func (a *app) vclSync(args []string) {
+	a.connect()
+	requireSingleService(a.services, "vcl sync")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.Sync(a.ctx, a.f, a.client)
+}
+
+func (a *app) vclUpload(args []string) {
+	a.connect()
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.Upload(a.ctx, a.f, a.client)
+}
+
+func (a *app) versionActivate(args []string) {
+	a.connect()
+	version := versionArg(args, "activate")
+	requireSingleService(a.services, "version activate")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	standalone.ActivateVersion(a.ctx, version, a.service, a.activateOptions(*a.f.Top.Yes), a.client)
+}
+
+// activateOptions are the guardrails checked by every command that activates
+// a version of the service, yes skips the confirmation
+func (a *app) activateOptions(yes bool) standalone.ActivateOptions {
+	return standalone.ActivateOptions{
+		DryRun:    *a.f.Top.DryRun,
+		Yes:       yes,
+		Protected: a.cfg.IsProtected(a.service),
+		Force:     *a.f.Top.Force,
+	}
+}
+
+func (a *app) versionClone(args []string) {
+	a.connect()
+	version := versionArg(args, "clone")
+	requireSingleService(a.services, "version clone")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.VersionClone(a.ctx, a.f, a.client, version)
+}
+
+func (a *app) versionComment(args []string) {
+	a.connect()
+	version := versionArg(args, "comment")
+	requireSingleService(a.services, "version comment")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.VersionComment(a.ctx, a.f, a.client, version, strings.Join(args[1:], " "))
+}
+
+func (a *app) versionList(args []string) {
+	a.connect()
+	commands.VersionList(a.ctx, a.f, a.client)
+}
+
+func (a *app) versionLock(args []string) {
+	a.connect()
+	version := versionArg(args, "lock")
+	requireSingleService(a.services, "version lock")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.VersionLock(a.ctx, a.f, a.client, version)
+}
+
+func (a *app) versionRollback(args []string) {
+	a.connect()
+	requireSingleService(a.services, "version rollback")
+	if !*a.f.Top.DryRun {
+		requireForce(a.cfg, a.service, *a.f.Top.Force)
+		verifyToken(a.client)
+	}
+	commands.Rollback(a.ctx, a.f, a.client, a.activateOptions(*a.f.Top.Yes || *a.f.Sub.RollbackConfirm))
+}
+
+func (a *app) versionSettings(args []string) {
+	a.connect()
+	version := versionArg(args, "settings")
+	requireSingleService(a.services, "version settings")
+	standalone.PrintSettings(a.ctx, version, a.service, a.client)
+}
+
+func (a *app) versionShow(args []string) {
+	a.connect()
+	commands.VersionShow(a.ctx, a.f, a.client, versionArg(args, "show"))
+}
+
+func (a *app) versionStatus(args []string) {
+	a.connect()
+	standalone.PrintStatus(a.ctx, versionArg(args, "status"), a.services, a.client)
+}
+
+func (a *app) versionValidate(args []string) {
+	a.connect()
+	standalone.ValidateVersion(a.ctx, versionArg(args, "validate"), a.services, a.client)
+}
+
+func (a *app) snippetDelete(args []string) {
+	a.connect()
+	name := snippetArg(args, "delete")
+	requireSingleService(a.services, "snippet delete")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.SnippetDelete(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) snippetDiff(args []string) {
+	a.connect()
+	commands.SnippetDiff(a.ctx, a.f, a.client)
+}
+
+func (a *app) snippetGet(args []string) {
+	a.connect()
+	name := snippetArg(args, "get")
+	requireSingleService(a.services, "snippet get")
+	commands.SnippetGet(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) snippetList(args []string) {
+	a.connect()
+	commands.SnippetList(a.ctx, a.f, a.client)
+}
+
+// dynamic snippets are live as soon as they're uploaded, so a protected
+// service requires -force (as with activating a version)
+func (a *app) snippetUpload(args []string) {
+	a.connect()
+	requireSingleService(a.services, "snippet upload")
+	if !*a.f.Top.DryRun {
+		if *a.f.Sub.SnippetDynamic {
+			requireForce(a.cfg, a.service, *a.f.Top.Force)
+		}
+		verifyToken(a.client)
+	}
+	commands.SnippetUpload(a.ctx, a.f, a.client)
+}
+
+func (a *app) dictionaryCreate(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly dictionary create routes", vcl.ErrMissingDictionaryName)[0]
+	requireSingleService(a.services, "dictionary create")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.DictionaryCreate(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) dictionaryDelete(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly dictionary delete routes", vcl.ErrMissingDictionaryName)[0]
+	requireSingleService(a.services, "dictionary delete")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.DictionaryDelete(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) dictionaryList(args []string) {
+	a.connect()
+	commands.DictionaryList(a.ctx, a.f, a.client)
+}
+
+// dictionary items are live as soon as they change, so a protected service
+// requires -force (as with activating a version)
+func (a *app) itemDelete(args []string) {
+	a.connect()
+	args = requireArgs(args, "fastly dictionary items delete routes /old", vcl.ErrMissingDictionaryName, vcl.ErrMissingItemKey)
+	requireSingleService(a.services, "dictionary items delete")
+	if !*a.f.Top.DryRun {
+		requireForce(a.cfg, a.service, *a.f.Top.Force)
+		verifyToken(a.client)
+	}
+	commands.ItemDelete(a.ctx, a.f, a.client, args[0], args[1])
+}
+
+func (a *app) itemGet(args []string) {
+	a.connect()
+	args = requireArgs(args, "fastly dictionary items get routes /old", vcl.ErrMissingDictionaryName, vcl.ErrMissingItemKey)
+	requireSingleService(a.services, "dictionary items get")
+	commands.ItemGet(a.ctx, a.f, a.client, args[0], args[1])
+}
+
+// a protected service requires -force (see: itemDelete)
+func (a *app) itemImport(args []string) {
+	a.connect()
+	args = requireArgs(args, "fastly dictionary items import routes routes.json", vcl.ErrMissingDictionaryName, errMissingItemsFile)
+	requireSingleService(a.services, "dictionary items import")
+	if !*a.f.Top.DryRun {
+		requireForce(a.cfg, a.service, *a.f.Top.Force)
+		verifyToken(a.client)
+	}
+	commands.ItemImport(a.ctx, a.f, a.client, args[0], args[1])
+}
+
+func (a *app) itemList(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly dictionary items list routes", vcl.ErrMissingDictionaryName)[0]
+	requireSingleService(a.services, "dictionary items list")
+	commands.ItemList(a.ctx, a.f, a.client, name)
+}
+
+// a protected service requires -force (see: itemDelete)
+func (a *app) itemSet(args []string) {
+	a.connect()
+	args = requireArgs(args, "fastly dictionary items set routes /old /new", vcl.ErrMissingDictionaryName, vcl.ErrMissingItemKey, errMissingItemValue)
+	requireSingleService(a.services, "dictionary items set")
+	if !*a.f.Top.DryRun {
+		requireForce(a.cfg, a.service, *a.f.Top.Force)
+		verifyToken(a.client)
+	}
+	commands.ItemSet(a.ctx, a.f, a.client, args[0], args[1], args[2])
+}
+
+func (a *app) aclCreate(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly acl create blocklist", vcl.ErrMissingACLName)[0]
+	requireSingleService(a.services, "acl create")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.ACLCreate(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) aclDelete(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly acl delete blocklist", vcl.ErrMissingACLName)[0]
+	requireSingleService(a.services, "acl delete")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.ACLDelete(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) aclList(args []string) {
+	a.connect()
+	commands.ACLList(a.ctx, a.f, a.client)
+}
+
+// ACL entries are live as soon as they change, so a protected service
+// requires -force (as with activating a version)
+func (a *app) entryAdd(args []string) {
+	a.connect()
+	args = requireArgs(args, "fastly acl entries add blocklist 192.0.2.0/24", vcl.ErrMissingACLName, vcl.ErrMissingACLEntry)
+	requireSingleService(a.services, "acl entries add")
+	if !*a.f.Top.DryRun {
+		requireForce(a.cfg, a.service, *a.f.Top.Force)
+		verifyToken(a.client)
+	}
+	commands.EntryAdd(a.ctx, a.f, a.client, args[0], args[1])
+}
+
+func (a *app) entryList(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly acl entries list blocklist", vcl.ErrMissingACLName)[0]
+	commands.EntryList(a.ctx, a.f, a.client, name)
+}
+
+// a protected service requires -force (see: entryAdd)
+func (a *app) entryRemove(args []string) {
+	a.connect()
+	args = requireArgs(args, "fastly acl entries remove blocklist 192.0.2.0/24", vcl.ErrMissingACLName, vcl.ErrMissingACLEntry)
+	requireSingleService(a.services, "acl entries remove")
+	if !*a.f.Top.DryRun {
+		requireForce(a.cfg, a.service, *a.f.Top.Force)
+		verifyToken(a.client)
+	}
+	commands.EntryRemove(a.ctx, a.f, a.client, args[0], args[1])
+}
+
+// each protected service requires -force (see: entryAdd)
+func (a *app) entrySync(args []string) {
+	a.connect()
+	args = requireArgs(args, "fastly acl entries sync blocklist blocklist.txt", vcl.ErrMissingACLName, errMissingEntriesFile)
+	if !*a.f.Top.DryRun {
+		for _, service := range a.services {
+			requireForce(a.cfg, service, *a.f.Top.Force)
+		}
+		verifyToken(a.client)
+	}
+	commands.EntrySync(a.ctx, a.f, a.client, args[0], args[1])
+}
+
+func (a *app) backendCreate(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly backend create -address origin.example.com origin", vcl.ErrMissingBackendName)[0]
+	requireSingleService(a.services, "backend create")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.BackendCreate(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) backendDelete(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly backend delete origin", vcl.ErrMissingBackendName)[0]
+	requireSingleService(a.services, "backend delete")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.BackendDelete(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) backendDiff(args []string) {
+	a.connect()
+	args = requireArgs(args, "fastly backend diff 122 123", vcl.ErrMissingFromVersion)
+	to := ""
+	if len(args) > 1 {
+		to = args[1]
+	}
+	commands.BackendDiff(a.ctx, a.f, a.client, args[0], to)
+}
+
+func (a *app) backendList(args []string) {
+	a.connect()
+	commands.BackendList(a.ctx, a.f, a.client)
+}
+
+func (a *app) backendShow(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly backend show origin", vcl.ErrMissingBackendName)[0]
+	requireSingleService(a.services, "backend show")
+	commands.BackendShow(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) backendUpdate(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly backend update -port 443 origin", vcl.ErrMissingBackendName)[0]
+	requireSingleService(a.services, "backend update")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.BackendUpdate(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) healthCheckCreate(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly healthcheck create -host www.example.com -path /healthz origin-check", vcl.ErrMissingHealthCheckName)[0]
+	requireSingleService(a.services, "healthcheck create")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.HealthCheckCreate(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) healthCheckDelete(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly healthcheck delete origin-check", vcl.ErrMissingHealthCheckName)[0]
+	requireSingleService(a.services, "healthcheck delete")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.HealthCheckDelete(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) healthCheckList(args []string) {
+	a.connect()
+	commands.HealthCheckList(a.ctx, a.f, a.client)
+}
+
+func (a *app) healthCheckUpdate(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly healthcheck update -threshold 4 origin-check", vcl.ErrMissingHealthCheckName)[0]
+	requireSingleService(a.services, "healthcheck update")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.HealthCheckUpdate(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) directorCreate(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly director create -backends origin-a,origin-b pool", vcl.ErrMissingDirectorName)[0]
+	requireSingleService(a.services, "director create")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.DirectorCreate(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) directorDelete(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly director delete pool", vcl.ErrMissingDirectorName)[0]
+	requireSingleService(a.services, "director delete")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.DirectorDelete(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) directorList(args []string) {
+	a.connect()
+	commands.DirectorList(a.ctx, a.f, a.client)
+}
+
+func (a *app) directorUpdate(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly director update -type hash pool", vcl.ErrMissingDirectorName)[0]
+	requireSingleService(a.services, "director update")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.DirectorUpdate(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) conditionCreate(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly condition create -statement 'req.url ~ \"^/api/\"' api-requests", vcl.ErrMissingConditionName)[0]
+	requireSingleService(a.services, "condition create")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.ConditionCreate(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) conditionDelete(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly condition delete api-requests", vcl.ErrMissingConditionName)[0]
+	requireSingleService(a.services, "condition delete")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.ConditionDelete(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) conditionList(args []string) {
+	a.connect()
+	commands.ConditionList(a.ctx, a.f, a.client)
+}
+
+func (a *app) conditionUpdate(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly condition update -priority 5 api-requests", vcl.ErrMissingConditionName)[0]
+	requireSingleService(a.services, "condition update")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.ConditionUpdate(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) domainAdd(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly domain add www.example.com", vcl.ErrMissingDomainName)[0]
+	requireSingleService(a.services, "domain add")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.DomainAdd(a.ctx, a.f, a.client, name)
+}
+
+func (a *app) domainCheck(args []string) {
+	a.connect()
+	commands.DomainCheck(a.ctx, a.f, a.client, a.client)
+}
+
+func (a *app) domainList(args []string) {
+	a.connect()
+	commands.DomainList(a.ctx, a.f, a.client)
+}
+
+func (a *app) domainRemove(args []string) {
+	a.connect()
+	name := requireArgs(args, "fastly domain remove www.example.com", vcl.ErrMissingDomainName)[0]
+	requireSingleService(a.services, "domain remove")
+	if !*a.f.Top.DryRun {
+		verifyToken(a.client)
+	}
+	commands.DomainRemove(a.ctx, a.f, a.client, name)
+}
+
+// the auth commands manage the stored tokens, so don't need one themselves
+
+func (a *app) authList(args []string) {
+	a.configure()
+	commands.AuthList()
+}
+
+func (a *app) authLogin(args []string) {
+	a.configure()
+	commands.AuthLogin(a.f, profileArg(args))
+}
+
+func (a *app) authLogout(args []string) {
+	a.configure()
+	commands.AuthLogout(a.f, profileArg(args))
+}
+
+func (a *app) configShow(args []string) {
+	a.authenticate()
+	commands.ConfigShow(a.resolved)
+}